@@ -12,6 +12,7 @@ import (
 
 	"github.com/thingsboard-rxprobe/internal/api"
 	"github.com/thingsboard-rxprobe/internal/config"
+	"github.com/thingsboard-rxprobe/internal/notifier"
 	"github.com/thingsboard-rxprobe/internal/prober"
 	"github.com/thingsboard-rxprobe/internal/repository"
 	"github.com/thingsboard-rxprobe/internal/scheduler"
@@ -44,7 +45,7 @@ func main() {
 	logger.Info("正在启动 生产环境通用监控工具...")
 	logger.Info("已加载配置",
 		zap.String("config_path", configPath),
-		zap.String("alert_policy", "每次失败都发送告警（固定策略）"),
+		zap.String("alert_policy", "按目标配置的连续失败/恢复阈值与重复通知间隔"),
 	)
 
 	// 初始化数据库
@@ -69,13 +70,25 @@ func main() {
 	resultRepo := repository.NewResultRepository(db)
 	alertRepo := repository.NewAlertRepository(db)
 	notifierRepo := repository.NewNotifierRepository(db)
+	deliveryRepo := repository.NewDeliveryRepository(db)
+	silenceRepo := repository.NewSilenceRepository(db)
+	maintenanceRepo := repository.NewMaintenanceRepository(db)
+	escalationRepo := repository.NewEscalationRepository(db)
+	routingRepo := repository.NewRoutingRepository(db)
 	userRepo := repository.NewUserRepository(db)
 
 	// 创建探针工厂
 	proberFactory := prober.NewFactory()
 
+	// 创建通知渠道注册表
+	notifiers := notifier.NewRegistry()
+
 	// 创建调度器（传入 alertRepo 用于检查未恢复的告警）
-	sch := scheduler.NewScheduler(proberFactory, alertRepo)
+	sch := scheduler.NewScheduler(proberFactory, alertRepo, scheduler.FlapConfig{
+		Window:        cfg.Scheduler.FlapWindow,
+		HighThreshold: cfg.Scheduler.FlapHighThreshold,
+		LowThreshold:  cfg.Scheduler.FlapLowThreshold,
+	})
 
 	// 解析JWT过期时间
 	jwtExpiry := 7 * 24 * time.Hour // 默认7天
@@ -86,14 +99,28 @@ func main() {
 	}
 
 	// 创建服务
-	probeService := service.NewProbeService(targetRepo, resultRepo, alertRepo, proberFactory, sch)
+	probeService := service.NewProbeService(targetRepo, resultRepo, alertRepo, escalationRepo, proberFactory, sch)
+	notificationService := service.NewNotificationService(deliveryRepo, notifierRepo, alertRepo, notifiers, service.RetryPolicy{
+		MaxAttempts:      cfg.Notification.MaxAttempts,
+		RetryInterval:    time.Duration(cfg.Notification.RetryInterval) * time.Second,
+		MaxRetryInterval: time.Duration(cfg.Notification.MaxRetryInterval) * time.Second,
+	}, service.RateLimitConfig{
+		PerMinute: cfg.Notification.RateLimitPerMinute,
+		QueueSize: cfg.Notification.RateLimitQueue,
+	})
 	// alerter 参数用于兼容旧的配置文件告警器；当前默认不启用（nil）
-	alertService := service.NewAlertService(alertRepo, targetRepo, resultRepo, notifierRepo, nil, sch)
-	cleanupService := service.NewCleanupService(resultRepo, alertRepo, cfg.Scheduler.ResultRetentionDays)
+	maintenanceService := service.NewMaintenanceService(maintenanceRepo)
+	escalationService := service.NewEscalationService(escalationRepo, targetRepo)
+	routingService := service.NewRoutingService(routingRepo)
+	alertService := service.NewAlertService(alertRepo, targetRepo, resultRepo, notifierRepo, silenceRepo, escalationRepo, maintenanceService, routingService, notificationService, nil, sch, cfg.Server.ExternalURL, service.GroupingConfig{
+		Wait:     time.Duration(cfg.Notification.GroupWait) * time.Second,
+		Interval: time.Duration(cfg.Notification.GroupInterval) * time.Second,
+	})
+	cleanupService := service.NewCleanupService(resultRepo, alertRepo, deliveryRepo, cfg.Scheduler.ResultRetentionDays)
 	authService := service.NewAuthService(userRepo, cfg.Auth.JWTSecret, jwtExpiry)
 
 	// 创建路由
-	router := api.NewRouter(probeService, alertService, authService, notifierRepo)
+	router := api.NewRouter(probeService, alertService, notificationService, maintenanceService, escalationService, routingService, authService, notifierRepo, notifiers)
 	engine := router.Setup(cfg.Server.Mode)
 
 	// 创建 HTTP 服务器
@@ -109,6 +136,7 @@ func main() {
 
 	sch.Start(ctx)
 	alertService.Start(ctx)
+	notificationService.Start(ctx)
 	cleanupService.Start()
 
 	// 加载已启用的探测目标
@@ -140,6 +168,7 @@ func main() {
 
 	sch.Stop()
 	alertService.Stop()
+	notificationService.Stop()
 	cleanupService.Stop()
 
 	if err := server.Shutdown(shutdownCtx); err != nil {
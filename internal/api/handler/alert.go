@@ -1,23 +1,30 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/thingsboard-rxprobe/internal/model"
 	"github.com/thingsboard-rxprobe/internal/repository"
 	"github.com/thingsboard-rxprobe/internal/service"
+	"gorm.io/gorm"
 )
 
 // AlertHandler 告警处理器
 type AlertHandler struct {
-	alertService *service.AlertService
+	alertService        *service.AlertService
+	notificationService *service.NotificationService
 }
 
 // NewAlertHandler 创建告警处理器
-func NewAlertHandler(alertService *service.AlertService) *AlertHandler {
-	return &AlertHandler{alertService: alertService}
+func NewAlertHandler(alertService *service.AlertService, notificationService *service.NotificationService) *AlertHandler {
+	return &AlertHandler{
+		alertService:        alertService,
+		notificationService: notificationService,
+	}
 }
 
 // ListRecords 获取告警记录列表
@@ -26,6 +33,7 @@ func (h *AlertHandler) ListRecords(c *gin.Context) {
 	query.Page, _ = strconv.Atoi(c.DefaultQuery("page", "1"))
 	query.Size, _ = strconv.Atoi(c.DefaultQuery("size", "20"))
 	query.Status = c.Query("status")
+	query.Severity = c.Query("severity")
 
 	if targetIDStr := c.Query("target_id"); targetIDStr != "" {
 		targetID, _ := strconv.ParseUint(targetIDStr, 10, 64)
@@ -75,7 +83,8 @@ func (h *AlertHandler) SilenceAlert(c *gin.Context) {
 	}
 
 	var req struct {
-		DurationMinutes int `json:"duration_minutes"`
+		DurationMinutes int    `json:"duration_minutes"`
+		Comment         string `json:"comment"`
 	}
 	if err := c.ShouldBindJSON(&req); err != nil {
 		Error(c, http.StatusBadRequest, err.Error())
@@ -87,7 +96,270 @@ func (h *AlertHandler) SilenceAlert(c *gin.Context) {
 	}
 
 	duration := time.Duration(req.DurationMinutes) * time.Minute
-	h.alertService.SilenceAlert(record.TargetID, duration)
+	silence, err := h.alertService.SilenceAlert(c.Request.Context(), record.TargetID, duration, c.GetString("username"), req.Comment)
+	if err != nil {
+		Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	Success(c, silence)
+}
+
+// CreateSilenceRequest 创建静默规则请求
+type CreateSilenceRequest struct {
+	TargetID        uint64     `json:"target_id"`        // 匹配目标 ID
+	Group           string     `json:"group"`            // 匹配分组
+	TargetType      string     `json:"target_type"`      // 匹配探针类型
+	NamePattern     string     `json:"name_pattern"`     // 匹配目标名称的正则表达式
+	StartsAt        *time.Time `json:"starts_at"`        // 开始时间，默认立即开始
+	EndsAt          *time.Time `json:"ends_at"`          // 结束时间，与 duration_minutes 二选一
+	DurationMinutes int        `json:"duration_minutes"` // 持续时长（分钟），未指定结束时间时使用，默认 30
+	Comment         string     `json:"comment"`          // 备注
+}
+
+// ListSilences 获取静默规则列表
+func (h *AlertHandler) ListSilences(c *gin.Context) {
+	var query repository.SilenceQuery
+	query.Page, _ = strconv.Atoi(c.DefaultQuery("page", "1"))
+	query.Size, _ = strconv.Atoi(c.DefaultQuery("size", "20"))
+	query.State = c.Query("state")
+
+	if targetIDStr := c.Query("target_id"); targetIDStr != "" {
+		targetID, _ := strconv.ParseUint(targetIDStr, 10, 64)
+		query.TargetID = targetID
+	}
+
+	silences, total, err := h.alertService.ListSilences(c.Request.Context(), query)
+	if err != nil {
+		Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	SuccessList(c, silences, total, query.Page, query.Size)
+}
+
+// CreateSilence 创建静默规则
+func (h *AlertHandler) CreateSilence(c *gin.Context) {
+	var req CreateSilenceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		Error(c, http.StatusBadRequest, "请求参数无效: "+err.Error())
+		return
+	}
+
+	startsAt := time.Now()
+	if req.StartsAt != nil {
+		startsAt = *req.StartsAt
+	}
+	endsAt := startsAt.Add(30 * time.Minute)
+	if req.EndsAt != nil {
+		endsAt = *req.EndsAt
+	} else if req.DurationMinutes > 0 {
+		endsAt = startsAt.Add(time.Duration(req.DurationMinutes) * time.Minute)
+	}
+
+	silence := &model.Silence{
+		TargetMatcher: model.TargetMatcher{
+			TargetID:    req.TargetID,
+			Group:       req.Group,
+			TargetType:  req.TargetType,
+			NamePattern: req.NamePattern,
+		},
+		StartsAt:  startsAt,
+		EndsAt:    endsAt,
+		CreatedBy: c.GetString("username"),
+		Comment:   req.Comment,
+	}
+	if err := silence.Validate(); err != nil {
+		Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := h.alertService.CreateSilence(c.Request.Context(), silence); err != nil {
+		Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	Success(c, silence)
+}
+
+// ExpireSilence 立即结束静默规则
+func (h *AlertHandler) ExpireSilence(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		Error(c, http.StatusBadRequest, "无效的 ID")
+		return
+	}
+
+	silence, err := h.alertService.ExpireSilence(c.Request.Context(), id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			Error(c, http.StatusNotFound, "静默规则不存在")
+			return
+		}
+		Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	Success(c, silence)
+}
+
+// ListDeliveries 获取告警记录的通知投递记录（含每次尝试的状态与响应）
+func (h *AlertHandler) ListDeliveries(c *gin.Context) {
+	recordID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		Error(c, http.StatusBadRequest, "无效的 ID")
+		return
+	}
+
+	deliveries, err := h.notificationService.ListDeliveries(c.Request.Context(), recordID)
+	if err != nil {
+		Error(c, http.StatusInternalServerError, "获取通知投递记录失败")
+		return
+	}
+
+	Success(c, deliveries)
+}
+
+// ResendDelivery 重发失败的通知投递
+func (h *AlertHandler) ResendDelivery(c *gin.Context) {
+	recordID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		Error(c, http.StatusBadRequest, "无效的 ID")
+		return
+	}
+	deliveryID, err := strconv.ParseUint(c.Param("delivery_id"), 10, 64)
+	if err != nil {
+		Error(c, http.StatusBadRequest, "无效的投递 ID")
+		return
+	}
+
+	delivery, err := h.notificationService.Resend(c.Request.Context(), recordID, deliveryID)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrDeliveryNotFound):
+			Error(c, http.StatusNotFound, err.Error())
+		case errors.Is(err, service.ErrDeliveryNotResendable):
+			Error(c, http.StatusBadRequest, err.Error())
+		default:
+			Error(c, http.StatusInternalServerError, "重发通知失败: "+err.Error())
+		}
+		return
+	}
+
+	Success(c, delivery)
+}
+
+// AckAlert 确认告警
+func (h *AlertHandler) AckAlert(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		Error(c, http.StatusBadRequest, "无效的 ID")
+		return
+	}
+
+	var req struct {
+		Comment string `json:"comment"`
+	}
+	// 备注可选，允许空请求体
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			Error(c, http.StatusBadRequest, "请求参数无效: "+err.Error())
+			return
+		}
+	}
+
+	record, err := h.alertService.AcknowledgeAlert(c.Request.Context(), id, c.GetString("username"), req.Comment)
+	if err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			Error(c, http.StatusNotFound, "记录不存在")
+		case errors.Is(err, service.ErrAlertResolved),
+			errors.Is(err, service.ErrAlertAcknowledged),
+			errors.Is(err, service.ErrAlertNotFiring):
+			Error(c, http.StatusConflict, err.Error())
+		default:
+			Error(c, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
+	Success(c, record)
+}
+
+// AssignAlert 指派告警处理人
+func (h *AlertHandler) AssignAlert(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		Error(c, http.StatusBadRequest, "无效的 ID")
+		return
+	}
+
+	var req struct {
+		Assignee string `json:"assignee"` // 处理人，为空表示取消指派
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		Error(c, http.StatusBadRequest, "请求参数无效: "+err.Error())
+		return
+	}
+
+	record, err := h.alertService.AssignAlert(c.Request.Context(), id, c.GetString("username"), req.Assignee)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			Error(c, http.StatusNotFound, "记录不存在")
+			return
+		}
+		Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	Success(c, record)
+}
+
+// AddComment 添加告警评论
+func (h *AlertHandler) AddComment(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		Error(c, http.StatusBadRequest, "无效的 ID")
+		return
+	}
+
+	var req struct {
+		Content string `json:"content" binding:"required,max=1024"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		Error(c, http.StatusBadRequest, "请求参数无效: "+err.Error())
+		return
+	}
+
+	activity, err := h.alertService.AddComment(c.Request.Context(), id, c.GetString("username"), req.Content)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			Error(c, http.StatusNotFound, "记录不存在")
+			return
+		}
+		Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	Success(c, activity)
+}
+
+// ListActivities 获取告警的活动时间线（触发、通知、确认、指派、评论、恢复）
+func (h *AlertHandler) ListActivities(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		Error(c, http.StatusBadRequest, "无效的 ID")
+		return
+	}
+
+	activities, err := h.alertService.ListActivities(c.Request.Context(), id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			Error(c, http.StatusNotFound, "记录不存在")
+			return
+		}
+		Error(c, http.StatusInternalServerError, "获取告警活动失败")
+		return
+	}
 
-	Success(c, nil)
+	Success(c, activities)
 }
@@ -38,13 +38,17 @@ func (h *DashboardHandler) GetSummary(c *gin.Context) {
 	}
 
 	// 统计各状态数量
-	var healthyCount, unhealthyCount, unknownCount, disabledCount int64
+	var healthyCount, degradedCount, unhealthyCount, flappingCount, unknownCount, disabledCount int64
 	for _, t := range targets {
 		switch t.Status {
 		case model.TargetStatusHealthy:
 			healthyCount++
+		case model.TargetStatusDegraded:
+			degradedCount++
 		case model.TargetStatusUnhealthy:
 			unhealthyCount++
+		case model.TargetStatusFlapping:
+			flappingCount++
 		case model.TargetStatusDisabled:
 			disabledCount++
 		default:
@@ -65,7 +69,9 @@ func (h *DashboardHandler) GetSummary(c *gin.Context) {
 	Success(c, gin.H{
 		"total_targets":   total,
 		"healthy_count":   healthyCount,
+		"degraded_count":  degradedCount,
 		"unhealthy_count": unhealthyCount,
+		"flapping_count":  flappingCount,
 		"unknown_count":   unknownCount,
 		"disabled_count":  disabledCount,
 		"recent_alerts":   alerts,
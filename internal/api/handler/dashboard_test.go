@@ -0,0 +1,72 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/thingsboard-rxprobe/internal/model"
+	"github.com/thingsboard-rxprobe/internal/repository"
+	"github.com/thingsboard-rxprobe/internal/service"
+)
+
+func TestDashboardSummaryCountsStatuses(t *testing.T) {
+	db := newTestDB(t, &model.ProbeTarget{}, &model.AlertRecord{})
+	targetRepo := repository.NewTargetRepository(db)
+	alertRepo := repository.NewAlertRepository(db)
+
+	statuses := []string{
+		model.TargetStatusHealthy,
+		model.TargetStatusHealthy,
+		model.TargetStatusDegraded,
+		model.TargetStatusDegraded,
+		model.TargetStatusDegraded,
+		model.TargetStatusUnhealthy,
+		model.TargetStatusFlapping,
+		model.TargetStatusDisabled,
+		model.TargetStatusUnknown,
+	}
+	for i, status := range statuses {
+		target := &model.ProbeTarget{Name: fmt.Sprintf("target-%d", i), Type: "http", Status: status}
+		if err := targetRepo.Create(context.Background(), target); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	h := NewDashboardHandler(
+		service.NewProbeService(targetRepo, nil, alertRepo, nil, nil, nil),
+		service.NewAlertService(alertRepo, targetRepo, nil, nil, nil, nil, nil, nil, nil, nil, nil, "", service.GroupingConfig{}),
+	)
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/dashboard/summary", h.GetSummary)
+
+	w := doJSON(t, r, http.MethodGet, "/dashboard/summary", nil)
+	var resp struct {
+		Data map[string]any `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		key  string
+		want float64
+	}{
+		{"total_targets", 9},
+		{"healthy_count", 2},
+		{"degraded_count", 3},
+		{"unhealthy_count", 1},
+		{"flapping_count", 1},
+		{"disabled_count", 1},
+		{"unknown_count", 1},
+	}
+	for _, tt := range tests {
+		if got := resp.Data[tt.key]; got != tt.want {
+			t.Errorf("%s = %v, want %v", tt.key, got, tt.want)
+		}
+	}
+}
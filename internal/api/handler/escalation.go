@@ -0,0 +1,146 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/thingsboard-rxprobe/internal/model"
+	"github.com/thingsboard-rxprobe/internal/service"
+	"gorm.io/gorm"
+)
+
+// EscalationHandler 升级策略处理器
+type EscalationHandler struct {
+	escalationService *service.EscalationService
+}
+
+// NewEscalationHandler 创建升级策略处理器
+func NewEscalationHandler(escalationService *service.EscalationService) *EscalationHandler {
+	return &EscalationHandler{escalationService: escalationService}
+}
+
+// EscalationPolicyRequest 创建/更新升级策略请求
+type EscalationPolicyRequest struct {
+	Name        string                 `json:"name" binding:"required"`
+	Steps       []model.EscalationStep `json:"steps" binding:"required"` // 升级步骤，按延迟递增排列
+	Description string                 `json:"description"`
+}
+
+// apply 将请求内容写入升级策略
+func (req *EscalationPolicyRequest) apply(policy *model.EscalationPolicy) error {
+	steps, err := json.Marshal(req.Steps)
+	if err != nil {
+		return err
+	}
+	policy.Name = req.Name
+	policy.Steps = steps
+	policy.Description = req.Description
+	return policy.Validate()
+}
+
+// List 获取升级策略列表
+func (h *EscalationHandler) List(c *gin.Context) {
+	policies, err := h.escalationService.List(c.Request.Context())
+	if err != nil {
+		Error(c, http.StatusInternalServerError, "获取升级策略列表失败")
+		return
+	}
+	Success(c, policies)
+}
+
+// Create 创建升级策略
+func (h *EscalationHandler) Create(c *gin.Context) {
+	var req EscalationPolicyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		Error(c, http.StatusBadRequest, "请求参数无效: "+err.Error())
+		return
+	}
+
+	policy := &model.EscalationPolicy{}
+	if err := req.apply(policy); err != nil {
+		Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := h.escalationService.Create(c.Request.Context(), policy); err != nil {
+		Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	Success(c, policy)
+}
+
+// Get 获取升级策略详情
+func (h *EscalationHandler) Get(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		Error(c, http.StatusBadRequest, "无效的 ID")
+		return
+	}
+
+	policy, err := h.escalationService.Get(c.Request.Context(), id)
+	if err != nil {
+		Error(c, http.StatusNotFound, "升级策略不存在")
+		return
+	}
+
+	Success(c, policy)
+}
+
+// Update 更新升级策略
+func (h *EscalationHandler) Update(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		Error(c, http.StatusBadRequest, "无效的 ID")
+		return
+	}
+
+	policy, err := h.escalationService.Get(c.Request.Context(), id)
+	if err != nil {
+		Error(c, http.StatusNotFound, "升级策略不存在")
+		return
+	}
+
+	var req EscalationPolicyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		Error(c, http.StatusBadRequest, "请求参数无效: "+err.Error())
+		return
+	}
+	if err := req.apply(policy); err != nil {
+		Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := h.escalationService.Update(c.Request.Context(), policy); err != nil {
+		Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	Success(c, policy)
+}
+
+// Delete 删除升级策略
+func (h *EscalationHandler) Delete(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		Error(c, http.StatusBadRequest, "无效的 ID")
+		return
+	}
+
+	if err := h.escalationService.Delete(c.Request.Context(), id); err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			Error(c, http.StatusNotFound, "升级策略不存在")
+		case errors.Is(err, service.ErrEscalationPolicyInUse):
+			Error(c, http.StatusConflict, err.Error())
+		default:
+			Error(c, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
+	Success(c, nil)
+}
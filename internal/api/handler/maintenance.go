@@ -0,0 +1,167 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/thingsboard-rxprobe/internal/model"
+	"github.com/thingsboard-rxprobe/internal/service"
+	"gorm.io/gorm"
+)
+
+// MaintenanceHandler 维护窗口处理器
+type MaintenanceHandler struct {
+	maintenanceService *service.MaintenanceService
+}
+
+// NewMaintenanceHandler 创建维护窗口处理器
+func NewMaintenanceHandler(maintenanceService *service.MaintenanceService) *MaintenanceHandler {
+	return &MaintenanceHandler{maintenanceService: maintenanceService}
+}
+
+// MaintenanceWindowRequest 创建/更新维护窗口请求
+type MaintenanceWindowRequest struct {
+	Name            string     `json:"name" binding:"required"`
+	TargetID        uint64     `json:"target_id"`        // 匹配目标 ID
+	Group           string     `json:"group"`            // 匹配分组
+	TargetType      string     `json:"target_type"`      // 匹配探针类型
+	NamePattern     string     `json:"name_pattern"`     // 匹配目标名称的正则表达式
+	Cron            string     `json:"cron"`             // 周期窗口的 cron 表达式，为空表示一次性窗口
+	DurationMinutes int        `json:"duration_minutes"` // 周期窗口每次持续时长（分钟）
+	Timezone        string     `json:"timezone"`         // cron 表达式使用的时区
+	StartsAt        *time.Time `json:"starts_at"`        // 开始时间
+	EndsAt          *time.Time `json:"ends_at"`          // 结束时间
+	Enabled         *bool      `json:"enabled"`          // 是否启用，默认启用
+	Comment         string     `json:"comment"`          // 备注
+}
+
+// apply 将请求内容写入维护窗口
+func (req *MaintenanceWindowRequest) apply(window *model.MaintenanceWindow) {
+	window.Name = req.Name
+	window.TargetMatcher = model.TargetMatcher{
+		TargetID:    req.TargetID,
+		Group:       req.Group,
+		TargetType:  req.TargetType,
+		NamePattern: req.NamePattern,
+	}
+	window.Cron = req.Cron
+	window.DurationMinutes = req.DurationMinutes
+	window.Timezone = req.Timezone
+	window.StartsAt = req.StartsAt
+	window.EndsAt = req.EndsAt
+	if req.Enabled != nil {
+		window.Enabled = *req.Enabled
+	}
+	window.Comment = req.Comment
+}
+
+// List 获取维护窗口列表
+func (h *MaintenanceHandler) List(c *gin.Context) {
+	windows, err := h.maintenanceService.List(c.Request.Context())
+	if err != nil {
+		Error(c, http.StatusInternalServerError, "获取维护窗口列表失败")
+		return
+	}
+	Success(c, windows)
+}
+
+// Create 创建维护窗口
+func (h *MaintenanceHandler) Create(c *gin.Context) {
+	var req MaintenanceWindowRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		Error(c, http.StatusBadRequest, "请求参数无效: "+err.Error())
+		return
+	}
+
+	window := &model.MaintenanceWindow{
+		Enabled:   true,
+		CreatedBy: c.GetString("username"),
+	}
+	req.apply(window)
+	if err := window.Validate(); err != nil {
+		Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := h.maintenanceService.Create(c.Request.Context(), window); err != nil {
+		Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	Success(c, window)
+}
+
+// Get 获取维护窗口详情
+func (h *MaintenanceHandler) Get(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		Error(c, http.StatusBadRequest, "无效的 ID")
+		return
+	}
+
+	window, err := h.maintenanceService.Get(c.Request.Context(), id)
+	if err != nil {
+		Error(c, http.StatusNotFound, "维护窗口不存在")
+		return
+	}
+
+	Success(c, window)
+}
+
+// Update 更新维护窗口
+func (h *MaintenanceHandler) Update(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		Error(c, http.StatusBadRequest, "无效的 ID")
+		return
+	}
+
+	view, err := h.maintenanceService.Get(c.Request.Context(), id)
+	if err != nil {
+		Error(c, http.StatusNotFound, "维护窗口不存在")
+		return
+	}
+
+	var req MaintenanceWindowRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		Error(c, http.StatusBadRequest, "请求参数无效: "+err.Error())
+		return
+	}
+
+	window := view.MaintenanceWindow
+	req.apply(window)
+	if err := window.Validate(); err != nil {
+		Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := h.maintenanceService.Update(c.Request.Context(), window); err != nil {
+		Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	Success(c, window)
+}
+
+// Delete 删除维护窗口
+func (h *MaintenanceHandler) Delete(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		Error(c, http.StatusBadRequest, "无效的 ID")
+		return
+	}
+
+	if err := h.maintenanceService.Delete(c.Request.Context(), id); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			Error(c, http.StatusNotFound, "维护窗口不存在")
+			return
+		}
+		Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	Success(c, nil)
+}
@@ -1,49 +1,53 @@
 package handler
 
 import (
-	"bytes"
-	"context"
 	"encoding/json"
-	"fmt"
 	"net/http"
 	"strconv"
-	"strings"
-	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/thingsboard-rxprobe/internal/model"
+	"github.com/thingsboard-rxprobe/internal/notifier"
 	"github.com/thingsboard-rxprobe/internal/repository"
+	"gorm.io/datatypes"
 )
 
 // NotifierHandler 通知渠道处理器
 type NotifierHandler struct {
-	repo *repository.NotifierRepository
+	repo      *repository.NotifierRepository
+	notifiers *notifier.Registry
 }
 
 // NewNotifierHandler 创建通知渠道处理器
-func NewNotifierHandler(repo *repository.NotifierRepository) *NotifierHandler {
-	return &NotifierHandler{repo: repo}
+func NewNotifierHandler(repo *repository.NotifierRepository, notifiers *notifier.Registry) *NotifierHandler {
+	return &NotifierHandler{repo: repo, notifiers: notifiers}
 }
 
 // CreateNotifierRequest 创建通知渠道请求
 type CreateNotifierRequest struct {
-	Name        string `json:"name" binding:"required"`
-	Type        string `json:"type" binding:"required"`
-	WebhookURL  string `json:"webhook_url" binding:"required"`
-	MessageTpl  string `json:"message_tpl"`
-	MentionAll  bool   `json:"mention_all"`
-	Enabled     bool   `json:"enabled"`
-	Description string `json:"description"`
+	Name        string         `json:"name" binding:"required"`
+	Type        string         `json:"type" binding:"required"`
+	WebhookURL  string         `json:"webhook_url"`
+	Secret      string         `json:"secret"`
+	MessageTpl  string         `json:"message_tpl"`
+	MentionAll  bool           `json:"mention_all"`
+	AtMobiles   string         `json:"at_mobiles"`
+	Config      map[string]any `json:"config"` // 渠道类型专属配置
+	Enabled     bool           `json:"enabled"`
+	Description string         `json:"description"`
 }
 
 // UpdateNotifierRequest 更新通知渠道请求
 type UpdateNotifierRequest struct {
-	Name        string `json:"name"`
-	WebhookURL  string `json:"webhook_url"`
-	MessageTpl  string `json:"message_tpl"`
-	MentionAll  *bool  `json:"mention_all"`
-	Enabled     *bool  `json:"enabled"`
-	Description string `json:"description"`
+	Name        string         `json:"name"`
+	WebhookURL  string         `json:"webhook_url"`
+	Secret      *string        `json:"secret"`      // 未提供时保持不变（密钥不会返回给前端），空字符串表示清除
+	MessageTpl  *string        `json:"message_tpl"` // 未提供时保持不变，空字符串表示恢复默认模板
+	MentionAll  *bool          `json:"mention_all"`
+	AtMobiles   *string        `json:"at_mobiles"`
+	Config      map[string]any `json:"config"` // 渠道类型专属配置，为空时保持不变；其中省略或为掩码的敏感字段保持已保存的值
+	Enabled     *bool          `json:"enabled"`
+	Description string         `json:"description"`
 }
 
 // List 获取通知渠道列表
@@ -53,9 +57,18 @@ func (h *NotifierHandler) List(c *gin.Context) {
 		Error(c, http.StatusInternalServerError, "获取通知渠道列表失败")
 		return
 	}
+	for _, channel := range channels {
+		h.maskSecrets(channel)
+	}
 	Success(c, channels)
 }
 
+// maskSecrets 隐藏响应中的敏感信息：签名密钥不返回（仅标记是否已设置），Webhook URL 中的凭据、Config 中的密码与请求头值替换为掩码
+func (h *NotifierHandler) maskSecrets(channel *model.NotifyChannel) {
+	channel.HasSecret = channel.Secret != ""
+	h.notifiers.MaskSecrets(channel)
+}
+
 // Create 创建通知渠道
 func (h *NotifierHandler) Create(c *gin.Context) {
 	var req CreateNotifierRequest
@@ -64,33 +77,37 @@ func (h *NotifierHandler) Create(c *gin.Context) {
 		return
 	}
 
-	// 验证通知类型
-	if req.Type != string(model.NotifyChannelTypeWeCom) {
-		Error(c, http.StatusBadRequest, "不支持的通知类型")
-		return
-	}
-
-	// 验证 webhook URL
-	if !strings.HasPrefix(req.WebhookURL, "http://") && !strings.HasPrefix(req.WebhookURL, "https://") {
-		Error(c, http.StatusBadRequest, "Webhook URL 格式无效")
-		return
-	}
-
 	channel := &model.NotifyChannel{
 		Name:        req.Name,
 		Type:        model.NotifyChannelType(req.Type),
 		WebhookURL:  req.WebhookURL,
+		Secret:      req.Secret,
 		MessageTpl:  req.MessageTpl,
 		MentionAll:  req.MentionAll,
+		AtMobiles:   req.AtMobiles,
+		Config:      marshalChannelConfig(req.Config),
 		Enabled:     req.Enabled,
 		Description: req.Description,
 	}
 
+	// 按渠道类型验证配置
+	if err := h.notifiers.Validate(channel); err != nil {
+		Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	// 验证消息模板
+	if err := notifier.ValidateTemplate(req.MessageTpl); err != nil {
+		Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
 	if err := h.repo.Create(c.Request.Context(), channel); err != nil {
 		Error(c, http.StatusInternalServerError, "创建通知渠道失败")
 		return
 	}
 
+	h.maskSecrets(channel)
 	Success(c, channel)
 }
 
@@ -108,6 +125,7 @@ func (h *NotifierHandler) Get(c *gin.Context) {
 		return
 	}
 
+	h.maskSecrets(channel)
 	Success(c, channel)
 }
 
@@ -136,18 +154,31 @@ func (h *NotifierHandler) Update(c *gin.Context) {
 		channel.Name = req.Name
 	}
 	if req.WebhookURL != "" {
-		if !strings.HasPrefix(req.WebhookURL, "http://") && !strings.HasPrefix(req.WebhookURL, "https://") {
-			Error(c, http.StatusBadRequest, "Webhook URL 格式无效")
+		// 响应中 Webhook URL 的凭据为掩码，提交未修改的 URL 时保持已保存的值
+		channel.WebhookURL = notifier.RestoreWebhookURL(req.WebhookURL, channel.WebhookURL)
+	}
+	if req.Secret != nil {
+		channel.Secret = *req.Secret
+	}
+	if req.MessageTpl != nil {
+		if err := notifier.ValidateTemplate(*req.MessageTpl); err != nil {
+			Error(c, http.StatusBadRequest, err.Error())
 			return
 		}
-		channel.WebhookURL = req.WebhookURL
-	}
-	if req.MessageTpl != "" {
-		channel.MessageTpl = req.MessageTpl
+		channel.MessageTpl = *req.MessageTpl
 	}
 	if req.MentionAll != nil {
 		channel.MentionAll = *req.MentionAll
 	}
+	if req.AtMobiles != nil {
+		channel.AtMobiles = *req.AtMobiles
+	}
+	if req.Config != nil {
+		// 响应中的敏感配置为掩码，省略或提交掩码时保持已保存的值
+		stored := *channel
+		channel.Config = marshalChannelConfig(req.Config)
+		h.notifiers.RestoreSecrets(channel, &stored)
+	}
 	if req.Enabled != nil {
 		channel.Enabled = *req.Enabled
 	}
@@ -155,11 +186,17 @@ func (h *NotifierHandler) Update(c *gin.Context) {
 		channel.Description = req.Description
 	}
 
+	if err := h.notifiers.Validate(channel); err != nil {
+		Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
 	if err := h.repo.Update(c.Request.Context(), channel); err != nil {
 		Error(c, http.StatusInternalServerError, "更新通知渠道失败")
 		return
 	}
 
+	h.maskSecrets(channel)
 	Success(c, channel)
 }
 
@@ -181,9 +218,20 @@ func (h *NotifierHandler) Delete(c *gin.Context) {
 
 // TestNotifierRequest 测试通知渠道请求
 type TestNotifierRequest struct {
-	WebhookURL string `json:"webhook_url" binding:"required"`
-	Type       string `json:"type" binding:"required"`
-	MentionAll bool   `json:"mention_all"`
+	ID         uint64         `json:"id"` // 编辑已有渠道时的渠道 ID，未填写的密钥与掩码配置使用已保存的值
+	WebhookURL string         `json:"webhook_url"`
+	Type       string         `json:"type" binding:"required"`
+	Secret     string         `json:"secret"`
+	MessageTpl string         `json:"message_tpl"`
+	MentionAll bool           `json:"mention_all"`
+	AtMobiles  string         `json:"at_mobiles"`
+	Config     map[string]any `json:"config"`
+}
+
+// PreviewNotifierRequest 预览消息模板请求
+type PreviewNotifierRequest struct {
+	MessageTpl string `json:"message_tpl"`
+	Status     string `json:"status"` // firing, resolved；默认 firing
 }
 
 // Test 测试通知渠道
@@ -194,90 +242,88 @@ func (h *NotifierHandler) Test(c *gin.Context) {
 		return
 	}
 
-	// 验证通知类型
-	if req.Type != string(model.NotifyChannelTypeWeCom) {
-		Error(c, http.StatusBadRequest, "不支持的通知类型")
+	channel := &model.NotifyChannel{
+		Name:       "测试渠道",
+		Type:       model.NotifyChannelType(req.Type),
+		WebhookURL: req.WebhookURL,
+		Secret:     req.Secret,
+		MessageTpl: req.MessageTpl,
+		MentionAll: req.MentionAll,
+		AtMobiles:  req.AtMobiles,
+		Config:     marshalChannelConfig(req.Config),
+	}
+	if req.ID > 0 {
+		if saved, err := h.repo.GetByID(c.Request.Context(), req.ID); err == nil {
+			if channel.Secret == "" {
+				channel.Secret = saved.Secret
+			}
+			channel.WebhookURL = notifier.RestoreWebhookURL(channel.WebhookURL, saved.WebhookURL)
+			h.notifiers.RestoreSecrets(channel, saved)
+		}
+	}
+	if err := h.notifiers.Validate(channel); err != nil {
+		Error(c, http.StatusBadRequest, err.Error())
 		return
 	}
 
-	// 构建测试消息（使用默认格式）
-	message := fmt.Sprintf(`🚨 测试通知
-
-目标：测试目标
-类型：HTTP
-原因：这是一条测试消息
-时间：%s`, time.Now().Format("2006-01-02 15:04:05"))
-
-	// 发送测试消息
-	if err := sendWeComMessage(c.Request.Context(), req.WebhookURL, message, req.MentionAll); err != nil {
-		Error(c, http.StatusInternalServerError, "发送测试消息失败: "+err.Error())
+	// 使用示例告警校验模板并发送测试消息（未指定模板时使用默认模板）
+	if err := notifier.ValidateTemplate(req.MessageTpl); err != nil {
+		Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	resp, err := h.notifiers.Send(c.Request.Context(), channel, notifier.SampleAlert(model.AlertStatusFiring))
+	if err != nil {
+		message := "发送测试消息失败: " + err.Error()
+		if resp != nil && resp.Body != "" {
+			message += ": " + resp.Body
+		}
+		Error(c, http.StatusInternalServerError, message)
 		return
 	}
 
 	Success(c, gin.H{"message": "测试消息发送成功"})
 }
 
-// GetTypes 获取支持的通知类型
-func (h *NotifierHandler) GetTypes(c *gin.Context) {
-	types := []gin.H{
-		{
-			"type":        "wecom",
-			"name":        "企业微信",
-			"description": "通过企业微信群机器人发送通知",
-		},
-	}
-	Success(c, types)
-}
-
-// sendWeComMessage 发送企业微信消息
-func sendWeComMessage(ctx context.Context, webhookURL, content string, mentionAll bool) error {
-	msg := struct {
-		MsgType string `json:"msgtype"`
-		Text    struct {
-			Content       string   `json:"content"`
-			MentionedList []string `json:"mentioned_list,omitempty"`
-		} `json:"text"`
-	}{
-		MsgType: "text",
-	}
-	msg.Text.Content = content
-	if mentionAll {
-		msg.Text.MentionedList = []string{"@all"}
+// Preview 使用示例告警预览消息模板的渲染结果
+func (h *NotifierHandler) Preview(c *gin.Context) {
+	var req PreviewNotifierRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		Error(c, http.StatusBadRequest, "请求参数无效: "+err.Error())
+		return
 	}
 
-	data, err := json.Marshal(msg)
-	if err != nil {
-		return fmt.Errorf("序列化消息失败: %w", err)
+	status := model.AlertStatusFiring
+	switch req.Status {
+	case "", string(model.AlertStatusFiring):
+	case string(model.AlertStatusResolved):
+		status = model.AlertStatusResolved
+	default:
+		Error(c, http.StatusBadRequest, "不支持的告警状态: "+req.Status)
+		return
 	}
 
-	client := &http.Client{Timeout: 10 * time.Second}
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhookURL, bytes.NewReader(data))
+	content, err := notifier.Render(req.MessageTpl, notifier.SampleAlert(status))
 	if err != nil {
-		return fmt.Errorf("创建请求失败: %w", err)
+		Error(c, http.StatusBadRequest, err.Error())
+		return
 	}
-	req.Header.Set("Content-Type", "application/json")
 
-	resp, err := client.Do(req)
-	if err != nil {
-		return fmt.Errorf("发送请求失败: %w", err)
-	}
-	defer resp.Body.Close()
+	Success(c, gin.H{"content": content})
+}
 
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("API 返回错误: HTTP %d", resp.StatusCode)
-	}
+// GetTypes 获取支持的通知类型及其配置 Schema
+func (h *NotifierHandler) GetTypes(c *gin.Context) {
+	Success(c, h.notifiers.Types())
+}
 
-	var result struct {
-		ErrCode int    `json:"errcode"`
-		ErrMsg  string `json:"errmsg"`
-	}
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return fmt.Errorf("解析响应失败: %w", err)
+// marshalChannelConfig 序列化渠道类型专属配置
+func marshalChannelConfig(config map[string]any) datatypes.JSON {
+	if len(config) == 0 {
+		return datatypes.JSON("{}")
 	}
-
-	if result.ErrCode != 0 {
-		return fmt.Errorf("企业微信 API 错误: %d - %s", result.ErrCode, result.ErrMsg)
+	data, err := json.Marshal(config)
+	if err != nil {
+		return datatypes.JSON("{}")
 	}
-
-	return nil
+	return data
 }
@@ -0,0 +1,437 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/thingsboard-rxprobe/internal/model"
+	"github.com/thingsboard-rxprobe/internal/notifier"
+	"github.com/thingsboard-rxprobe/internal/repository"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+// 测试用的敏感信息，任何读取接口的响应中都不应出现
+const (
+	testSigningSecret = "sign-secret-value"
+	testSMTPPassword  = "smtp-password-value"
+	testAuthHeader    = "Bearer header-token-value"
+	testWeComKey      = "wecom-key-value"
+	testDingTalkToken = "dingtalk-token-value"
+	testSlackToken    = "T000/B000/slack-token-value"
+	testBotToken      = "123456:bot-token-value"
+)
+
+// newTestDB 创建迁移好指定数据表的内存数据库
+func newTestDB(t *testing.T, models ...any) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{
+		Logger: gormlogger.Default.LogMode(gormlogger.Silent),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(models...); err != nil {
+		t.Fatal(err)
+	}
+	sqlDB, _ := db.DB()
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+	return db
+}
+
+func newNotifierTestRouter(t *testing.T) (*gin.Engine, *repository.NotifierRepository) {
+	t.Helper()
+	db := newTestDB(t, &model.NotifyChannel{})
+	repo := repository.NewNotifierRepository(db)
+	h := NewNotifierHandler(repo, notifier.NewRegistry())
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/notifiers", h.List)
+	r.POST("/notifiers", h.Create)
+	r.GET("/notifiers/:id", h.Get)
+	r.PUT("/notifiers/:id", h.Update)
+	r.POST("/notifiers/preview", h.Preview)
+	return r, repo
+}
+
+// serveJSON 发送 JSON 请求并返回响应，不检查状态码
+func serveJSON(t *testing.T, r http.Handler, method, path string, body any) *httptest.ResponseRecorder {
+	t.Helper()
+	var reader *bytes.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		reader = bytes.NewReader(data)
+	} else {
+		reader = bytes.NewReader(nil)
+	}
+	req := httptest.NewRequest(method, path, reader)
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+// doJSON 发送 JSON 请求，要求返回 200
+func doJSON(t *testing.T, r http.Handler, method, path string, body any) *httptest.ResponseRecorder {
+	t.Helper()
+	w := serveJSON(t, r, method, path, body)
+	if w.Code != http.StatusOK {
+		t.Fatalf("%s %s 返回 %d: %s", method, path, w.Code, w.Body.String())
+	}
+	return w
+}
+
+// assertNoSecrets 检查响应中不包含任何敏感信息
+func assertNoSecrets(t *testing.T, name, body string) {
+	t.Helper()
+	for _, secret := range []string{testSigningSecret, testSMTPPassword, "header-token-value", testWeComKey, testDingTalkToken, "slack-token-value", testBotToken} {
+		if strings.Contains(body, secret) {
+			t.Fatalf("%s 响应泄露了敏感信息 %q: %s", name, secret, body)
+		}
+	}
+}
+
+func TestNotifierHandlerMasksSecrets(t *testing.T) {
+	r, repo := newNotifierTestRouter(t)
+
+	requests := []CreateNotifierRequest{
+		{
+			Name:       "dingtalk",
+			Type:       string(model.NotifyChannelTypeDingTalk),
+			WebhookURL: "https://oapi.dingtalk.com/robot/send",
+			Secret:     testSigningSecret,
+			Enabled:    true,
+		},
+		{
+			Name: "email",
+			Type: string(model.NotifyChannelTypeEmail),
+			Config: map[string]any{
+				"smtp_host": "smtp.example.com",
+				"username":  "probe",
+				"password":  testSMTPPassword,
+				"from":      "rxprobe@example.com",
+				"to":        "ops@example.com",
+			},
+			Enabled: true,
+		},
+	}
+
+	var ids []uint64
+	for _, req := range requests {
+		w := doJSON(t, r, http.MethodPost, "/notifiers", req)
+		assertNoSecrets(t, "创建 "+req.Name, w.Body.String())
+		var resp struct {
+			Data model.NotifyChannel `json:"data"`
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, resp.Data.ID)
+	}
+
+	w := doJSON(t, r, http.MethodGet, "/notifiers", nil)
+	assertNoSecrets(t, "列表", w.Body.String())
+	if !strings.Contains(w.Body.String(), `"has_secret":true`) {
+		t.Fatalf("列表应标记已设置签名密钥: %s", w.Body.String())
+	}
+
+	emailPath := "/notifiers/" + strconv.FormatUint(ids[1], 10)
+	w = doJSON(t, r, http.MethodGet, emailPath, nil)
+	assertNoSecrets(t, "详情", w.Body.String())
+	var detail struct {
+		Data model.NotifyChannel `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &detail); err != nil {
+		t.Fatal(err)
+	}
+	config := notifier.ChannelConfig(&detail.Data)
+	if config["password"] != notifier.SecretMask {
+		t.Fatalf("密码应替换为掩码: %q", config["password"])
+	}
+
+	// 回传掩码并修改其他配置：密码保持不变
+	config["to"] = "oncall@example.com"
+	w = doJSON(t, r, http.MethodPut, emailPath, map[string]any{"config": config})
+	assertNoSecrets(t, "更新", w.Body.String())
+	saved, err := repo.GetByID(context.Background(), ids[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	savedConfig := notifier.ChannelConfig(saved)
+	if savedConfig["password"] != testSMTPPassword || savedConfig["to"] != "oncall@example.com" {
+		t.Fatalf("回传掩码时密码应保持不变: %v", savedConfig)
+	}
+
+	// 省略密码字段时保持已保存的值，提交空字符串时清除
+	delete(config, "password")
+	doJSON(t, r, http.MethodPut, emailPath, map[string]any{"config": config})
+	if saved, err = repo.GetByID(context.Background(), ids[1]); err != nil {
+		t.Fatal(err)
+	}
+	if got := notifier.ChannelConfig(saved)["password"]; got != testSMTPPassword {
+		t.Fatalf("省略密码时应保持不变，实际为 %q", got)
+	}
+
+	config["password"] = ""
+	doJSON(t, r, http.MethodPut, emailPath, map[string]any{"config": config})
+	if saved, err = repo.GetByID(context.Background(), ids[1]); err != nil {
+		t.Fatal(err)
+	}
+	if got := notifier.ChannelConfig(saved)["password"]; got != "" {
+		t.Fatalf("提交空密码时应清除，实际为 %q", got)
+	}
+}
+
+func TestNotifierHandlerMasksHeaders(t *testing.T) {
+	r, repo := newNotifierTestRouter(t)
+
+	w := doJSON(t, r, http.MethodPost, "/notifiers", CreateNotifierRequest{
+		Name:       "webhook",
+		Type:       string(model.NotifyChannelTypeWebhook),
+		WebhookURL: "https://example.com/hook",
+		Secret:     testSigningSecret,
+		Config:     map[string]any{"headers": "Authorization: " + testAuthHeader + "\nX-Env: prod"},
+		Enabled:    true,
+	})
+	assertNoSecrets(t, "创建", w.Body.String())
+	var detail struct {
+		Data model.NotifyChannel `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &detail); err != nil {
+		t.Fatal(err)
+	}
+
+	path := "/notifiers/" + strconv.FormatUint(detail.Data.ID, 10)
+	w = doJSON(t, r, http.MethodGet, path, nil)
+	assertNoSecrets(t, "详情", w.Body.String())
+	if err := json.Unmarshal(w.Body.Bytes(), &detail); err != nil {
+		t.Fatal(err)
+	}
+	config := notifier.ChannelConfig(&detail.Data)
+	if config["headers"] != "Authorization: "+notifier.SecretMask+"\nX-Env: "+notifier.SecretMask {
+		t.Fatalf("请求头应保留名称并隐藏值: %q", config["headers"])
+	}
+
+	// 回传掩码并修改其他请求头：敏感值保持不变
+	config["headers"] = "Authorization: " + notifier.SecretMask + "\nX-Env: staging"
+	w = doJSON(t, r, http.MethodPut, path, map[string]any{"config": config})
+	assertNoSecrets(t, "更新", w.Body.String())
+	saved, err := repo.GetByID(context.Background(), detail.Data.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := notifier.ChannelConfig(saved)["headers"]; got != "Authorization: "+testAuthHeader+"\nX-Env: staging" {
+		t.Fatalf("更新后请求头 = %q", got)
+	}
+	if saved.Secret != testSigningSecret {
+		t.Fatalf("未提交签名密钥时应保持不变，实际为 %q", saved.Secret)
+	}
+}
+
+func TestNotifierHandlerMasksWebhookURL(t *testing.T) {
+	r, repo := newNotifierTestRouter(t)
+
+	wecomURL := "https://qyapi.weixin.qq.com/cgi-bin/webhook/send?key=" + testWeComKey
+	dingtalkURL := "https://oapi.dingtalk.com/robot/send?access_token=" + testDingTalkToken
+	var paths []string
+	for _, req := range []CreateNotifierRequest{
+		{Name: "wecom", Type: string(model.NotifyChannelTypeWeCom), WebhookURL: wecomURL, Enabled: true},
+		{Name: "dingtalk", Type: string(model.NotifyChannelTypeDingTalk), WebhookURL: dingtalkURL, Enabled: true},
+	} {
+		w := doJSON(t, r, http.MethodPost, "/notifiers", req)
+		assertNoSecrets(t, "创建 "+req.Name, w.Body.String())
+		var resp struct {
+			Data model.NotifyChannel `json:"data"`
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+			t.Fatal(err)
+		}
+		paths = append(paths, "/notifiers/"+strconv.FormatUint(resp.Data.ID, 10))
+	}
+
+	w := doJSON(t, r, http.MethodGet, "/notifiers", nil)
+	assertNoSecrets(t, "列表", w.Body.String())
+
+	var detail struct {
+		Data model.NotifyChannel `json:"data"`
+	}
+	w = doJSON(t, r, http.MethodGet, paths[0], nil)
+	if err := json.Unmarshal(w.Body.Bytes(), &detail); err != nil {
+		t.Fatal(err)
+	}
+	if want := "https://qyapi.weixin.qq.com/cgi-bin/webhook/send?key=" + notifier.SecretMask; detail.Data.WebhookURL != want {
+		t.Fatalf("Webhook URL 应隐藏 key: %q", detail.Data.WebhookURL)
+	}
+
+	// 回传掩码 URL 并修改其他字段：Webhook URL 保持不变
+	w = doJSON(t, r, http.MethodPut, paths[0], map[string]any{"webhook_url": detail.Data.WebhookURL, "description": "ops"})
+	assertNoSecrets(t, "更新", w.Body.String())
+	saved, err := repo.GetByID(context.Background(), detail.Data.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if saved.WebhookURL != wecomURL || saved.Description != "ops" {
+		t.Fatalf("回传掩码时 Webhook URL 应保持不变: %q", saved.WebhookURL)
+	}
+
+	// 修改为新的 URL
+	newURL := "https://oapi.dingtalk.com/robot/send?access_token=new-token"
+	doJSON(t, r, http.MethodPut, paths[1], map[string]any{"webhook_url": newURL})
+	w = doJSON(t, r, http.MethodGet, paths[1], nil)
+	if strings.Contains(w.Body.String(), "new-token") {
+		t.Fatalf("响应泄露了新的 Webhook URL: %s", w.Body.String())
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &detail); err != nil {
+		t.Fatal(err)
+	}
+	if saved, err = repo.GetByID(context.Background(), detail.Data.ID); err != nil || saved.WebhookURL != newURL {
+		t.Fatalf("提交新的 URL 时应更新: %q (%v)", saved.WebhookURL, err)
+	}
+}
+
+func TestNotifierHandlerMasksSlackAndTelegram(t *testing.T) {
+	r, repo := newNotifierTestRouter(t)
+
+	var ids []uint64
+	for _, req := range []CreateNotifierRequest{
+		{Name: "slack", Type: string(model.NotifyChannelTypeSlack), WebhookURL: "https://hooks.slack.com/services/" + testSlackToken, Enabled: true},
+		{Name: "telegram", Type: string(model.NotifyChannelTypeTelegram), Config: map[string]any{"bot_token": testBotToken, "chat_id": "-100123"}, Enabled: true},
+	} {
+		w := doJSON(t, r, http.MethodPost, "/notifiers", req)
+		assertNoSecrets(t, "创建 "+req.Name, w.Body.String())
+		var resp struct {
+			Data model.NotifyChannel `json:"data"`
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, resp.Data.ID)
+	}
+
+	w := doJSON(t, r, http.MethodGet, "/notifiers", nil)
+	assertNoSecrets(t, "列表", w.Body.String())
+	if !strings.Contains(w.Body.String(), "https://hooks.slack.com/services/"+notifier.SecretMask) {
+		t.Fatalf("Slack Webhook URL 应隐藏路径中的令牌: %s", w.Body.String())
+	}
+
+	// 省略 bot_token 时保持已保存的值
+	doJSON(t, r, http.MethodPut, "/notifiers/"+strconv.FormatUint(ids[1], 10), map[string]any{"config": map[string]any{"chat_id": "-100456"}})
+	saved, err := repo.GetByID(context.Background(), ids[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	config := notifier.ChannelConfig(saved)
+	if config["bot_token"] != testBotToken || config["chat_id"] != "-100456" {
+		t.Fatalf("省略 bot_token 时应保持不变: %v", config)
+	}
+}
+
+func TestNotifierHandlerPreview(t *testing.T) {
+	r, _ := newNotifierTestRouter(t)
+
+	tests := []struct {
+		name     string
+		req      PreviewNotifierRequest
+		wantCode int
+		want     string
+	}{
+		{"默认触发模板", PreviewNotifierRequest{}, http.StatusOK, "🚨 告警通知"},
+		{"默认恢复模板", PreviewNotifierRequest{Status: "resolved"}, http.StatusOK, "✅ 恢复通知"},
+		{"自定义模板", PreviewNotifierRequest{MessageTpl: "{{.TargetName}}: {{.Message}}"}, http.StatusOK, "测试目标: 这是一条测试消息"},
+		{"语法错误的模板", PreviewNotifierRequest{MessageTpl: "{{.TargetName"}, http.StatusBadRequest, "解析消息模板失败"},
+		{"字段不存在的模板", PreviewNotifierRequest{MessageTpl: "{{.Nonexistent}}"}, http.StatusBadRequest, "Nonexistent"},
+		{"不支持的状态", PreviewNotifierRequest{Status: "pending"}, http.StatusBadRequest, "不支持的告警状态"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := serveJSON(t, r, http.MethodPost, "/notifiers/preview", tt.req)
+			if w.Code != tt.wantCode {
+				t.Fatalf("状态码 = %d, want %d: %s", w.Code, tt.wantCode, w.Body.String())
+			}
+			var resp struct {
+				Message string `json:"message"`
+				Data    struct {
+					Content string `json:"content"`
+				} `json:"data"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatal(err)
+			}
+			if got := resp.Data.Content + resp.Message; !strings.Contains(got, tt.want) {
+				t.Fatalf("响应 = %q，缺少 %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNotifierHandlerUpdateMessageTemplate(t *testing.T) {
+	r, repo := newNotifierTestRouter(t)
+
+	w := doJSON(t, r, http.MethodPost, "/notifiers", CreateNotifierRequest{
+		Name:       "wecom",
+		Type:       string(model.NotifyChannelTypeWeCom),
+		WebhookURL: "https://qyapi.weixin.qq.com/cgi-bin/webhook/send?key=k",
+		MessageTpl: "{{.TargetName}}",
+		Enabled:    true,
+	})
+	var resp struct {
+		Data model.NotifyChannel `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	id := resp.Data.ID
+	path := "/notifiers/" + strconv.FormatUint(id, 10)
+	messageTpl := func() string {
+		t.Helper()
+		saved, err := repo.GetByID(context.Background(), id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return saved.MessageTpl
+	}
+
+	// 未提交模板时保持不变
+	doJSON(t, r, http.MethodPut, path, map[string]any{"description": "ops"})
+	if got := messageTpl(); got != "{{.TargetName}}" {
+		t.Fatalf("未提交模板时应保持不变，实际为 %q", got)
+	}
+
+	// 无效模板被拒绝且不修改已保存的模板
+	if w := serveJSON(t, r, http.MethodPut, path, map[string]any{"message_tpl": "{{.Nonexistent}}"}); w.Code != http.StatusBadRequest {
+		t.Fatalf("无效模板应返回 400，实际为 %d: %s", w.Code, w.Body.String())
+	}
+	if got := messageTpl(); got != "{{.TargetName}}" {
+		t.Fatalf("无效模板不应保存，实际为 %q", got)
+	}
+
+	// 提交空字符串恢复默认模板
+	doJSON(t, r, http.MethodPut, path, map[string]any{"message_tpl": ""})
+	if got := messageTpl(); got != "" {
+		t.Fatalf("提交空模板时应恢复默认模板，实际为 %q", got)
+	}
+
+	// 创建时同样校验模板
+	w = serveJSON(t, r, http.MethodPost, "/notifiers", CreateNotifierRequest{
+		Name:       "invalid",
+		Type:       string(model.NotifyChannelTypeWeCom),
+		WebhookURL: "https://qyapi.weixin.qq.com/cgi-bin/webhook/send?key=k",
+		MessageTpl: "{{.TargetName",
+	})
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("创建时无效模板应返回 400，实际为 %d", w.Code)
+	}
+}
@@ -152,6 +152,7 @@ func (h *ProbeHandler) TestTarget(c *gin.Context) {
 
 	Success(c, gin.H{
 		"success":    result.Success,
+		"outcome":    result.Outcome(),
 		"latency_ms": result.Latency.Milliseconds(),
 		"message":    result.Message,
 		"metrics":    result.Metrics,
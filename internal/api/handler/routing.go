@@ -0,0 +1,167 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/thingsboard-rxprobe/internal/model"
+	"github.com/thingsboard-rxprobe/internal/service"
+	"gorm.io/gorm"
+)
+
+// RoutingHandler 告警路由规则处理器
+type RoutingHandler struct {
+	routingService *service.RoutingService
+}
+
+// NewRoutingHandler 创建告警路由规则处理器
+func NewRoutingHandler(routingService *service.RoutingService) *RoutingHandler {
+	return &RoutingHandler{routingService: routingService}
+}
+
+// RoutingRuleRequest 创建/更新路由规则请求
+type RoutingRuleRequest struct {
+	Name        string           `json:"name" binding:"required"`
+	Priority    int              `json:"priority"`     // 优先级，越小越先匹配
+	TargetID    uint64           `json:"target_id"`    // 匹配目标 ID
+	Group       string           `json:"group"`        // 匹配分组
+	TargetType  string           `json:"target_type"`  // 匹配探针类型
+	NamePattern string           `json:"name_pattern"` // 匹配目标名称的正则表达式
+	Severities  []model.Severity `json:"severities"`   // 匹配的告警级别
+	ChannelIDs  []uint64         `json:"channel_ids"`  // 命中后通知的渠道 ID 列表
+	Continue    bool             `json:"continue"`     // 命中后是否继续匹配后续规则
+	Enabled     *bool            `json:"enabled"`      // 是否启用，默认启用
+	Description string           `json:"description"`
+}
+
+// apply 将请求内容写入路由规则
+func (req *RoutingRuleRequest) apply(rule *model.RoutingRule) error {
+	severities, err := json.Marshal(req.Severities)
+	if err != nil {
+		return err
+	}
+	channelIDs, err := json.Marshal(req.ChannelIDs)
+	if err != nil {
+		return err
+	}
+	rule.Name = req.Name
+	rule.Priority = req.Priority
+	rule.TargetMatcher = model.TargetMatcher{
+		TargetID:    req.TargetID,
+		Group:       req.Group,
+		TargetType:  req.TargetType,
+		NamePattern: req.NamePattern,
+	}
+	rule.Severities = severities
+	rule.ChannelIDs = channelIDs
+	rule.Continue = req.Continue
+	if req.Enabled != nil {
+		rule.Enabled = *req.Enabled
+	}
+	rule.Description = req.Description
+	return rule.Validate()
+}
+
+// List 获取路由规则列表
+func (h *RoutingHandler) List(c *gin.Context) {
+	rules, err := h.routingService.List(c.Request.Context())
+	if err != nil {
+		Error(c, http.StatusInternalServerError, "获取路由规则列表失败")
+		return
+	}
+	Success(c, rules)
+}
+
+// Create 创建路由规则
+func (h *RoutingHandler) Create(c *gin.Context) {
+	var req RoutingRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		Error(c, http.StatusBadRequest, "请求参数无效: "+err.Error())
+		return
+	}
+
+	rule := &model.RoutingRule{Enabled: true}
+	if err := req.apply(rule); err != nil {
+		Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := h.routingService.Create(c.Request.Context(), rule); err != nil {
+		Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	Success(c, rule)
+}
+
+// Get 获取路由规则详情
+func (h *RoutingHandler) Get(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		Error(c, http.StatusBadRequest, "无效的 ID")
+		return
+	}
+
+	rule, err := h.routingService.Get(c.Request.Context(), id)
+	if err != nil {
+		Error(c, http.StatusNotFound, "路由规则不存在")
+		return
+	}
+
+	Success(c, rule)
+}
+
+// Update 更新路由规则
+func (h *RoutingHandler) Update(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		Error(c, http.StatusBadRequest, "无效的 ID")
+		return
+	}
+
+	rule, err := h.routingService.Get(c.Request.Context(), id)
+	if err != nil {
+		Error(c, http.StatusNotFound, "路由规则不存在")
+		return
+	}
+
+	var req RoutingRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		Error(c, http.StatusBadRequest, "请求参数无效: "+err.Error())
+		return
+	}
+	if err := req.apply(rule); err != nil {
+		Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := h.routingService.Update(c.Request.Context(), rule); err != nil {
+		Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	Success(c, rule)
+}
+
+// Delete 删除路由规则
+func (h *RoutingHandler) Delete(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		Error(c, http.StatusBadRequest, "无效的 ID")
+		return
+	}
+
+	if err := h.routingService.Delete(c.Request.Context(), id); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			Error(c, http.StatusNotFound, "路由规则不存在")
+			return
+		}
+		Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	Success(c, nil)
+}
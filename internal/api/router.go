@@ -4,31 +4,47 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/thingsboard-rxprobe/internal/api/handler"
 	"github.com/thingsboard-rxprobe/internal/api/middleware"
+	"github.com/thingsboard-rxprobe/internal/notifier"
 	"github.com/thingsboard-rxprobe/internal/repository"
 	"github.com/thingsboard-rxprobe/internal/service"
 )
 
 // Router 路由管理器
 type Router struct {
-	engine       *gin.Engine
-	probeService *service.ProbeService
-	alertService *service.AlertService
-	authService  *service.AuthService
-	notifierRepo *repository.NotifierRepository
+	engine              *gin.Engine
+	probeService        *service.ProbeService
+	alertService        *service.AlertService
+	notificationService *service.NotificationService
+	maintenanceService  *service.MaintenanceService
+	escalationService   *service.EscalationService
+	routingService      *service.RoutingService
+	authService         *service.AuthService
+	notifierRepo        *repository.NotifierRepository
+	notifiers           *notifier.Registry
 }
 
 // NewRouter 创建路由管理器
 func NewRouter(
 	probeService *service.ProbeService,
 	alertService *service.AlertService,
+	notificationService *service.NotificationService,
+	maintenanceService *service.MaintenanceService,
+	escalationService *service.EscalationService,
+	routingService *service.RoutingService,
 	authService *service.AuthService,
 	notifierRepo *repository.NotifierRepository,
+	notifiers *notifier.Registry,
 ) *Router {
 	return &Router{
-		probeService: probeService,
-		alertService: alertService,
-		authService:  authService,
-		notifierRepo: notifierRepo,
+		probeService:        probeService,
+		alertService:        alertService,
+		notificationService: notificationService,
+		maintenanceService:  maintenanceService,
+		escalationService:   escalationService,
+		routingService:      routingService,
+		authService:         authService,
+		notifierRepo:        notifierRepo,
+		notifiers:           notifiers,
 	}
 }
 
@@ -44,9 +60,12 @@ func (r *Router) Setup(mode string) *gin.Engine {
 
 	// 创建处理器
 	probeHandler := handler.NewProbeHandler(r.probeService)
-	alertHandler := handler.NewAlertHandler(r.alertService)
+	alertHandler := handler.NewAlertHandler(r.alertService, r.notificationService)
 	dashboardHandler := handler.NewDashboardHandler(r.probeService, r.alertService)
-	notifierHandler := handler.NewNotifierHandler(r.notifierRepo)
+	notifierHandler := handler.NewNotifierHandler(r.notifierRepo, r.notifiers)
+	maintenanceHandler := handler.NewMaintenanceHandler(r.maintenanceService)
+	escalationHandler := handler.NewEscalationHandler(r.escalationService)
+	routingHandler := handler.NewRoutingHandler(r.routingService)
 	authHandler := handler.NewAuthHandler(r.authService)
 
 	// 健康检查
@@ -96,8 +115,47 @@ func (r *Router) Setup(mode string) *gin.Engine {
 			alerts := protected.Group("/alerts")
 			{
 				alerts.GET("", alertHandler.ListRecords)
+				alerts.GET("/silences", alertHandler.ListSilences)
+				alerts.POST("/silences", alertHandler.CreateSilence)
+				alerts.POST("/silences/:id/expire", alertHandler.ExpireSilence)
 				alerts.GET("/:id", alertHandler.GetRecord)
 				alerts.PUT("/:id/silence", alertHandler.SilenceAlert)
+				alerts.POST("/:id/ack", alertHandler.AckAlert)
+				alerts.POST("/:id/assign", alertHandler.AssignAlert)
+				alerts.GET("/:id/activities", alertHandler.ListActivities)
+				alerts.POST("/:id/comments", alertHandler.AddComment)
+				alerts.GET("/:id/deliveries", alertHandler.ListDeliveries)
+				alerts.POST("/:id/deliveries/:delivery_id/resend", alertHandler.ResendDelivery)
+			}
+
+			// 维护窗口
+			maintenance := protected.Group("/maintenance-windows")
+			{
+				maintenance.GET("", maintenanceHandler.List)
+				maintenance.POST("", maintenanceHandler.Create)
+				maintenance.GET("/:id", maintenanceHandler.Get)
+				maintenance.PUT("/:id", maintenanceHandler.Update)
+				maintenance.DELETE("/:id", maintenanceHandler.Delete)
+			}
+
+			// 升级策略
+			escalations := protected.Group("/escalation-policies")
+			{
+				escalations.GET("", escalationHandler.List)
+				escalations.POST("", escalationHandler.Create)
+				escalations.GET("/:id", escalationHandler.Get)
+				escalations.PUT("/:id", escalationHandler.Update)
+				escalations.DELETE("/:id", escalationHandler.Delete)
+			}
+
+			// 告警路由规则
+			routing := protected.Group("/routing-rules")
+			{
+				routing.GET("", routingHandler.List)
+				routing.POST("", routingHandler.Create)
+				routing.GET("/:id", routingHandler.Get)
+				routing.PUT("/:id", routingHandler.Update)
+				routing.DELETE("/:id", routingHandler.Delete)
 			}
 
 			// 仪表盘
@@ -114,6 +172,7 @@ func (r *Router) Setup(mode string) *gin.Engine {
 				notifiers.POST("", notifierHandler.Create)
 				notifiers.GET("/types", notifierHandler.GetTypes)
 				notifiers.POST("/test", notifierHandler.Test)
+				notifiers.POST("/preview", notifierHandler.Preview)
 				notifiers.GET("/:id", notifierHandler.Get)
 				notifiers.PUT("/:id", notifierHandler.Update)
 				notifiers.DELETE("/:id", notifierHandler.Delete)
@@ -10,18 +10,20 @@ import (
 
 // Config 应用配置
 type Config struct {
-	Server    ServerConfig    `mapstructure:"server"`
-	Database  DatabaseConfig  `mapstructure:"database"`
-	Scheduler SchedulerConfig `mapstructure:"scheduler"`
-	Log       LogConfig       `mapstructure:"log"`
-	Auth      AuthConfig      `mapstructure:"auth"`
+	Server       ServerConfig       `mapstructure:"server"`
+	Database     DatabaseConfig     `mapstructure:"database"`
+	Scheduler    SchedulerConfig    `mapstructure:"scheduler"`
+	Notification NotificationConfig `mapstructure:"notification"`
+	Log          LogConfig          `mapstructure:"log"`
+	Auth         AuthConfig         `mapstructure:"auth"`
 }
 
 // ServerConfig 服务器配置
 type ServerConfig struct {
-	Host string `mapstructure:"host"`
-	Port int    `mapstructure:"port"`
-	Mode string `mapstructure:"mode"` // debug, release
+	Host        string `mapstructure:"host"`
+	Port        int    `mapstructure:"port"`
+	Mode        string `mapstructure:"mode"`         // debug, release
+	ExternalURL string `mapstructure:"external_url"` // 对外访问地址，用于生成告警通知中的详情链接
 }
 
 // DatabaseConfig 数据库配置
@@ -38,9 +40,23 @@ type DatabaseConfig struct {
 
 // SchedulerConfig 调度配置
 type SchedulerConfig struct {
-	DefaultInterval     int `mapstructure:"default_interval"`      // 默认探测间隔（秒）
-	DefaultTimeout      int `mapstructure:"default_timeout"`       // 默认超时时间（秒）
-	ResultRetentionDays int `mapstructure:"result_retention_days"` // 探测结果保留天数
+	DefaultInterval     int     `mapstructure:"default_interval"`      // 默认探测间隔（秒）
+	DefaultTimeout      int     `mapstructure:"default_timeout"`       // 默认超时时间（秒）
+	ResultRetentionDays int     `mapstructure:"result_retention_days"` // 探测结果保留天数
+	FlapWindow          int     `mapstructure:"flap_window"`           // 抖动检测窗口（最近探测次数），0 表示关闭
+	FlapHighThreshold   float64 `mapstructure:"flap_high_threshold"`   // 进入抖动的状态变化率（百分比）
+	FlapLowThreshold    float64 `mapstructure:"flap_low_threshold"`    // 退出抖动的状态变化率（百分比）
+}
+
+// NotificationConfig 通知投递配置
+type NotificationConfig struct {
+	MaxAttempts        int `mapstructure:"max_attempts"`          // 每个渠道的最大投递次数（含首次发送）
+	RetryInterval      int `mapstructure:"retry_interval"`        // 首次重试间隔（秒），之后按指数退避
+	MaxRetryInterval   int `mapstructure:"max_retry_interval"`    // 最大重试间隔（秒）
+	GroupWait          int `mapstructure:"group_wait"`            // 告警分组首次发送前的等待时间（秒），0 表示不分组
+	GroupInterval      int `mapstructure:"group_interval"`        // 同一分组两次发送的最小间隔（秒）
+	RateLimitPerMinute int `mapstructure:"rate_limit_per_minute"` // 渠道默认的每分钟最大消息数，0 表示不限流
+	RateLimitQueue     int `mapstructure:"rate_limit_queue"`      // 渠道排队的通知超过该数量时合并为一条汇总通知
 }
 
 // LogConfig 日志配置
@@ -93,6 +109,7 @@ func setDefaults() {
 	viper.SetDefault("server.host", "0.0.0.0")
 	viper.SetDefault("server.port", 8088)
 	viper.SetDefault("server.mode", "release")
+	viper.SetDefault("server.external_url", "")
 
 	// Database
 	viper.SetDefault("database.driver", "sqlite")
@@ -108,6 +125,18 @@ func setDefaults() {
 	viper.SetDefault("scheduler.default_interval", 30)
 	viper.SetDefault("scheduler.default_timeout", 5)
 	viper.SetDefault("scheduler.result_retention_days", 30)
+	viper.SetDefault("scheduler.flap_window", 0)
+	viper.SetDefault("scheduler.flap_high_threshold", 50)
+	viper.SetDefault("scheduler.flap_low_threshold", 25)
+
+	// Notification
+	viper.SetDefault("notification.max_attempts", 5)
+	viper.SetDefault("notification.retry_interval", 30)
+	viper.SetDefault("notification.max_retry_interval", 1800)
+	viper.SetDefault("notification.group_wait", 0)
+	viper.SetDefault("notification.group_interval", 60)
+	viper.SetDefault("notification.rate_limit_per_minute", 0)
+	viper.SetDefault("notification.rate_limit_queue", 10)
 
 	// Log
 	viper.SetDefault("log.level", "info")
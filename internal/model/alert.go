@@ -1,29 +1,102 @@
 package model
 
 import (
+	"encoding/json"
 	"time"
+
+	"gorm.io/datatypes"
 )
 
 // AlertStatus 告警状态
 type AlertStatus string
 
 const (
-	AlertStatusFiring   AlertStatus = "firing"
-	AlertStatusResolved AlertStatus = "resolved"
+	AlertStatusFiring       AlertStatus = "firing"
+	AlertStatusAcknowledged AlertStatus = "acknowledged" // 已确认：仍未恢复，但不再重复通知
+	AlertStatusResolved     AlertStatus = "resolved"
+)
+
+// Severity 告警级别
+type Severity string
+
+const (
+	SeverityInfo     Severity = "info"     // 提示
+	SeverityWarning  Severity = "warning"  // 警告
+	SeverityCritical Severity = "critical" // 严重
 )
 
+// severityRanks 告警级别高低
+var severityRanks = map[Severity]int{
+	SeverityInfo:     1,
+	SeverityWarning:  2,
+	SeverityCritical: 3,
+}
+
+// Valid 是否为有效的告警级别
+func (s Severity) Valid() bool {
+	_, ok := severityRanks[s]
+	return ok
+}
+
+// Rank 告警级别高低，无效级别视为严重
+func (s Severity) Rank() int {
+	if rank, ok := severityRanks[s]; ok {
+		return rank
+	}
+	return severityRanks[SeverityCritical]
+}
+
+// Label 告警级别名称
+func (s Severity) Label() string {
+	switch s {
+	case SeverityInfo:
+		return "提示"
+	case SeverityWarning:
+		return "警告"
+	default:
+		return "严重"
+	}
+}
+
+// Lower 取两个告警级别中较低的一个
+func (s Severity) Lower(other Severity) Severity {
+	if other.Rank() < s.Rank() {
+		return other
+	}
+	return s
+}
+
+// Higher 取两个告警级别中较高的一个
+func (s Severity) Higher(other Severity) Severity {
+	if other.Rank() > s.Rank() {
+		return other
+	}
+	return s
+}
+
 // AlertRecord 告警记录
 type AlertRecord struct {
-	ID         uint64      `json:"id" gorm:"primaryKey"`
-	TargetID   uint64      `json:"target_id" gorm:"index;not null"`
-	TargetName string      `json:"target_name" gorm:"size:128"`
-	TargetType string      `json:"target_type" gorm:"size:32"`
-	Status     AlertStatus `json:"status" gorm:"size:16;not null;index"`
-	Message    string      `json:"message" gorm:"size:1024"`
-	LatencyMs  int64       `json:"latency_ms"`
-	FiredAt    time.Time   `json:"fired_at" gorm:"index"`
-	ResolvedAt *time.Time  `json:"resolved_at"`
-	Notified   bool        `json:"notified" gorm:"default:false"`
+	ID              uint64         `json:"id" gorm:"primaryKey"`
+	TargetID        uint64         `json:"target_id" gorm:"index;not null"`
+	TargetName      string         `json:"target_name" gorm:"size:128"`
+	TargetType      string         `json:"target_type" gorm:"size:32"`
+	Status          AlertStatus    `json:"status" gorm:"size:16;not null;index"`
+	Severity        Severity       `json:"severity" gorm:"size:16;default:'critical';index"` // 告警级别
+	Message         string         `json:"message" gorm:"size:1024"`
+	LatencyMs       int64          `json:"latency_ms"`
+	FiredAt         time.Time      `json:"fired_at" gorm:"index"`
+	ResolvedAt      *time.Time     `json:"resolved_at"`
+	Notified        bool           `json:"notified" gorm:"default:false"`
+	LastNotifiedAt  *time.Time     `json:"last_notified_at"`                    // 最近一次成功发送通知的时间
+	InMaintenance   bool           `json:"in_maintenance" gorm:"default:false"` // 是否在维护窗口内触发（维护期间不发送通知）
+	MaintenanceID   uint64         `json:"maintenance_id"`                      // 命中的维护窗口 ID
+	AcknowledgedBy  string         `json:"acknowledged_by" gorm:"size:64"`      // 确认人
+	AcknowledgedAt  *time.Time     `json:"acknowledged_at"`                     // 确认时间
+	AckComment      string         `json:"ack_comment" gorm:"size:512"`         // 确认备注
+	Assignee        string         `json:"assignee" gorm:"size:64;index"`       // 处理人
+	EscalationLevel int            `json:"escalation_level" gorm:"default:0"`   // 已到达的升级步骤数，仅在目标使用升级策略时有效
+	SuppressedBy    uint64         `json:"suppressed_by" gorm:"default:0"`      // 因上游目标故障被抑制时为上游目标 ID
+	ImpactedIDs     datatypes.JSON `json:"impacted_ids" gorm:"type:jsonb"`      // 作为根因告警时，已通知的受影响下游告警记录 ID 列表
 }
 
 // TableName 表名
@@ -31,18 +104,74 @@ func (AlertRecord) TableName() string {
 	return "alert_records"
 }
 
-// Alert 告警通知内容
+// GetImpactedIDs 解析已通知的受影响下游告警记录 ID 列表
+func (r *AlertRecord) GetImpactedIDs() []uint64 {
+	var ids []uint64
+	if len(r.ImpactedIDs) > 0 {
+		_ = json.Unmarshal(r.ImpactedIDs, &ids)
+	}
+	return ids
+}
+
+// IsUnresolved 告警是否未恢复（告警中或已确认）
+func (r *AlertRecord) IsUnresolved() bool {
+	return r.Status == AlertStatusFiring || r.Status == AlertStatusAcknowledged
+}
+
+// AlertActivityType 告警活动类型
+type AlertActivityType string
+
+const (
+	AlertActivityFired        AlertActivityType = "fired"        // 触发
+	AlertActivityNotified     AlertActivityType = "notified"     // 已通知
+	AlertActivityAcknowledged AlertActivityType = "acknowledged" // 已确认
+	AlertActivityAssigned     AlertActivityType = "assigned"     // 指派
+	AlertActivityEscalated    AlertActivityType = "escalated"    // 升级
+	AlertActivitySuppressed   AlertActivityType = "suppressed"   // 被上游依赖抑制
+	AlertActivityFlapping     AlertActivityType = "flapping"     // 进入抖动
+	AlertActivityComment      AlertActivityType = "comment"      // 评论
+	AlertActivityResolved     AlertActivityType = "resolved"     // 恢复
+)
+
+// AlertActivity 告警活动记录，构成告警的处理时间线
+type AlertActivity struct {
+	ID            uint64            `json:"id" gorm:"primaryKey"`
+	AlertRecordID uint64            `json:"alert_record_id" gorm:"index;not null"`
+	Type          AlertActivityType `json:"type" gorm:"size:16;not null"`
+	Actor         string            `json:"actor" gorm:"size:64"`     // 操作人，系统产生的活动为空
+	Content       string            `json:"content" gorm:"size:1024"` // 活动内容（评论、备注等）
+	CreatedAt     time.Time         `json:"created_at" gorm:"index"`
+}
+
+// TableName 表名
+func (AlertActivity) TableName() string {
+	return "alert_activities"
+}
+
+// Alert 告警通知内容（同时作为通知消息模板的数据）
 type Alert struct {
 	ID         uint64
 	TargetID   uint64
 	TargetName string
 	TargetType string
+	Group      string
 	Endpoint   string
 	Status     AlertStatus
+	Severity   Severity
 	Message    string
 	Latency    time.Duration
 	FiredAt    time.Time
 	ResolvedAt *time.Time
 	DetailURL  string
 	Metrics    map[string]any
+	Alerts     []*Alert // 分组通知中合并的各条告警，单条告警时为空
+	Summary    bool     // 是否为渠道限流排队的汇总通知，此时 Alerts 为汇总的各条告警
+}
+
+// Duration 故障时长：已恢复时为恢复时间与触发时间之差，未恢复时为至今的时长
+func (a Alert) Duration() time.Duration {
+	if a.ResolvedAt != nil {
+		return a.ResolvedAt.Sub(a.FiredAt)
+	}
+	return time.Since(a.FiredAt)
 }
@@ -0,0 +1,65 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/datatypes"
+)
+
+// DeliveryStatus 通知投递状态
+type DeliveryStatus string
+
+const (
+	DeliveryStatusPending    DeliveryStatus = "pending"    // 等待重试
+	DeliveryStatusQueued     DeliveryStatus = "queued"     // 因渠道限流排队等待发送
+	DeliveryStatusSending    DeliveryStatus = "sending"    // 发送中
+	DeliveryStatusSuccess    DeliveryStatus = "success"    // 发送成功
+	DeliveryStatusFailed     DeliveryStatus = "failed"     // 重试次数耗尽
+	DeliveryStatusSuperseded DeliveryStatus = "superseded" // 已被同一告警在同一渠道上更新的通知取代
+)
+
+// NotificationDelivery 通知投递（发件箱），每条告警通知对每个渠道对应一条记录
+// 合并通知（分组、限流汇总）为其中每条告警记录各写入一条投递，同一批次的投递共享 BatchID 与内容快照，一起发送与重试
+type NotificationDelivery struct {
+	ID            uint64                `json:"id" gorm:"primaryKey"`
+	AlertRecordID uint64                `json:"alert_record_id" gorm:"index"`                    // 告警记录 ID
+	TargetID      uint64                `json:"target_id" gorm:"index"`                          // 目标 ID
+	BatchID       uint64                `json:"batch_id" gorm:"index"`                           // 合并通知的批次 ID（批次中首条投递的 ID），单条通知为 0
+	ChannelID     uint64                `json:"channel_id" gorm:"index"`                         // 通知渠道 ID
+	ChannelName   string                `json:"channel_name" gorm:"size:128"`                    // 通知渠道名称（快照）
+	ChannelType   NotifyChannelType     `json:"channel_type" gorm:"size:32"`                     // 通知渠道类型（快照）
+	AlertStatus   AlertStatus           `json:"alert_status" gorm:"size:16"`                     // 通知类型：firing/resolved
+	Payload       datatypes.JSON        `json:"-" gorm:"type:jsonb"`                             // 告警内容快照，用于重试
+	Status        DeliveryStatus        `json:"status" gorm:"size:16;not null;index"`            // 投递状态
+	AttemptCount  int                   `json:"attempt_count" gorm:"default:0"`                  // 已尝试次数
+	MaxAttempts   int                   `json:"max_attempts" gorm:"default:1"`                   // 最大尝试次数
+	NextAttemptAt *time.Time            `json:"next_attempt_at" gorm:"index"`                    // 下次重试时间
+	LastError     string                `json:"last_error" gorm:"size:1024"`                     // 最近一次错误
+	DeliveredAt   *time.Time            `json:"delivered_at"`                                    // 发送成功时间
+	CreatedAt     time.Time             `json:"created_at" gorm:"autoCreateTime"`                // 创建时间
+	UpdatedAt     time.Time             `json:"updated_at" gorm:"autoUpdateTime"`                // 更新时间
+	Attempts      []NotificationAttempt `json:"attempts,omitempty" gorm:"foreignKey:DeliveryID"` // 投递尝试记录
+}
+
+// TableName 表名
+func (NotificationDelivery) TableName() string {
+	return "notification_deliveries"
+}
+
+// NotificationAttempt 通知投递尝试记录
+type NotificationAttempt struct {
+	ID          uint64    `json:"id" gorm:"primaryKey"`
+	DeliveryID  uint64    `json:"delivery_id" gorm:"index;not null"` // 投递 ID
+	Attempt     int       `json:"attempt"`                           // 第几次尝试
+	Success     bool      `json:"success"`                           // 是否成功
+	StatusCode  int       `json:"status_code"`                       // 渠道接口返回的 HTTP 状态码
+	Response    string    `json:"response" gorm:"type:text"`         // 渠道接口响应内容
+	Error       string    `json:"error" gorm:"size:1024"`            // 错误信息
+	LatencyMs   int64     `json:"latency_ms"`                        // 耗时
+	AttemptedAt time.Time `json:"attempted_at" gorm:"index"`         // 尝试时间
+}
+
+// TableName 表名
+func (NotificationAttempt) TableName() string {
+	return "notification_attempts"
+}
@@ -0,0 +1,101 @@
+package model
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"gorm.io/datatypes"
+)
+
+// EscalationPolicy 告警升级策略
+// 目标关联升级策略后不再使用 NotifyChannelIDs：告警触发后按步骤依次通知，
+// 告警在某一步骤的延迟到达时仍未恢复且未被确认，则升级到该步骤并通知其渠道。
+// 恢复通知发送到告警已到达的所有步骤的渠道；未到达任何步骤就恢复的告警没有渠道收到过，不发送恢复通知
+type EscalationPolicy struct {
+	ID          uint64         `json:"id" gorm:"primaryKey"`
+	Name        string         `json:"name" gorm:"size:128;not null"`
+	Steps       datatypes.JSON `json:"steps" gorm:"type:jsonb"` // 升级步骤列表，见 EscalationStep
+	Description string         `json:"description" gorm:"size:512"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+}
+
+// TableName 表名
+func (EscalationPolicy) TableName() string {
+	return "escalation_policies"
+}
+
+// EscalationStep 升级步骤
+type EscalationStep struct {
+	DelayMinutes int      `json:"delay_minutes"` // 距离告警触发的延迟（分钟），0 表示触发时立即通知
+	ChannelIDs   []uint64 `json:"channel_ids"`   // 该步骤通知的渠道 ID 列表
+}
+
+// ParseSteps 解析升级步骤
+func (p *EscalationPolicy) ParseSteps() ([]EscalationStep, error) {
+	var steps []EscalationStep
+	if len(p.Steps) == 0 {
+		return steps, nil
+	}
+	if err := json.Unmarshal(p.Steps, &steps); err != nil {
+		return nil, fmt.Errorf("解析升级步骤失败: %w", err)
+	}
+	return steps, nil
+}
+
+// Validate 验证升级策略
+func (p *EscalationPolicy) Validate() error {
+	if p.Name == "" {
+		return fmt.Errorf("升级策略名称不能为空")
+	}
+	steps, err := p.ParseSteps()
+	if err != nil {
+		return err
+	}
+	if len(steps) == 0 {
+		return fmt.Errorf("升级策略至少需要一个步骤")
+	}
+	for i, step := range steps {
+		if step.DelayMinutes < 0 {
+			return fmt.Errorf("第 %d 步的延迟不能为负数", i+1)
+		}
+		if i > 0 && step.DelayMinutes <= steps[i-1].DelayMinutes {
+			return fmt.Errorf("第 %d 步的延迟必须大于上一步", i+1)
+		}
+		if len(step.ChannelIDs) == 0 {
+			return fmt.Errorf("第 %d 步至少需要一个通知渠道", i+1)
+		}
+	}
+	return nil
+}
+
+// ReachedLevel 计算告警持续 elapsed 后应到达的升级级别（已到达的步骤数）
+func ReachedLevel(steps []EscalationStep, elapsed time.Duration) int {
+	level := 0
+	for _, step := range steps {
+		if elapsed < time.Duration(step.DelayMinutes)*time.Minute {
+			break
+		}
+		level++
+	}
+	return level
+}
+
+// StepChannelIDs 汇总指定步骤区间 [from, to) 的通知渠道 ID（去重）
+func StepChannelIDs(steps []EscalationStep, from, to int) []uint64 {
+	if to > len(steps) {
+		to = len(steps)
+	}
+	seen := make(map[uint64]bool)
+	var ids []uint64
+	for i := from; i < to; i++ {
+		for _, id := range steps[i].ChannelIDs {
+			if !seen[id] {
+				seen[id] = true
+				ids = append(ids, id)
+			}
+		}
+	}
+	return ids
+}
@@ -0,0 +1,90 @@
+package model
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"gorm.io/datatypes"
+)
+
+func TestEscalationPolicyValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		steps   string
+		wantErr bool
+	}{
+		{"合法步骤", `[{"delay_minutes": 0, "channel_ids": [1]}, {"delay_minutes": 10, "channel_ids": [2]}]`, false},
+		{"没有步骤", `[]`, true},
+		{"延迟为负数", `[{"delay_minutes": -1, "channel_ids": [1]}]`, true},
+		{"延迟未递增", `[{"delay_minutes": 10, "channel_ids": [1]}, {"delay_minutes": 10, "channel_ids": [2]}]`, true},
+		{"步骤没有渠道", `[{"delay_minutes": 0, "channel_ids": []}]`, true},
+		{"步骤格式错误", `{"delay_minutes": 0}`, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &EscalationPolicy{Name: "oncall", Steps: datatypes.JSON(tt.steps)}
+			if err := p.Validate(); (err != nil) != tt.wantErr {
+				t.Fatalf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestReachedLevel(t *testing.T) {
+	steps := []EscalationStep{
+		{DelayMinutes: 0, ChannelIDs: []uint64{1}},
+		{DelayMinutes: 10, ChannelIDs: []uint64{2}},
+		{DelayMinutes: 30, ChannelIDs: []uint64{3}},
+	}
+	tests := []struct {
+		elapsed time.Duration
+		want    int
+	}{
+		{0, 1},
+		{10*time.Minute - time.Second, 1},
+		{10 * time.Minute, 2},
+		{29 * time.Minute, 2},
+		{30 * time.Minute, 3},
+		{24 * time.Hour, 3},
+	}
+	for _, tt := range tests {
+		if got := ReachedLevel(steps, tt.elapsed); got != tt.want {
+			t.Errorf("ReachedLevel(%s) = %d, want %d", tt.elapsed, got, tt.want)
+		}
+	}
+
+	delayed := []EscalationStep{{DelayMinutes: 5, ChannelIDs: []uint64{1}}}
+	if got := ReachedLevel(delayed, time.Minute); got != 0 {
+		t.Fatalf("第一步有延迟时未到达前应为 0: %d", got)
+	}
+	if got := ReachedLevel(nil, time.Hour); got != 0 {
+		t.Fatalf("没有步骤时应为 0: %d", got)
+	}
+}
+
+func TestStepChannelIDs(t *testing.T) {
+	steps := []EscalationStep{
+		{DelayMinutes: 0, ChannelIDs: []uint64{1, 2}},
+		{DelayMinutes: 10, ChannelIDs: []uint64{2, 3}},
+		{DelayMinutes: 30, ChannelIDs: []uint64{4}},
+	}
+	tests := []struct {
+		name     string
+		from, to int
+		want     []uint64
+	}{
+		{"第一步", 0, 1, []uint64{1, 2}},
+		{"前两步去重", 0, 2, []uint64{1, 2, 3}},
+		{"新到达的步骤", 1, 3, []uint64{2, 3, 4}},
+		{"超出步骤数", 2, 5, []uint64{4}},
+		{"未到达任何步骤", 0, 0, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := StepChannelIDs(steps, tt.from, tt.to); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("StepChannelIDs(%d, %d) = %v, want %v", tt.from, tt.to, got, tt.want)
+			}
+		})
+	}
+}
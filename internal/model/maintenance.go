@@ -0,0 +1,179 @@
+package model
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	"gorm.io/gorm"
+)
+
+// MaintenanceWindow 维护窗口
+// 维护期间照常探测并记录结果，但不发送告警通知，产生的告警记录会标记为维护中。
+// 未设置 Cron 时为一次性窗口，生效区间为 [StartsAt, EndsAt)；
+// 设置 Cron 时为周期窗口，每次按 Cron 触发后持续 DurationMinutes 分钟，StartsAt/EndsAt 可选，用于限定周期窗口的有效期
+type MaintenanceWindow struct {
+	ID   uint64 `json:"id" gorm:"primaryKey"`
+	Name string `json:"name" gorm:"size:128;not null"`
+	TargetMatcher
+	Cron            string     `json:"cron" gorm:"size:64"`              // 周期窗口的 cron 表达式（分 时 日 月 周），为空表示一次性窗口
+	DurationMinutes int        `json:"duration_minutes"`                 // 周期窗口每次持续时长（分钟）
+	Timezone        string     `json:"timezone" gorm:"size:64"`          // cron 表达式使用的时区，为空使用服务器本地时区
+	StartsAt        *time.Time `json:"starts_at"`                        // 开始时间
+	EndsAt          *time.Time `json:"ends_at"`                          // 结束时间
+	Enabled         bool       `json:"enabled" gorm:"default:true"`      // 是否启用
+	CreatedBy       string     `json:"created_by" gorm:"size:64"`        // 创建人
+	Comment         string     `json:"comment" gorm:"size:512"`          // 备注
+	CreatedAt       time.Time  `json:"created_at" gorm:"autoCreateTime"` // 创建时间
+	UpdatedAt       time.Time  `json:"updated_at" gorm:"autoUpdateTime"` // 更新时间
+
+	cronSpec     string        // 已解析的 cron 表达式（含时区），用于识别加载后修改过的窗口
+	cronSchedule cron.Schedule // 解析后的 Cron，在验证或从数据库加载时生成
+}
+
+// TableName 表名
+func (MaintenanceWindow) TableName() string {
+	return "maintenance_windows"
+}
+
+// AfterFind 从数据库加载后编译名称正则表达式并解析 cron 表达式
+// 加载时不返回错误，cron 表达式无效的周期窗口视为不生效
+func (w *MaintenanceWindow) AfterFind(tx *gorm.DB) error {
+	w.TargetMatcher.AfterFind(tx)
+	w.cronSpec, w.cronSchedule = "", nil
+	if w.IsRecurring() {
+		if schedule, err := w.parseSchedule(); err == nil {
+			w.cronSpec, w.cronSchedule = w.spec(), schedule
+		}
+	}
+	return nil
+}
+
+// IsRecurring 是否为周期窗口
+func (w *MaintenanceWindow) IsRecurring() bool {
+	return w.Cron != ""
+}
+
+// Validate 验证维护窗口
+func (w *MaintenanceWindow) Validate() error {
+	if w.Name == "" {
+		return fmt.Errorf("维护窗口名称不能为空")
+	}
+	if err := w.TargetMatcher.Validate(); err != nil {
+		return err
+	}
+	if w.StartsAt != nil && w.EndsAt != nil && !w.EndsAt.After(*w.StartsAt) {
+		return fmt.Errorf("结束时间必须晚于开始时间")
+	}
+
+	if !w.IsRecurring() {
+		if w.StartsAt == nil || w.EndsAt == nil {
+			return fmt.Errorf("一次性维护窗口必须指定开始时间和结束时间")
+		}
+		return nil
+	}
+
+	if w.DurationMinutes <= 0 {
+		return fmt.Errorf("周期维护窗口的持续时长必须大于 0")
+	}
+	schedule, err := w.parseSchedule()
+	if err != nil {
+		return err
+	}
+	w.cronSpec, w.cronSchedule = w.spec(), schedule
+	return nil
+}
+
+// ActiveAt 检查维护窗口在指定时间是否生效
+func (w *MaintenanceWindow) ActiveAt(now time.Time) bool {
+	if !w.Enabled {
+		return false
+	}
+	if w.StartsAt != nil && now.Before(*w.StartsAt) {
+		return false
+	}
+	if w.EndsAt != nil && !now.Before(*w.EndsAt) {
+		return false
+	}
+	if !w.IsRecurring() {
+		return true
+	}
+
+	schedule, err := w.schedule()
+	if err != nil {
+		return false
+	}
+	// 若在 (now-duration, now] 内存在一次触发，则当前仍处于该次维护中
+	duration := time.Duration(w.DurationMinutes) * time.Minute
+	return !schedule.Next(now.Add(-duration)).After(now)
+}
+
+// NextStart 获取指定时间之后下一次进入维护的时间，不会再进入维护时返回 nil
+func (w *MaintenanceWindow) NextStart(now time.Time) *time.Time {
+	if !w.Enabled {
+		return nil
+	}
+	if w.EndsAt != nil && !now.Before(*w.EndsAt) {
+		return nil
+	}
+
+	if !w.IsRecurring() {
+		if w.StartsAt != nil && now.Before(*w.StartsAt) {
+			return w.StartsAt
+		}
+		return nil
+	}
+
+	schedule, err := w.schedule()
+	if err != nil {
+		return nil
+	}
+	from := now
+	if w.StartsAt != nil && from.Before(*w.StartsAt) {
+		// Next 返回严格晚于 from 的触发时间，回退 1 秒以包含恰好在开始时间触发的情况
+		from = w.StartsAt.Add(-time.Second)
+	}
+	next := schedule.Next(from)
+	if next.IsZero() || (w.EndsAt != nil && !next.Before(*w.EndsAt)) {
+		return nil
+	}
+	return &next
+}
+
+// schedule 获取解析后的 cron 表达式
+// 未经验证或加载后修改过的窗口临时解析，不回写以免并发检查时产生数据竞争
+func (w *MaintenanceWindow) schedule() (cron.Schedule, error) {
+	if w.cronSchedule != nil && w.cronSpec == w.spec() {
+		return w.cronSchedule, nil
+	}
+	return w.parseSchedule()
+}
+
+// spec 带时区前缀的 cron 表达式
+func (w *MaintenanceWindow) spec() string {
+	if w.Timezone == "" {
+		return w.Cron
+	}
+	return "CRON_TZ=" + w.Timezone + " " + w.Cron
+}
+
+// parseSchedule 解析 cron 表达式（含时区）
+func (w *MaintenanceWindow) parseSchedule() (cron.Schedule, error) {
+	if w.Timezone != "" {
+		if _, err := time.LoadLocation(w.Timezone); err != nil {
+			return nil, fmt.Errorf("时区无效: %w", err)
+		}
+	}
+	schedule, err := cron.ParseStandard(w.spec())
+	if err != nil {
+		return nil, fmt.Errorf("cron 表达式无效: %w", err)
+	}
+	return schedule, nil
+}
+
+// MaintenanceWindowView 带当前状态的维护窗口，用于接口返回
+type MaintenanceWindowView struct {
+	*MaintenanceWindow
+	Active      bool       `json:"active"`        // 当前是否处于维护中
+	NextStartAt *time.Time `json:"next_start_at"` // 下一次进入维护的时间
+}
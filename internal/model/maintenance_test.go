@@ -0,0 +1,140 @@
+package model
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMaintenanceWindowParsesCronOnce(t *testing.T) {
+	w := &MaintenanceWindow{Name: "nightly", TargetMatcher: TargetMatcher{Group: "tb"}, Cron: "0 23 * * *", DurationMinutes: 120, Timezone: "UTC", Enabled: true}
+	if err := w.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if w.cronSchedule == nil {
+		t.Fatal("验证后应缓存解析后的 cron 表达式")
+	}
+
+	loaded := &MaintenanceWindow{Cron: "0 23 * * *", DurationMinutes: 120, Timezone: "UTC", Enabled: true}
+	if err := loaded.AfterFind(nil); err != nil {
+		t.Fatal(err)
+	}
+	if loaded.cronSchedule == nil {
+		t.Fatal("加载后应缓存解析后的 cron 表达式")
+	}
+
+	// 加载后修改过的表达式按新的表达式检查
+	loaded.Cron = "0 3 * * *"
+	if !loaded.ActiveAt(time.Date(2024, 5, 1, 3, 30, 0, 0, time.UTC)) {
+		t.Fatal("修改后的 cron 表达式未生效")
+	}
+
+	invalid := &MaintenanceWindow{Cron: "not a cron", DurationMinutes: 60, Enabled: true}
+	if err := invalid.AfterFind(nil); err != nil {
+		t.Fatalf("加载时不应返回错误: %v", err)
+	}
+	if invalid.ActiveAt(time.Now()) || invalid.NextStart(time.Now()) != nil {
+		t.Fatal("cron 表达式无效的窗口不应生效")
+	}
+}
+
+func TestMaintenanceWindowValidate(t *testing.T) {
+	start := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	end := start.Add(time.Hour)
+	tests := []struct {
+		name    string
+		window  MaintenanceWindow
+		wantErr bool
+	}{
+		{"一次性窗口", MaintenanceWindow{Name: "w", TargetMatcher: TargetMatcher{Group: "tb"}, StartsAt: &start, EndsAt: &end}, false},
+		{"一次性窗口缺少结束时间", MaintenanceWindow{Name: "w", TargetMatcher: TargetMatcher{Group: "tb"}, StartsAt: &start}, true},
+		{"结束时间早于开始时间", MaintenanceWindow{Name: "w", TargetMatcher: TargetMatcher{Group: "tb"}, StartsAt: &end, EndsAt: &start}, true},
+		{"周期窗口", MaintenanceWindow{Name: "w", TargetMatcher: TargetMatcher{Group: "tb"}, Cron: "0 2 * * 6", DurationMinutes: 60}, false},
+		{"周期窗口缺少持续时长", MaintenanceWindow{Name: "w", TargetMatcher: TargetMatcher{Group: "tb"}, Cron: "0 2 * * 6"}, true},
+		{"cron 表达式无效", MaintenanceWindow{Name: "w", TargetMatcher: TargetMatcher{Group: "tb"}, Cron: "0 25 * * *", DurationMinutes: 60}, true},
+		{"时区无效", MaintenanceWindow{Name: "w", TargetMatcher: TargetMatcher{Group: "tb"}, Cron: "0 2 * * *", DurationMinutes: 60, Timezone: "Mars/Olympus"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.window.Validate(); (err != nil) != tt.wantErr {
+				t.Fatalf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestMaintenanceWindowCrossesMidnight(t *testing.T) {
+	// 每天 23:00 开始持续 2 小时，跨越零点到次日 01:00
+	w := &MaintenanceWindow{Name: "nightly", TargetMatcher: TargetMatcher{Group: "tb"}, Cron: "0 23 * * *", DurationMinutes: 120, Timezone: "UTC", Enabled: true}
+	if err := w.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		at   time.Time
+		want bool
+	}{
+		{time.Date(2024, 5, 1, 22, 59, 59, 0, time.UTC), false},
+		{time.Date(2024, 5, 1, 23, 0, 0, 0, time.UTC), true},
+		{time.Date(2024, 5, 1, 23, 59, 0, 0, time.UTC), true},
+		{time.Date(2024, 5, 2, 0, 30, 0, 0, time.UTC), true},
+		{time.Date(2024, 5, 2, 0, 59, 59, 0, time.UTC), true},
+		{time.Date(2024, 5, 2, 1, 0, 0, 0, time.UTC), false},
+		// 跨月
+		{time.Date(2024, 6, 1, 0, 30, 0, 0, time.UTC), true},
+	}
+	for _, tt := range tests {
+		if got := w.ActiveAt(tt.at); got != tt.want {
+			t.Errorf("ActiveAt(%s) = %v, want %v", tt.at, got, tt.want)
+		}
+	}
+
+	next := w.NextStart(time.Date(2024, 5, 2, 0, 30, 0, 0, time.UTC))
+	if want := time.Date(2024, 5, 2, 23, 0, 0, 0, time.UTC); next == nil || !next.Equal(want) {
+		t.Fatalf("NextStart = %v, want %s", next, want)
+	}
+
+	// 有效期结束后不再生效，跨越结束时间的那次维护也在结束时间截止
+	endsAt := time.Date(2024, 5, 2, 0, 0, 0, 0, time.UTC)
+	w.EndsAt = &endsAt
+	if w.ActiveAt(time.Date(2024, 5, 2, 0, 30, 0, 0, time.UTC)) {
+		t.Fatal("超过结束时间后不应处于维护中")
+	}
+	if next := w.NextStart(time.Date(2024, 5, 1, 23, 30, 0, 0, time.UTC)); next != nil {
+		t.Fatalf("结束时间之后的触发不应返回: %v", next)
+	}
+
+	// 开始时间之前不生效，下一次维护从开始时间之后的首次触发算起
+	startsAt := time.Date(2024, 5, 10, 23, 0, 0, 0, time.UTC)
+	w.StartsAt, w.EndsAt = &startsAt, nil
+	if w.ActiveAt(time.Date(2024, 5, 2, 0, 30, 0, 0, time.UTC)) {
+		t.Fatal("开始时间之前不应处于维护中")
+	}
+	if next := w.NextStart(time.Date(2024, 5, 2, 0, 30, 0, 0, time.UTC)); next == nil || !next.Equal(startsAt) {
+		t.Fatalf("恰好在开始时间触发时应返回开始时间: %v", next)
+	}
+}
+
+func TestMaintenanceWindowTimezone(t *testing.T) {
+	// 上海时间每天 02:00，即 UTC 前一天 18:00
+	w := &MaintenanceWindow{Name: "cst", TargetMatcher: TargetMatcher{Group: "tb"}, Cron: "0 2 * * *", DurationMinutes: 60, Timezone: "Asia/Shanghai", Enabled: true}
+	if err := w.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	if !w.ActiveAt(time.Date(2024, 5, 1, 18, 30, 0, 0, time.UTC)) {
+		t.Fatal("应按窗口时区的 02:00 生效")
+	}
+	if w.ActiveAt(time.Date(2024, 5, 1, 2, 30, 0, 0, time.UTC)) {
+		t.Fatal("不应按 UTC 的 02:00 生效")
+	}
+	next := w.NextStart(time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC))
+	if want := time.Date(2024, 5, 1, 18, 0, 0, 0, time.UTC); next == nil || !next.Equal(want) {
+		t.Fatalf("NextStart = %v, want %s", next, want)
+	}
+
+	// 修改时区后按新的时区检查
+	w.Timezone = "UTC"
+	if !w.ActiveAt(time.Date(2024, 5, 1, 2, 30, 0, 0, time.UTC)) {
+		t.Fatal("修改后的时区未生效")
+	}
+}
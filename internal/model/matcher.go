@@ -0,0 +1,75 @@
+package model
+
+import (
+	"fmt"
+	"regexp"
+
+	"gorm.io/gorm"
+)
+
+// TargetMatcher 目标匹配条件
+// 各条件之间为“且”关系，未设置的条件不参与匹配
+type TargetMatcher struct {
+	TargetID    uint64 `json:"target_id" gorm:"index"`       // 匹配目标 ID，0 表示不限
+	Group       string `json:"group" gorm:"size:64"`         // 匹配分组，空表示不限
+	TargetType  string `json:"target_type" gorm:"size:32"`   // 匹配探针类型，空表示不限
+	NamePattern string `json:"name_pattern" gorm:"size:256"` // 匹配目标名称的正则表达式，空表示不限
+
+	nameRegexp *regexp.Regexp // 编译后的 NamePattern，在验证或从数据库加载时生成
+}
+
+// Validate 验证匹配条件并编译名称正则表达式
+func (m *TargetMatcher) Validate() error {
+	if m.TargetID == 0 && m.Group == "" && m.TargetType == "" && m.NamePattern == "" {
+		return fmt.Errorf("至少需要指定一个匹配条件")
+	}
+	return m.compile()
+}
+
+// AfterFind 从数据库加载后编译名称正则表达式
+// 加载时不返回错误，正则无效的规则在匹配时视为不匹配
+func (m *TargetMatcher) AfterFind(*gorm.DB) error {
+	m.compile()
+	return nil
+}
+
+// compile 编译名称正则表达式
+func (m *TargetMatcher) compile() error {
+	m.nameRegexp = nil
+	if m.NamePattern == "" {
+		return nil
+	}
+	re, err := regexp.Compile(m.NamePattern)
+	if err != nil {
+		return fmt.Errorf("名称正则表达式无效: %w", err)
+	}
+	m.nameRegexp = re
+	return nil
+}
+
+// Matches 检查目标是否满足匹配条件
+func (m *TargetMatcher) Matches(target *ProbeTarget) bool {
+	if m.TargetID != 0 && m.TargetID != target.ID {
+		return false
+	}
+	if m.Group != "" && m.Group != target.Group {
+		return false
+	}
+	if m.TargetType != "" && m.TargetType != target.Type {
+		return false
+	}
+	if m.NamePattern != "" {
+		re := m.nameRegexp
+		if re == nil || re.String() != m.NamePattern {
+			// 未经验证或加载后修改过的条件临时编译，不回写以免并发匹配时产生数据竞争
+			var err error
+			if re, err = regexp.Compile(m.NamePattern); err != nil {
+				return false
+			}
+		}
+		if !re.MatchString(target.Name) {
+			return false
+		}
+	}
+	return true
+}
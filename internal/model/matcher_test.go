@@ -0,0 +1,41 @@
+package model
+
+import "testing"
+
+func TestTargetMatcherNamePattern(t *testing.T) {
+	target := &ProbeTarget{ID: 7, Name: "redis-cache-01", Group: "cache", Type: "redis"}
+
+	m := TargetMatcher{Group: "cache", NamePattern: `^redis-cache-\d+$`}
+	if err := m.Validate(); err != nil {
+		t.Fatalf("Validate 返回错误: %v", err)
+	}
+	if m.nameRegexp == nil {
+		t.Fatal("Validate 后应已编译名称正则表达式")
+	}
+	if !m.Matches(target) {
+		t.Fatal("应匹配目标名称")
+	}
+
+	// 从数据库加载的条件在 AfterFind 中编译
+	loaded := Silence{TargetMatcher: TargetMatcher{NamePattern: `^mysql-`}}
+	if err := loaded.AfterFind(nil); err != nil {
+		t.Fatal(err)
+	}
+	if loaded.nameRegexp == nil || loaded.Matches(target) {
+		t.Fatal("加载后应编译正则且不匹配其他名称")
+	}
+
+	// 编译后修改的条件按新的表达式匹配
+	loaded.NamePattern = `^redis-`
+	if !loaded.Matches(target) {
+		t.Fatal("修改后的名称正则表达式应生效")
+	}
+
+	invalid := TargetMatcher{NamePattern: `redis-(`}
+	if err := invalid.Validate(); err == nil {
+		t.Fatal("无效的名称正则表达式应返回错误")
+	}
+	if err := invalid.AfterFind(nil); err != nil || invalid.Matches(target) {
+		t.Fatal("无效的名称正则表达式加载时不报错且不匹配任何目标")
+	}
+}
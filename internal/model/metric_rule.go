@@ -0,0 +1,95 @@
+package model
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+)
+
+// MetricRule 指标阈值规则
+// 表达式格式为 “指标 比较符 阈值 [for N checks]”，如 avg_rtt_ms > 200、packet_loss >= 25、latency_ms > 1500 for 3 checks。
+// 指标取自探测结果的 Metrics（支持以 . 访问嵌套字段），latency_ms 为探测耗时；
+// 条件连续满足 N 次（默认 1 次）后：严重级别的规则将本次探测判定为失败并立即告警（不再叠加目标的连续失败阈值），
+// 其余级别的规则作为警告将本次探测判定为降级并按规则的级别告警（不受目标的 AlertOnDegraded 影响）
+type MetricRule struct {
+	Expr     string   `json:"expr"`     // 规则表达式
+	Severity Severity `json:"severity"` // 告警级别，默认 warning
+}
+
+// MetricCondition 解析后的指标阈值条件
+type MetricCondition struct {
+	Metric    string  // 指标名称
+	Op        string  // 比较符：>, >=, <, <=, ==, !=
+	Threshold float64 // 阈值
+	For       int     // 需连续满足的次数
+}
+
+// metricExprPattern 指标阈值表达式
+var metricExprPattern = regexp.MustCompile(`^\s*([A-Za-z_][\w.]*)\s*(>=|<=|==|!=|>|<)\s*(-?\d+(?:\.\d+)?)\s*(?:for\s+(\d+)\s*(?:checks?)?)?\s*$`)
+
+// Parse 解析规则表达式
+func (r MetricRule) Parse() (*MetricCondition, error) {
+	m := metricExprPattern.FindStringSubmatch(r.Expr)
+	if m == nil {
+		return nil, fmt.Errorf("指标规则表达式无效: %q，格式应为“指标 比较符 阈值 [for N checks]”", r.Expr)
+	}
+	threshold, err := strconv.ParseFloat(m[3], 64)
+	if err != nil {
+		return nil, fmt.Errorf("指标规则阈值无效: %w", err)
+	}
+	cond := &MetricCondition{Metric: m[1], Op: m[2], Threshold: threshold, For: 1}
+	if m[4] != "" {
+		cond.For, _ = strconv.Atoi(m[4])
+		if cond.For < 1 {
+			return nil, fmt.Errorf("指标规则的连续次数必须大于 0: %q", r.Expr)
+		}
+	}
+	return cond, nil
+}
+
+// GetSeverity 获取规则的告警级别，未设置时为警告
+func (r MetricRule) GetSeverity() Severity {
+	if r.Severity == "" {
+		return SeverityWarning
+	}
+	return r.Severity
+}
+
+// Validate 验证指标阈值规则
+func (r MetricRule) Validate() error {
+	if r.Severity != "" && !r.Severity.Valid() {
+		return fmt.Errorf("告警级别无效: %s", r.Severity)
+	}
+	_, err := r.Parse()
+	return err
+}
+
+// Eval 检查指标值是否满足条件
+func (c *MetricCondition) Eval(value float64) bool {
+	switch c.Op {
+	case ">":
+		return value > c.Threshold
+	case ">=":
+		return value >= c.Threshold
+	case "<":
+		return value < c.Threshold
+	case "<=":
+		return value <= c.Threshold
+	case "==":
+		return value == c.Threshold
+	case "!=":
+		return value != c.Threshold
+	default:
+		return false
+	}
+}
+
+// Describe 描述条件的触发情况，包含当前指标值
+func (c *MetricCondition) Describe(value float64) string {
+	desc := fmt.Sprintf("%s 当前值 %s %s %s", c.Metric,
+		strconv.FormatFloat(value, 'f', -1, 64), c.Op, strconv.FormatFloat(c.Threshold, 'f', -1, 64))
+	if c.For > 1 {
+		desc += fmt.Sprintf("（连续 %d 次）", c.For)
+	}
+	return desc
+}
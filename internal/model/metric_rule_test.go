@@ -0,0 +1,85 @@
+package model
+
+import "testing"
+
+func TestMetricRuleParse(t *testing.T) {
+	tests := []struct {
+		expr      string
+		metric    string
+		op        string
+		threshold float64
+		count     int
+		wantErr   bool
+	}{
+		{expr: "avg_rtt_ms > 200", metric: "avg_rtt_ms", op: ">", threshold: 200, count: 1},
+		{expr: "packet_loss >= 25", metric: "packet_loss", op: ">=", threshold: 25, count: 1},
+		{expr: "free_pct<=10.5", metric: "free_pct", op: "<=", threshold: 10.5, count: 1},
+		{expr: "replication.lag_s != -1", metric: "replication.lag_s", op: "!=", threshold: -1, count: 1},
+		{expr: "latency_ms > 1500 for 3 checks", metric: "latency_ms", op: ">", threshold: 1500, count: 3},
+		{expr: "  brokers.online < 3 for 1 check ", metric: "brokers.online", op: "<", threshold: 3, count: 1},
+		{expr: "leader == 0 for 2", metric: "leader", op: "==", threshold: 0, count: 2},
+		{expr: "latency_ms > 1500 for 0 checks", wantErr: true},
+		{expr: "latency_ms => 1500", wantErr: true},
+		{expr: "latency_ms > fast", wantErr: true},
+		{expr: "1abc > 1", wantErr: true},
+		{expr: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		cond, err := MetricRule{Expr: tt.expr}.Parse()
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("Parse(%q) 应返回错误，实际为 %+v", tt.expr, cond)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Parse(%q) 返回错误: %v", tt.expr, err)
+			continue
+		}
+		if cond.Metric != tt.metric || cond.Op != tt.op || cond.Threshold != tt.threshold || cond.For != tt.count {
+			t.Errorf("Parse(%q) = %+v", tt.expr, cond)
+		}
+	}
+}
+
+func TestMetricConditionEval(t *testing.T) {
+	tests := []struct {
+		op    string
+		value float64
+		want  bool
+	}{
+		{op: ">", value: 11, want: true},
+		{op: ">", value: 10, want: false},
+		{op: ">=", value: 10, want: true},
+		{op: ">=", value: 9.9, want: false},
+		{op: "<", value: 9, want: true},
+		{op: "<", value: 10, want: false},
+		{op: "<=", value: 10, want: true},
+		{op: "<=", value: 10.1, want: false},
+		{op: "==", value: 10, want: true},
+		{op: "==", value: 11, want: false},
+		{op: "!=", value: 11, want: true},
+		{op: "!=", value: 10, want: false},
+		{op: "~", value: 10, want: false},
+	}
+
+	for _, tt := range tests {
+		cond := &MetricCondition{Metric: "m", Op: tt.op, Threshold: 10, For: 1}
+		if got := cond.Eval(tt.value); got != tt.want {
+			t.Errorf("%v %s 10 = %v, want %v", tt.value, tt.op, got, tt.want)
+		}
+	}
+}
+
+func TestMetricRuleValidate(t *testing.T) {
+	if err := (MetricRule{Expr: "latency_ms > 100"}).Validate(); err != nil {
+		t.Fatalf("未设置级别的规则应通过校验: %v", err)
+	}
+	if got := (MetricRule{Expr: "latency_ms > 100"}).GetSeverity(); got != SeverityWarning {
+		t.Fatalf("未设置级别时应为警告，实际为 %s", got)
+	}
+	if err := (MetricRule{Expr: "latency_ms > 100", Severity: "fatal"}).Validate(); err == nil {
+		t.Fatal("无效的告警级别应校验失败")
+	}
+}
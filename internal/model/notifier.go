@@ -2,27 +2,39 @@ package model
 
 import (
 	"time"
+
+	"gorm.io/datatypes"
 )
 
 // NotifyChannelType 通知渠道类型
 type NotifyChannelType string
 
 const (
-	NotifyChannelTypeWeCom NotifyChannelType = "wecom" // 企业微信
+	NotifyChannelTypeWeCom    NotifyChannelType = "wecom"    // 企业微信
+	NotifyChannelTypeDingTalk NotifyChannelType = "dingtalk" // 钉钉
+	NotifyChannelTypeFeishu   NotifyChannelType = "feishu"   // 飞书/Lark
+	NotifyChannelTypeWebhook  NotifyChannelType = "webhook"  // 通用 Webhook
+	NotifyChannelTypeEmail    NotifyChannelType = "email"    // 邮件（SMTP）
+	NotifyChannelTypeSlack    NotifyChannelType = "slack"    // Slack
+	NotifyChannelTypeTelegram NotifyChannelType = "telegram" // Telegram
 )
 
 // NotifyChannel 通知渠道
 type NotifyChannel struct {
 	ID          uint64            `json:"id" gorm:"primaryKey"`
-	Name        string            `json:"name" gorm:"size:128;not null"`        // 渠道名称
-	Type        NotifyChannelType `json:"type" gorm:"size:32;not null;index"`   // 渠道类型
-	WebhookURL  string            `json:"webhook_url" gorm:"size:512;not null"` // Webhook URL
-	MessageTpl  string            `json:"message_tpl" gorm:"type:text"`         // 消息模板
-	MentionAll  bool              `json:"mention_all" gorm:"default:true"`      // 是否@所有人
-	Enabled     bool              `json:"enabled" gorm:"default:true;index"`    // 是否启用
-	Description string            `json:"description" gorm:"size:256"`          // 描述
-	CreatedAt   time.Time         `json:"created_at" gorm:"autoCreateTime"`     // 创建时间
-	UpdatedAt   time.Time         `json:"updated_at" gorm:"autoUpdateTime"`     // 更新时间
+	Name        string            `json:"name" gorm:"size:128;not null"`      // 渠道名称
+	Type        NotifyChannelType `json:"type" gorm:"size:32;not null;index"` // 渠道类型
+	WebhookURL  string            `json:"webhook_url" gorm:"size:512"`        // Webhook URL（非 Webhook 类渠道为空）
+	Secret      string            `json:"-" gorm:"size:256"`                  // 签名密钥（钉钉加签、飞书签名校验、Webhook 签名），只写不返回
+	HasSecret   bool              `json:"has_secret" gorm:"-"`                // 是否已设置签名密钥
+	MessageTpl  string            `json:"message_tpl" gorm:"type:text"`       // 消息模板
+	MentionAll  bool              `json:"mention_all" gorm:"default:true"`    // 是否@所有人
+	AtMobiles   string            `json:"at_mobiles" gorm:"size:512"`         // @指定手机号，逗号分隔（钉钉）
+	Config      datatypes.JSON    `json:"config" gorm:"type:jsonb"`           // 渠道类型专属配置（如 Webhook 请求模板、SMTP 服务器）
+	Enabled     bool              `json:"enabled" gorm:"default:true;index"`  // 是否启用
+	Description string            `json:"description" gorm:"size:256"`        // 描述
+	CreatedAt   time.Time         `json:"created_at" gorm:"autoCreateTime"`   // 创建时间
+	UpdatedAt   time.Time         `json:"updated_at" gorm:"autoUpdateTime"`   // 更新时间
 }
 
 // TableName 表名
@@ -31,15 +43,33 @@ func (NotifyChannel) TableName() string {
 }
 
 // DefaultFiringMessageTemplate 默认告警触发消息模板
+// 模板使用 text/template 语法，数据为 Alert，可用函数：formatTime、formatDuration、upper、lower、json、default
 const DefaultFiringMessageTemplate = `🚨 告警通知
 
+级别：{{.Severity.Label}}
 目标：{{.TargetName}}
+类型：{{.TargetType}}
+{{- if .Group}}
+分组：{{.Group}}{{end}}
+{{- if .Endpoint}}
+地址：{{.Endpoint}}{{end}}
 原因：{{.Message}}
-时间：{{.FiredAt}}`
+时间：{{formatTime .FiredAt}}
+{{- if .DetailURL}}
+详情：{{.DetailURL}}{{end}}`
 
 // DefaultResolvedMessageTemplate 默认告警恢复消息模板
 const DefaultResolvedMessageTemplate = `✅ 恢复通知
 
 目标：{{.TargetName}}
-恢复时间：{{.ResolvedAt}}
-故障时长：{{.Duration}}`
+类型：{{.TargetType}}
+{{- if .Group}}
+分组：{{.Group}}{{end}}
+时间：{{formatTime .FiredAt}}
+{{- if .ResolvedAt}}
+恢复时间：{{formatTime .ResolvedAt}}
+故障时长：{{formatDuration .Duration}}{{end}}
+{{- if .Alerts}}
+{{.Message}}{{end}}
+{{- if .DetailURL}}
+详情：{{.DetailURL}}{{end}}`
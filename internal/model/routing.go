@@ -0,0 +1,91 @@
+package model
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"gorm.io/datatypes"
+)
+
+// RoutingRule 告警路由规则
+// 按告警级别、分组、探针类型等条件为告警选择通知渠道，命中规则的渠道替换目标自身配置的通知渠道，
+// 未命中任何规则时仍使用目标的通知渠道。
+// 规则按 Priority 从小到大依次匹配，命中后默认停止匹配，设置 Continue 时继续匹配后续规则并合并其渠道。
+// 使用升级策略的目标不参与路由，由升级策略决定通知渠道（策略无法加载时按上述规则回退）
+type RoutingRule struct {
+	ID       uint64 `json:"id" gorm:"primaryKey"`
+	Name     string `json:"name" gorm:"size:128;not null"`
+	Priority int    `json:"priority" gorm:"default:0;index"` // 优先级，越小越先匹配
+	TargetMatcher
+	Severities  datatypes.JSON `json:"severities" gorm:"type:jsonb"`     // 匹配的告警级别列表，空表示不限
+	ChannelIDs  datatypes.JSON `json:"channel_ids" gorm:"type:jsonb"`    // 命中后通知的渠道 ID 列表
+	Continue    bool           `json:"continue" gorm:"default:false"`    // 命中后是否继续匹配后续规则
+	Enabled     bool           `json:"enabled" gorm:"default:true"`      // 是否启用
+	Description string         `json:"description" gorm:"size:512"`      // 描述
+	CreatedAt   time.Time      `json:"created_at" gorm:"autoCreateTime"` // 创建时间
+	UpdatedAt   time.Time      `json:"updated_at" gorm:"autoUpdateTime"` // 更新时间
+}
+
+// TableName 表名
+func (RoutingRule) TableName() string {
+	return "routing_rules"
+}
+
+// GetSeverities 解析匹配的告警级别列表
+func (r *RoutingRule) GetSeverities() []Severity {
+	var severities []Severity
+	if len(r.Severities) > 0 {
+		_ = json.Unmarshal(r.Severities, &severities)
+	}
+	return severities
+}
+
+// GetChannelIDs 解析通知渠道 ID 列表
+func (r *RoutingRule) GetChannelIDs() []uint64 {
+	var ids []uint64
+	if len(r.ChannelIDs) > 0 {
+		_ = json.Unmarshal(r.ChannelIDs, &ids)
+	}
+	return ids
+}
+
+// Validate 验证路由规则
+func (r *RoutingRule) Validate() error {
+	if r.Name == "" {
+		return fmt.Errorf("路由规则名称不能为空")
+	}
+	severities := r.GetSeverities()
+	if r.TargetID == 0 && r.Group == "" && r.TargetType == "" && r.NamePattern == "" && len(severities) == 0 {
+		return fmt.Errorf("至少需要指定一个匹配条件")
+	}
+	for _, severity := range severities {
+		if !severity.Valid() {
+			return fmt.Errorf("告警级别无效: %s", severity)
+		}
+	}
+	if err := r.TargetMatcher.compile(); err != nil {
+		return err
+	}
+	if len(r.GetChannelIDs()) == 0 {
+		return fmt.Errorf("路由规则至少需要一个通知渠道")
+	}
+	return nil
+}
+
+// Matches 检查告警是否命中路由规则
+func (r *RoutingRule) Matches(target *ProbeTarget, severity Severity) bool {
+	if !r.TargetMatcher.Matches(target) {
+		return false
+	}
+	severities := r.GetSeverities()
+	if len(severities) == 0 {
+		return true
+	}
+	for _, s := range severities {
+		if s == severity {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,36 @@
+package model
+
+import (
+	"testing"
+
+	"gorm.io/datatypes"
+)
+
+func TestRoutingRuleMatches(t *testing.T) {
+	target := &ProbeTarget{ID: 7, Name: "redis-cache-01", Group: "cache", Type: "redis"}
+
+	tests := []struct {
+		name     string
+		rule     RoutingRule
+		severity Severity
+		want     bool
+	}{
+		{"未指定级别时匹配任意级别", RoutingRule{TargetMatcher: TargetMatcher{Group: "cache"}}, SeverityInfo, true},
+		{"命中级别列表", RoutingRule{Severities: datatypes.JSON(`["warning","critical"]`)}, SeverityCritical, true},
+		{"不在级别列表中", RoutingRule{Severities: datatypes.JSON(`["warning","critical"]`)}, SeverityInfo, false},
+		{"目标条件与级别同时满足", RoutingRule{TargetMatcher: TargetMatcher{TargetType: "redis"}, Severities: datatypes.JSON(`["critical"]`)}, SeverityCritical, true},
+		{"级别满足但目标条件不满足", RoutingRule{TargetMatcher: TargetMatcher{TargetType: "kafka"}, Severities: datatypes.JSON(`["critical"]`)}, SeverityCritical, false},
+		{"目标条件满足但级别不满足", RoutingRule{TargetMatcher: TargetMatcher{Group: "cache"}, Severities: datatypes.JSON(`["critical"]`)}, SeverityWarning, false},
+		{"按名称匹配", RoutingRule{TargetMatcher: TargetMatcher{NamePattern: `^redis-`}}, SeverityWarning, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.rule.TargetMatcher.compile(); err != nil {
+				t.Fatal(err)
+			}
+			if got := tt.rule.Matches(target, tt.severity); got != tt.want {
+				t.Fatalf("Matches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
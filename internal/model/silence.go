@@ -0,0 +1,58 @@
+package model
+
+import (
+	"fmt"
+	"time"
+)
+
+// Silence 告警静默规则
+type Silence struct {
+	ID uint64 `json:"id" gorm:"primaryKey"`
+	TargetMatcher
+	StartsAt  time.Time `json:"starts_at" gorm:"index"`           // 开始时间
+	EndsAt    time.Time `json:"ends_at" gorm:"index"`             // 结束时间
+	CreatedBy string    `json:"created_by" gorm:"size:64"`        // 创建人
+	Comment   string    `json:"comment" gorm:"size:512"`          // 备注
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"` // 创建时间
+	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime"` // 更新时间
+}
+
+// TableName 表名
+func (Silence) TableName() string {
+	return "silences"
+}
+
+// 静默状态
+const (
+	SilenceStatePending = "pending" // 未开始
+	SilenceStateActive  = "active"  // 生效中
+	SilenceStateExpired = "expired" // 已过期
+)
+
+// State 获取静默在指定时间的状态
+func (s *Silence) State(now time.Time) string {
+	if now.Before(s.StartsAt) {
+		return SilenceStatePending
+	}
+	if !now.Before(s.EndsAt) {
+		return SilenceStateExpired
+	}
+	return SilenceStateActive
+}
+
+// Validate 验证静默规则
+func (s *Silence) Validate() error {
+	if err := s.TargetMatcher.Validate(); err != nil {
+		return err
+	}
+	if !s.EndsAt.After(s.StartsAt) {
+		return fmt.Errorf("结束时间必须晚于开始时间")
+	}
+	return nil
+}
+
+// SilenceView 带状态的静默规则，用于接口返回
+type SilenceView struct {
+	*Silence
+	State string `json:"state"`
+}
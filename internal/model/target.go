@@ -1,6 +1,7 @@
 package model
 
 import (
+	"encoding/json"
 	"time"
 
 	"gorm.io/datatypes"
@@ -9,28 +10,38 @@ import (
 // ProbeTarget 状态常量
 const (
 	TargetStatusHealthy   = "healthy"   // 健康
+	TargetStatusDegraded  = "degraded"  // 降级（服务可用但存在警告）
 	TargetStatusUnhealthy = "unhealthy" // 不健康
+	TargetStatusFlapping  = "flapping"  // 抖动（状态频繁变化）
 	TargetStatusUnknown   = "unknown"   // 未知（未探测或等待首次探测）
 	TargetStatusDisabled  = "disabled"  // 已禁用
 )
 
 // ProbeTarget 探测目标
 type ProbeTarget struct {
-	ID               uint64         `json:"id" gorm:"primaryKey"`
-	Name             string         `json:"name" gorm:"size:128;not null"`
-	Type             string         `json:"type" gorm:"size:32;not null;index"`
-	Config           datatypes.JSON `json:"config" gorm:"type:jsonb"`
-	TimeoutSeconds   int            `json:"timeout_seconds" gorm:"default:5"`
-	IntervalSeconds  int            `json:"interval_seconds" gorm:"default:30"`
-	Enabled          bool           `json:"enabled" gorm:"default:true;index"`
-	Status           string         `json:"status" gorm:"size:16;default:'unknown'"` // healthy, unhealthy, unknown, disabled
-	LastCheckAt      *time.Time     `json:"last_check_at"`
-	LastLatencyMs    int64          `json:"last_latency_ms"`
-	LastMessage      string         `json:"last_message" gorm:"size:512"`
-	Group            string         `json:"group" gorm:"size:64;index"`           // 分组
-	NotifyChannelIDs datatypes.JSON `json:"notify_channel_ids" gorm:"type:jsonb"` // 通知渠道ID列表
-	CreatedAt        time.Time      `json:"created_at"`
-	UpdatedAt        time.Time      `json:"updated_at"`
+	ID                    uint64         `json:"id" gorm:"primaryKey"`
+	Name                  string         `json:"name" gorm:"size:128;not null"`
+	Type                  string         `json:"type" gorm:"size:32;not null;index"`
+	Config                datatypes.JSON `json:"config" gorm:"type:jsonb"`
+	TimeoutSeconds        int            `json:"timeout_seconds" gorm:"default:5"`
+	IntervalSeconds       int            `json:"interval_seconds" gorm:"default:30"`
+	Enabled               bool           `json:"enabled" gorm:"default:true;index"`
+	Status                string         `json:"status" gorm:"size:16;default:'unknown'"` // healthy, degraded, unhealthy, flapping, unknown, disabled
+	LastCheckAt           *time.Time     `json:"last_check_at"`
+	LastLatencyMs         int64          `json:"last_latency_ms"`
+	LastMessage           string         `json:"last_message" gorm:"size:512"`
+	Group                 string         `json:"group" gorm:"size:64;index"`                  // 分组
+	NotifyChannelIDs      datatypes.JSON `json:"notify_channel_ids" gorm:"type:jsonb"`        // 通知渠道ID列表
+	FailureThreshold      int            `json:"failure_threshold" gorm:"default:1"`          // 连续失败 N 次后触发告警
+	RecoveryThreshold     int            `json:"recovery_threshold" gorm:"default:1"`         // 连续成功 M 次后恢复告警
+	RepeatIntervalSeconds int            `json:"repeat_interval_seconds" gorm:"default:0"`    // 重复通知最小间隔（秒），0 表示每次失败都通知
+	EscalationPolicyID    uint64         `json:"escalation_policy_id" gorm:"default:0;index"` // 升级策略 ID，设置后代替通知渠道ID列表，0 表示不使用
+	ParentIDs             datatypes.JSON `json:"parent_ids" gorm:"type:jsonb"`                // 依赖的上游目标ID列表，上游故障时本目标的告警被抑制
+	Severity              Severity       `json:"severity" gorm:"size:16;default:'critical'"`  // 探测失败时的告警级别，探针报告的非致命问题可降低该级别
+	AlertOnDegraded       bool           `json:"alert_on_degraded" gorm:"default:false"`      // 探针报告降级时是否按警告级别告警（指标规则始终告警）
+	MetricRules           datatypes.JSON `json:"metric_rules" gorm:"type:jsonb"`              // 指标阈值规则列表，见 MetricRule
+	CreatedAt             time.Time      `json:"created_at"`
+	UpdatedAt             time.Time      `json:"updated_at"`
 }
 
 // TableName 表名
@@ -38,11 +49,38 @@ func (ProbeTarget) TableName() string {
 	return "probe_targets"
 }
 
+// GetMetricRules 解析指标阈值规则列表
+func (t *ProbeTarget) GetMetricRules() []MetricRule {
+	var rules []MetricRule
+	if len(t.MetricRules) > 0 {
+		_ = json.Unmarshal(t.MetricRules, &rules)
+	}
+	return rules
+}
+
+// GetSeverity 获取目标的告警级别，未设置时为严重
+func (t *ProbeTarget) GetSeverity() Severity {
+	if t.Severity.Valid() {
+		return t.Severity
+	}
+	return SeverityCritical
+}
+
+// GetParentIDs 解析依赖的上游目标ID列表
+func (t *ProbeTarget) GetParentIDs() []uint64 {
+	var ids []uint64
+	if len(t.ParentIDs) > 0 {
+		_ = json.Unmarshal(t.ParentIDs, &ids)
+	}
+	return ids
+}
+
 // ProbeResult 探测结果
 type ProbeResult struct {
 	ID        uint64         `json:"id" gorm:"primaryKey"`
 	TargetID  uint64         `json:"target_id" gorm:"index;not null"`
 	Success   bool           `json:"success"`
+	Outcome   string         `json:"outcome" gorm:"size:16"` // 探测结论：ok, degraded, down
 	LatencyMs int64          `json:"latency_ms"`
 	Message   string         `json:"message" gorm:"size:512"`
 	Metrics   datatypes.JSON `json:"metrics" gorm:"type:jsonb"`
@@ -57,25 +95,41 @@ func (ProbeResult) TableName() string {
 
 // CreateTargetRequest 创建目标请求
 type CreateTargetRequest struct {
-	Name             string         `json:"name" binding:"required"`
-	Type             string         `json:"type" binding:"required"`
-	Config           map[string]any `json:"config" binding:"required"`
-	TimeoutSeconds   int            `json:"timeout_seconds"`
-	IntervalSeconds  int            `json:"interval_seconds"`
-	Enabled          bool           `json:"enabled"`
-	Group            string         `json:"group"`              // 分组
-	NotifyChannelIDs []uint64       `json:"notify_channel_ids"` // 通知渠道ID列表
+	Name                  string         `json:"name" binding:"required"`
+	Type                  string         `json:"type" binding:"required"`
+	Config                map[string]any `json:"config" binding:"required"`
+	TimeoutSeconds        int            `json:"timeout_seconds"`
+	IntervalSeconds       int            `json:"interval_seconds"`
+	Enabled               bool           `json:"enabled"`
+	Group                 string         `json:"group"`                   // 分组
+	NotifyChannelIDs      []uint64       `json:"notify_channel_ids"`      // 通知渠道ID列表
+	FailureThreshold      int            `json:"failure_threshold"`       // 连续失败 N 次后触发告警，默认 1
+	RecoveryThreshold     int            `json:"recovery_threshold"`      // 连续成功 M 次后恢复告警，默认 1
+	RepeatIntervalSeconds int            `json:"repeat_interval_seconds"` // 重复通知最小间隔（秒），默认 0
+	EscalationPolicyID    uint64         `json:"escalation_policy_id"`    // 升级策略 ID，默认不使用
+	ParentIDs             []uint64       `json:"parent_ids"`              // 依赖的上游目标ID列表
+	Severity              Severity       `json:"severity"`                // 告警级别，默认 critical
+	AlertOnDegraded       bool           `json:"alert_on_degraded"`       // 探针报告降级时是否按警告级别告警（指标规则始终告警）
+	MetricRules           []MetricRule   `json:"metric_rules"`            // 指标阈值规则列表
 }
 
 // UpdateTargetRequest 更新目标请求
 type UpdateTargetRequest struct {
-	Name             string         `json:"name"`
-	Config           map[string]any `json:"config"`
-	TimeoutSeconds   int            `json:"timeout_seconds"`
-	IntervalSeconds  int            `json:"interval_seconds"`
-	Enabled          *bool          `json:"enabled"`
-	Group            *string        `json:"group"`              // 分组
-	NotifyChannelIDs *[]uint64      `json:"notify_channel_ids"` // 通知渠道ID列表
+	Name                  string         `json:"name"`
+	Config                map[string]any `json:"config"`
+	TimeoutSeconds        int            `json:"timeout_seconds"`
+	IntervalSeconds       int            `json:"interval_seconds"`
+	Enabled               *bool          `json:"enabled"`
+	Group                 *string        `json:"group"`                   // 分组
+	NotifyChannelIDs      *[]uint64      `json:"notify_channel_ids"`      // 通知渠道ID列表
+	FailureThreshold      *int           `json:"failure_threshold"`       // 连续失败 N 次后触发告警
+	RecoveryThreshold     *int           `json:"recovery_threshold"`      // 连续成功 M 次后恢复告警
+	RepeatIntervalSeconds *int           `json:"repeat_interval_seconds"` // 重复通知最小间隔（秒）
+	EscalationPolicyID    *uint64        `json:"escalation_policy_id"`    // 升级策略 ID，0 表示取消
+	ParentIDs             *[]uint64      `json:"parent_ids"`              // 依赖的上游目标ID列表
+	Severity              Severity       `json:"severity"`                // 告警级别，为空表示不修改
+	AlertOnDegraded       *bool          `json:"alert_on_degraded"`       // 探针报告降级时是否按警告级别告警（指标规则始终告警）
+	MetricRules           *[]MetricRule  `json:"metric_rules"`            // 指标阈值规则列表
 }
 
 // TestTargetRequest 测试目标请求
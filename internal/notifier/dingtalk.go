@@ -0,0 +1,173 @@
+package notifier
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/thingsboard-rxprobe/internal/model"
+	"github.com/thingsboard-rxprobe/internal/prober"
+)
+
+// dingTalkRateLimit 群机器人每分钟最多发送 20 条消息
+const dingTalkRateLimit = 20
+
+// DingTalkNotifier 钉钉通知
+type DingTalkNotifier struct{}
+
+// NewDingTalkNotifier 创建钉钉通知
+func NewDingTalkNotifier() *DingTalkNotifier {
+	return &DingTalkNotifier{}
+}
+
+// Type 返回渠道类型
+func (n *DingTalkNotifier) Type() model.NotifyChannelType {
+	return model.NotifyChannelTypeDingTalk
+}
+
+// Name 返回渠道显示名称
+func (n *DingTalkNotifier) Name() string {
+	return "钉钉"
+}
+
+// Description 返回渠道描述
+func (n *DingTalkNotifier) Description() string {
+	return "通过钉钉群机器人发送 markdown 通知，支持加签与 @指定手机号"
+}
+
+// RateLimit 返回默认的每分钟最大消息数
+func (n *DingTalkNotifier) RateLimit() int {
+	return dingTalkRateLimit
+}
+
+// ConfigSchema 返回配置表单 schema
+func (n *DingTalkNotifier) ConfigSchema() map[string]prober.FieldSchema {
+	return map[string]prober.FieldSchema{
+		"webhook_url": {
+			Type:        "string",
+			Label:       "Webhook URL",
+			Required:    true,
+			Placeholder: "https://oapi.dingtalk.com/robot/send?access_token=xxx",
+			Hint:        "钉钉群机器人的 Webhook 地址",
+		},
+		"secret": {
+			Type:        "password",
+			Label:       "加签密钥",
+			Placeholder: "SECxxx",
+			Hint:        "机器人安全设置启用“加签”时填写",
+		},
+		"at_mobiles": {
+			Type:        "string",
+			Label:       "@手机号",
+			Placeholder: "13800000000,13900000000",
+			Hint:        "多个手机号以逗号分隔",
+		},
+		"rate_limit_per_minute": {
+			Type:         "number",
+			Label:        "每分钟最大消息数",
+			DefaultValue: dingTalkRateLimit,
+			Hint:         "钉钉群机器人每分钟最多发送 20 条消息，超出的通知排队发送",
+		},
+		"mention_all": {
+			Type:         "boolean",
+			Label:        "@所有人",
+			DefaultValue: true,
+			Hint:         "开启后消息将@群内所有成员",
+		},
+	}
+}
+
+// Send 发送到钉钉群机器人（markdown 消息）
+func (n *DingTalkNotifier) Send(ctx context.Context, channel *model.NotifyChannel, alert *model.Alert) (*Response, error) {
+	content := RenderMessage(channel, alert)
+
+	webhookURL, err := signDingTalkURL(channel.WebhookURL, channel.Secret, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	mobiles := SplitList(channel.AtMobiles)
+	text := markdownLines(content)
+	// 钉钉要求被 @ 的手机号出现在消息正文中才会高亮提醒
+	if len(mobiles) > 0 {
+		text += "\n\n@" + strings.Join(mobiles, " @")
+	}
+
+	msg := map[string]any{
+		"msgtype": "markdown",
+		"markdown": map[string]string{
+			"title": messageTitle(content),
+			"text":  text,
+		},
+		"at": map[string]any{
+			"atMobiles": mobiles,
+			"isAtAll":   channel.MentionAll,
+		},
+	}
+
+	resp, err := postJSON(ctx, webhookURL, msg)
+	if err != nil {
+		return resp, err
+	}
+
+	var result struct {
+		ErrCode int    `json:"errcode"`
+		ErrMsg  string `json:"errmsg"`
+	}
+	if err := json.Unmarshal([]byte(resp.Body), &result); err != nil {
+		return resp, fmt.Errorf("解析响应失败: %w", err)
+	}
+	if result.ErrCode != 0 {
+		return resp, fmt.Errorf("钉钉 API 错误: %d - %s", result.ErrCode, result.ErrMsg)
+	}
+	return resp, nil
+}
+
+// Validate 验证钉钉渠道配置
+func (n *DingTalkNotifier) Validate(channel *model.NotifyChannel) error {
+	if err := validateWebhookURL(channel.WebhookURL); err != nil {
+		return err
+	}
+	for _, mobile := range SplitList(channel.AtMobiles) {
+		if _, err := strconv.ParseUint(mobile, 10, 64); err != nil {
+			return fmt.Errorf("@手机号格式无效: %s", mobile)
+		}
+	}
+	return nil
+}
+
+// signDingTalkURL 为启用“加签”安全设置的钉钉机器人追加 timestamp 与 sign 参数
+// 签名算法：base64(HmacSHA256(secret, timestamp + "\n" + secret))
+func signDingTalkURL(webhookURL, secret string, now time.Time) (string, error) {
+	if secret == "" {
+		return webhookURL, nil
+	}
+
+	u, err := url.Parse(webhookURL)
+	if err != nil {
+		return "", fmt.Errorf("Webhook URL 格式无效: %w", err)
+	}
+
+	timestamp := strconv.FormatInt(now.UnixMilli(), 10)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "\n" + secret))
+	sign := base64.StdEncoding.EncodeToString(mac.Sum(nil))
+
+	query := u.Query()
+	query.Set("timestamp", timestamp)
+	query.Set("sign", sign)
+	u.RawQuery = query.Encode()
+	return u.String(), nil
+}
+
+// markdownLines 将纯文本换行转换为 markdown 换行
+func markdownLines(content string) string {
+	return strings.ReplaceAll(content, "\n", "  \n")
+}
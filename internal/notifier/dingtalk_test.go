@@ -0,0 +1,39 @@
+package notifier
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSignDingTalkURL(t *testing.T) {
+	// 签名由 base64(HmacSHA256("SECa1b2c3", "1700000000000\nSECa1b2c3")) 独立计算得到
+	const sign = "r3A3qrlgRa%2BTdR5aXyvO01zn0wmdf5B%2FXKtKbV42SDc%3D"
+	now := time.UnixMilli(1700000000000)
+
+	tests := []struct {
+		name   string
+		url    string
+		secret string
+		want   string
+	}{
+		{"未设置密钥时不签名", "https://oapi.dingtalk.com/robot/send?access_token=abc", "", "https://oapi.dingtalk.com/robot/send?access_token=abc"},
+		{"无查询参数", "https://oapi.dingtalk.com/robot/send", "SECa1b2c3", "https://oapi.dingtalk.com/robot/send?sign=" + sign + "&timestamp=1700000000000"},
+		{"保留已有的查询参数", "https://oapi.dingtalk.com/robot/send?access_token=abc", "SECa1b2c3", "https://oapi.dingtalk.com/robot/send?access_token=abc&sign=" + sign + "&timestamp=1700000000000"},
+		{"替换已有的签名参数", "https://oapi.dingtalk.com/robot/send?access_token=abc&timestamp=1&sign=old", "SECa1b2c3", "https://oapi.dingtalk.com/robot/send?access_token=abc&sign=" + sign + "&timestamp=1700000000000"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := signDingTalkURL(tt.url, tt.secret, now)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Fatalf("signDingTalkURL() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := signDingTalkURL("://invalid", "SECa1b2c3", now); err == nil {
+		t.Fatal("无效的 URL 应返回错误")
+	}
+}
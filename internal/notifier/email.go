@@ -0,0 +1,420 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"fmt"
+	"html/template"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/thingsboard-rxprobe/internal/model"
+	"github.com/thingsboard-rxprobe/internal/prober"
+)
+
+// SMTP 连接加密方式
+const (
+	SMTPSecurityNone     = "none"     // 明文
+	SMTPSecuritySTARTTLS = "starttls" // 明文连接后升级 TLS（常用端口 587）
+	SMTPSecurityTLS      = "tls"      // 隐式 TLS（常用端口 465）
+)
+
+// emailHTMLTemplate 邮件 HTML 正文模板
+var emailHTMLTemplate = template.Must(template.New("email").Parse(`<!DOCTYPE html>
+<html>
+<body style="font-family:-apple-system,'Segoe UI','PingFang SC','Microsoft YaHei',sans-serif;font-size:14px;color:#1f2937;">
+<div style="max-width:640px;border:1px solid #e5e7eb;border-radius:8px;overflow:hidden;">
+  <div style="background:{{.Color}};color:#ffffff;padding:12px 16px;font-size:16px;font-weight:600;">{{.Title}}</div>
+  <table style="width:100%;border-collapse:collapse;">
+    {{- range .Fields}}
+    <tr>
+      <td style="padding:8px 16px;width:96px;color:#6b7280;border-bottom:1px solid #f3f4f6;">{{.Label}}</td>
+      <td style="padding:8px 16px;border-bottom:1px solid #f3f4f6;">{{.Value}}</td>
+    </tr>
+    {{- end}}
+  </table>
+  <pre style="margin:0;padding:12px 16px;white-space:pre-wrap;font-family:inherit;">{{.Content}}</pre>
+  {{- if .DetailURL}}
+  <div style="padding:0 16px 16px;"><a href="{{.DetailURL}}" style="color:#2563eb;">查看详情</a></div>
+  {{- end}}
+</div>
+</body>
+</html>`))
+
+// emailField 邮件 HTML 正文中的字段
+type emailField struct {
+	Label string
+	Value string
+}
+
+// emailConfig 邮件渠道配置
+type emailConfig struct {
+	Host               string
+	Port               int
+	Security           string
+	Username           string
+	Password           string
+	From               string
+	To                 []string
+	InsecureSkipVerify bool
+	Timeout            time.Duration
+}
+
+// EmailNotifier 邮件通知
+type EmailNotifier struct{}
+
+// NewEmailNotifier 创建邮件通知
+func NewEmailNotifier() *EmailNotifier {
+	return &EmailNotifier{}
+}
+
+// Type 返回渠道类型
+func (n *EmailNotifier) Type() model.NotifyChannelType {
+	return model.NotifyChannelTypeEmail
+}
+
+// Name 返回渠道显示名称
+func (n *EmailNotifier) Name() string {
+	return "邮件"
+}
+
+// Description 返回渠道描述
+func (n *EmailNotifier) Description() string {
+	return "通过 SMTP 发送 HTML 邮件，支持 STARTTLS/TLS 与认证"
+}
+
+// ConfigSchema 返回配置表单 schema
+func (n *EmailNotifier) ConfigSchema() map[string]prober.FieldSchema {
+	return map[string]prober.FieldSchema{
+		"smtp_host": {
+			Type:        "string",
+			Label:       "SMTP 服务器",
+			Required:    true,
+			Placeholder: "smtp.example.com",
+		},
+		"smtp_port": {
+			Type:        "number",
+			Label:       "SMTP 端口",
+			Placeholder: "587",
+			Hint:        "为空时按加密方式使用 25/587/465",
+		},
+		"security": {
+			Type:         "select",
+			Label:        "加密方式",
+			DefaultValue: SMTPSecuritySTARTTLS,
+			Options: []prober.Option{
+				{Value: SMTPSecurityNone, Label: "无"},
+				{Value: SMTPSecuritySTARTTLS, Label: "STARTTLS"},
+				{Value: SMTPSecurityTLS, Label: "SSL/TLS"},
+			},
+		},
+		"username": {
+			Type:  "string",
+			Label: "用户名",
+			Hint:  "为空时不进行 SMTP 认证；认证需要 STARTTLS 或 SSL/TLS 加密",
+		},
+		"password": {
+			Type:  "password",
+			Label: "密码",
+		},
+		"from": {
+			Type:        "string",
+			Label:       "发件人",
+			Required:    true,
+			Placeholder: "RxProbe <rxprobe@example.com>",
+		},
+		"to": {
+			Type:        "textarea",
+			Label:       "收件人",
+			Required:    true,
+			Placeholder: "ops@example.com",
+			Hint:        "多个地址以逗号或换行分隔",
+		},
+		"insecure_skip_verify": {
+			Type:         "boolean",
+			Label:        "跳过证书验证",
+			DefaultValue: false,
+		},
+	}
+}
+
+// Send 通过 SMTP 发送邮件（HTML + 纯文本 multipart/alternative）
+//
+// 渠道配置（Config）：
+//   - smtp_host / smtp_port：SMTP 服务器地址与端口
+//   - security：none、starttls（默认）、tls
+//   - username / password：SMTP 认证信息，为空时不认证
+//   - from：发件人地址
+//   - to：收件人列表，逗号或换行分隔
+//   - insecure_skip_verify：跳过证书验证
+func (n *EmailNotifier) Send(ctx context.Context, channel *model.NotifyChannel, alert *model.Alert) (*Response, error) {
+	cfg := parseEmailConfig(channel)
+
+	content := RenderMessage(channel, alert)
+	subject := messageTitle(content)
+	if alert.TargetName != "" {
+		subject = fmt.Sprintf("%s - %s", subject, alert.TargetName)
+	}
+
+	htmlBody, err := renderEmailHTML(content, alert)
+	if err != nil {
+		return nil, err
+	}
+	msg, err := buildEmailMessage(cfg.From, cfg.To, subject, content, htmlBody)
+	if err != nil {
+		return nil, err
+	}
+
+	// SMTP 没有 HTTP 状态码，投递成功时以收件人列表作为响应记录
+	if err := sendSMTP(ctx, cfg, msg); err != nil {
+		return nil, err
+	}
+	return &Response{Body: "已投递至 " + strings.Join(cfg.To, ", ")}, nil
+}
+
+// Validate 验证邮件渠道配置
+func (n *EmailNotifier) Validate(channel *model.NotifyChannel) error {
+	cfg := parseEmailConfig(channel)
+
+	if cfg.Host == "" {
+		return fmt.Errorf("缺少必填字段: smtp_host")
+	}
+	if cfg.Port <= 0 || cfg.Port > 65535 {
+		return fmt.Errorf("SMTP 端口无效: %d", cfg.Port)
+	}
+	switch cfg.Security {
+	case SMTPSecurityNone, SMTPSecuritySTARTTLS, SMTPSecurityTLS:
+	default:
+		return fmt.Errorf("不支持的加密方式: %s", cfg.Security)
+	}
+	// PLAIN 认证会明文传输密码，net/smtp 仅允许在加密连接或本机连接上使用
+	if cfg.Security == SMTPSecurityNone && cfg.Username != "" && !isLocalhost(cfg.Host) {
+		return fmt.Errorf("加密方式为“无”时不能使用 SMTP 认证（密码将明文传输），请选择 STARTTLS 或 SSL/TLS，或清空用户名")
+	}
+	if cfg.From == "" {
+		return fmt.Errorf("缺少必填字段: from")
+	}
+	if _, err := mail.ParseAddress(cfg.From); err != nil {
+		return fmt.Errorf("发件人地址格式无效: %s", cfg.From)
+	}
+	if len(cfg.To) == 0 {
+		return fmt.Errorf("缺少必填字段: to")
+	}
+	for _, to := range cfg.To {
+		if _, err := mail.ParseAddress(to); err != nil {
+			return fmt.Errorf("收件人地址格式无效: %s", to)
+		}
+	}
+	return nil
+}
+
+// parseEmailConfig 解析邮件渠道配置
+func parseEmailConfig(channel *model.NotifyChannel) *emailConfig {
+	config := ChannelConfig(channel)
+
+	security := strings.ToLower(getStringConfig(config, "security", SMTPSecuritySTARTTLS))
+	defaultPort := 587
+	switch security {
+	case SMTPSecurityTLS:
+		defaultPort = 465
+	case SMTPSecurityNone:
+		defaultPort = 25
+	}
+
+	return &emailConfig{
+		Host:               getStringConfig(config, "smtp_host", ""),
+		Port:               getIntConfig(config, "smtp_port", defaultPort),
+		Security:           security,
+		Username:           getStringConfig(config, "username", ""),
+		Password:           getStringConfig(config, "password", ""),
+		From:               getStringConfig(config, "from", ""),
+		To:                 SplitList(getStringConfig(config, "to", "")),
+		InsecureSkipVerify: getBoolConfig(config, "insecure_skip_verify", false),
+		Timeout:            defaultTimeout,
+	}
+}
+
+// isLocalhost 是否为本机地址，与 smtp.PlainAuth 允许明文认证的主机一致
+func isLocalhost(host string) bool {
+	return host == "localhost" || host == "127.0.0.1" || host == "::1"
+}
+
+// sendSMTP 建立 SMTP 连接并投递邮件
+func sendSMTP(ctx context.Context, cfg *emailConfig, msg []byte) error {
+	addr := net.JoinHostPort(cfg.Host, strconv.Itoa(cfg.Port))
+	tlsConfig := &tls.Config{
+		ServerName:         cfg.Host,
+		InsecureSkipVerify: cfg.InsecureSkipVerify,
+	}
+
+	dialer := &net.Dialer{Timeout: cfg.Timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return fmt.Errorf("连接 SMTP 服务器失败: %w", err)
+	}
+	deadline := time.Now().Add(cfg.Timeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	_ = conn.SetDeadline(deadline)
+
+	if cfg.Security == SMTPSecurityTLS {
+		conn = tls.Client(conn, tlsConfig)
+	}
+
+	client, err := smtp.NewClient(conn, cfg.Host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("建立 SMTP 会话失败: %w", err)
+	}
+	defer client.Close()
+
+	if cfg.Security == SMTPSecuritySTARTTLS {
+		if ok, _ := client.Extension("STARTTLS"); !ok {
+			return fmt.Errorf("SMTP 服务器不支持 STARTTLS")
+		}
+		if err := client.StartTLS(tlsConfig); err != nil {
+			return fmt.Errorf("STARTTLS 失败: %w", err)
+		}
+	}
+
+	if cfg.Username != "" {
+		if ok, _ := client.Extension("AUTH"); !ok {
+			return fmt.Errorf("SMTP 服务器不支持认证")
+		}
+		if err := client.Auth(smtp.PlainAuth("", cfg.Username, cfg.Password, cfg.Host)); err != nil {
+			return fmt.Errorf("SMTP 认证失败: %w", err)
+		}
+	}
+
+	from, _ := mail.ParseAddress(cfg.From)
+	if err := client.Mail(from.Address); err != nil {
+		return fmt.Errorf("设置发件人失败: %w", err)
+	}
+	for _, to := range cfg.To {
+		rcpt, err := mail.ParseAddress(to)
+		if err != nil {
+			return fmt.Errorf("收件人地址格式无效: %s", to)
+		}
+		if err := client.Rcpt(rcpt.Address); err != nil {
+			return fmt.Errorf("设置收件人 %s 失败: %w", rcpt.Address, err)
+		}
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("发送邮件内容失败: %w", err)
+	}
+	if _, err := w.Write(msg); err != nil {
+		w.Close()
+		return fmt.Errorf("发送邮件内容失败: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("发送邮件内容失败: %w", err)
+	}
+
+	return client.Quit()
+}
+
+// renderEmailHTML 渲染邮件 HTML 正文
+func renderEmailHTML(content string, alert *model.Alert) (string, error) {
+	title := messageTitle(content)
+	color := "#dc2626"
+	if alert.Status == model.AlertStatusResolved {
+		color = "#16a34a"
+	}
+
+	fields := []emailField{
+		{Label: "目标", Value: alert.TargetName},
+		{Label: "类型", Value: alert.TargetType},
+	}
+	if alert.Group != "" {
+		fields = append(fields, emailField{Label: "分组", Value: alert.Group})
+	}
+	if alert.Endpoint != "" {
+		fields = append(fields, emailField{Label: "地址", Value: alert.Endpoint})
+	}
+	fields = append(fields, emailField{Label: "触发时间", Value: formatTime(alert.FiredAt)})
+	if alert.ResolvedAt != nil {
+		fields = append(fields,
+			emailField{Label: "恢复时间", Value: formatTime(alert.ResolvedAt)},
+			emailField{Label: "故障时长", Value: FormatDuration(alert.Duration())},
+		)
+	}
+
+	var buf bytes.Buffer
+	err := emailHTMLTemplate.Execute(&buf, map[string]any{
+		"Title":     title,
+		"Color":     color,
+		"Fields":    fields,
+		"Content":   content,
+		"DetailURL": alert.DetailURL,
+	})
+	if err != nil {
+		return "", fmt.Errorf("渲染邮件正文失败: %w", err)
+	}
+	return buf.String(), nil
+}
+
+// buildEmailMessage 构建 multipart/alternative 邮件
+func buildEmailMessage(from string, to []string, subject, textBody, htmlBody string) ([]byte, error) {
+	boundary, err := randomBoundary()
+	if err != nil {
+		return nil, err
+	}
+
+	if addr, err := mail.ParseAddress(from); err == nil {
+		from = addr.String()
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("From: " + from + "\r\n")
+	buf.WriteString("To: " + strings.Join(to, ", ") + "\r\n")
+	buf.WriteString("Subject: " + mime.BEncoding.Encode("UTF-8", subject) + "\r\n")
+	buf.WriteString("Date: " + time.Now().Format(time.RFC1123Z) + "\r\n")
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: multipart/alternative; boundary=\"" + boundary + "\"\r\n")
+	buf.WriteString("\r\n")
+
+	for _, part := range []struct {
+		contentType string
+		body        string
+	}{
+		{contentType: "text/plain; charset=UTF-8", body: textBody},
+		{contentType: "text/html; charset=UTF-8", body: htmlBody},
+	} {
+		buf.WriteString("--" + boundary + "\r\n")
+		buf.WriteString("Content-Type: " + part.contentType + "\r\n")
+		buf.WriteString("Content-Transfer-Encoding: quoted-printable\r\n")
+		buf.WriteString("\r\n")
+		qp := quotedprintable.NewWriter(&buf)
+		if _, err := qp.Write([]byte(part.body)); err != nil {
+			return nil, fmt.Errorf("编码邮件内容失败: %w", err)
+		}
+		if err := qp.Close(); err != nil {
+			return nil, fmt.Errorf("编码邮件内容失败: %w", err)
+		}
+		buf.WriteString("\r\n")
+	}
+	buf.WriteString("--" + boundary + "--\r\n")
+
+	return buf.Bytes(), nil
+}
+
+// randomBoundary 生成 multipart 分隔符
+func randomBoundary() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("生成邮件分隔符失败: %w", err)
+	}
+	return "rxprobe-" + hex.EncodeToString(b), nil
+}
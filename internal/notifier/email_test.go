@@ -0,0 +1,205 @@
+package notifier
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/textproto"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/thingsboard-rxprobe/internal/model"
+)
+
+// smtpSession 测试 SMTP 服务器记录的一次会话
+type smtpSession struct {
+	auth string   // AUTH PLAIN 解码后的凭据
+	from string   // MAIL FROM
+	rcpt []string // RCPT TO
+	data []byte   // DATA 内容
+}
+
+// newSMTPTestServer 启动只处理一次会话的本地 SMTP 服务器，会话结束后通过通道返回记录
+func newSMTPTestServer(t *testing.T) (string, <-chan *smtpSession) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	done := make(chan *smtpSession, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+		session := &smtpSession{}
+		defer func() { done <- session }()
+		tp := textproto.NewConn(conn)
+		tp.PrintfLine("220 localhost ESMTP test")
+		for {
+			line, err := tp.ReadLine()
+			if err != nil {
+				return
+			}
+			verb, arg, _ := strings.Cut(line, " ")
+			switch strings.ToUpper(verb) {
+			case "EHLO", "HELO":
+				tp.PrintfLine("250-localhost")
+				tp.PrintfLine("250 AUTH PLAIN")
+			case "AUTH":
+				_, encoded, _ := strings.Cut(arg, " ")
+				decoded, _ := base64.StdEncoding.DecodeString(encoded)
+				session.auth = string(decoded)
+				tp.PrintfLine("235 2.7.0 Authentication successful")
+			case "MAIL":
+				session.from = arg
+				tp.PrintfLine("250 OK")
+			case "RCPT":
+				session.rcpt = append(session.rcpt, arg)
+				tp.PrintfLine("250 OK")
+			case "DATA":
+				tp.PrintfLine("354 End data with <CR><LF>.<CR><LF>")
+				if session.data, err = tp.ReadDotBytes(); err != nil {
+					return
+				}
+				tp.PrintfLine("250 OK")
+			case "QUIT":
+				tp.PrintfLine("221 Bye")
+				return
+			default:
+				tp.PrintfLine("502 Command not implemented")
+			}
+		}
+	}()
+	return ln.Addr().String(), done
+}
+
+func emailChannel(t *testing.T, config map[string]any) *model.NotifyChannel {
+	t.Helper()
+	data, err := json.Marshal(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &model.NotifyChannel{Name: "邮件", Type: model.NotifyChannelTypeEmail, Config: data, Enabled: true}
+}
+
+func TestEmailSend(t *testing.T) {
+	addr, sessions := newSMTPTestServer(t)
+	host, port, _ := net.SplitHostPort(addr)
+	channel := emailChannel(t, map[string]any{
+		"smtp_host": host,
+		"smtp_port": port,
+		"security":  SMTPSecurityNone,
+		"username":  "probe",
+		"password":  "secret",
+		"from":      "RxProbe <rxprobe@example.com>",
+		"to":        "ops@example.com,\ndev@example.com",
+	})
+	n := NewEmailNotifier()
+	if err := n.Validate(channel); err != nil {
+		t.Fatalf("本机明文认证应通过校验: %v", err)
+	}
+
+	alert := SampleAlert(model.AlertStatusFiring)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if _, err := n.Send(ctx, channel, alert); err != nil {
+		t.Fatalf("发送失败: %v", err)
+	}
+	session := <-sessions
+
+	// 信封
+	if session.auth != "\x00probe\x00secret" {
+		t.Fatalf("AUTH PLAIN 凭据 = %q", session.auth)
+	}
+	if session.from != "FROM:<rxprobe@example.com>" {
+		t.Fatalf("MAIL %s", session.from)
+	}
+	if strings.Join(session.rcpt, ";") != "TO:<ops@example.com>;TO:<dev@example.com>" {
+		t.Fatalf("RCPT %v", session.rcpt)
+	}
+
+	// 邮件头
+	msg, err := mail.ReadMessage(strings.NewReader(string(session.data)))
+	if err != nil {
+		t.Fatalf("解析邮件失败: %v", err)
+	}
+	subject, err := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	if err != nil || !strings.Contains(subject, alert.TargetName) {
+		t.Fatalf("Subject = %q, %v", subject, err)
+	}
+	if got := msg.Header.Get("To"); got != "ops@example.com, dev@example.com" {
+		t.Fatalf("To = %q", got)
+	}
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/alternative" {
+		t.Fatalf("Content-Type = %q, %v", mediaType, err)
+	}
+
+	// 正文：纯文本在前、HTML 在后，均为 quoted-printable 编码的 UTF-8
+	reader := multipart.NewReader(msg.Body, params["boundary"])
+	var types, bodies []string
+	for {
+		part, err := reader.NextRawPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("读取邮件分段失败: %v", err)
+		}
+		if enc := part.Header.Get("Content-Transfer-Encoding"); enc != "quoted-printable" {
+			t.Fatalf("Content-Transfer-Encoding = %q", enc)
+		}
+		body, err := io.ReadAll(quotedprintable.NewReader(bufio.NewReader(part)))
+		if err != nil {
+			t.Fatalf("解码邮件分段失败: %v", err)
+		}
+		types = append(types, part.Header.Get("Content-Type"))
+		bodies = append(bodies, string(body))
+	}
+	if len(types) != 2 || types[0] != "text/plain; charset=UTF-8" || types[1] != "text/html; charset=UTF-8" {
+		t.Fatalf("分段类型 = %v", types)
+	}
+	if want := RenderMessage(channel, alert); bodies[0] != want {
+		t.Fatalf("纯文本正文 = %q, want %q", bodies[0], want)
+	}
+	if !strings.Contains(bodies[1], "<html>") || !strings.Contains(bodies[1], alert.TargetName) {
+		t.Fatalf("HTML 正文缺少目标名称: %q", bodies[1])
+	}
+}
+
+func TestEmailValidatePlaintextAuth(t *testing.T) {
+	n := NewEmailNotifier()
+	config := map[string]any{
+		"smtp_host": "smtp.example.com",
+		"security":  SMTPSecurityNone,
+		"from":      "rxprobe@example.com",
+		"to":        "ops@example.com",
+	}
+	if err := n.Validate(emailChannel(t, config)); err != nil {
+		t.Fatalf("明文连接不认证应通过校验: %v", err)
+	}
+
+	config["username"] = "probe"
+	if err := n.Validate(emailChannel(t, config)); err == nil {
+		t.Fatal("明文连接远程服务器时使用认证应校验失败")
+	}
+
+	config["security"] = SMTPSecuritySTARTTLS
+	if err := n.Validate(emailChannel(t, config)); err != nil {
+		t.Fatalf("STARTTLS 认证应通过校验: %v", err)
+	}
+}
@@ -0,0 +1,193 @@
+package notifier
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/thingsboard-rxprobe/internal/model"
+	"github.com/thingsboard-rxprobe/internal/prober"
+)
+
+// FeishuNotifier 飞书通知
+type FeishuNotifier struct{}
+
+// NewFeishuNotifier 创建飞书通知
+func NewFeishuNotifier() *FeishuNotifier {
+	return &FeishuNotifier{}
+}
+
+// Type 返回渠道类型
+func (n *FeishuNotifier) Type() model.NotifyChannelType {
+	return model.NotifyChannelTypeFeishu
+}
+
+// Name 返回渠道显示名称
+func (n *FeishuNotifier) Name() string {
+	return "飞书"
+}
+
+// Description 返回渠道描述
+func (n *FeishuNotifier) Description() string {
+	return "通过飞书/Lark 群机器人发送交互式卡片通知，支持签名校验"
+}
+
+// ConfigSchema 返回配置表单 schema
+func (n *FeishuNotifier) ConfigSchema() map[string]prober.FieldSchema {
+	return map[string]prober.FieldSchema{
+		"webhook_url": {
+			Type:        "string",
+			Label:       "Webhook URL",
+			Required:    true,
+			Placeholder: "https://open.feishu.cn/open-apis/bot/v2/hook/xxx",
+			Hint:        "飞书/Lark 群机器人的 Webhook 地址",
+		},
+		"secret": {
+			Type:  "password",
+			Label: "签名密钥",
+			Hint:  "机器人安全设置启用“签名校验”时填写",
+		},
+		"mention_all": {
+			Type:         "boolean",
+			Label:        "@所有人",
+			DefaultValue: true,
+			Hint:         "开启后消息将@群内所有成员",
+		},
+	}
+}
+
+// Send 发送到飞书/Lark 自定义机器人（交互式卡片消息）
+func (n *FeishuNotifier) Send(ctx context.Context, channel *model.NotifyChannel, alert *model.Alert) (*Response, error) {
+	msg := map[string]any{
+		"msg_type": "interactive",
+		"card":     buildFeishuCard(channel, alert),
+	}
+
+	// 启用“签名校验”的机器人需要在消息体中携带 timestamp 与 sign
+	if channel.Secret != "" {
+		timestamp := time.Now().Unix()
+		msg["timestamp"] = strconv.FormatInt(timestamp, 10)
+		msg["sign"] = signFeishu(channel.Secret, timestamp)
+	}
+
+	resp, err := postJSON(ctx, channel.WebhookURL, msg)
+	if err != nil {
+		return resp, err
+	}
+
+	// 新版接口返回 code/msg，旧版接口返回 StatusCode/StatusMessage
+	var result struct {
+		Code          int    `json:"code"`
+		Msg           string `json:"msg"`
+		StatusCode    int    `json:"StatusCode"`
+		StatusMessage string `json:"StatusMessage"`
+	}
+	if err := json.Unmarshal([]byte(resp.Body), &result); err != nil {
+		return resp, fmt.Errorf("解析响应失败: %w", err)
+	}
+	if result.Code != 0 {
+		return resp, fmt.Errorf("飞书 API 错误: %d - %s", result.Code, result.Msg)
+	}
+	if result.StatusCode != 0 {
+		return resp, fmt.Errorf("飞书 API 错误: %d - %s", result.StatusCode, result.StatusMessage)
+	}
+	return resp, nil
+}
+
+// Validate 验证飞书渠道配置
+func (n *FeishuNotifier) Validate(channel *model.NotifyChannel) error {
+	return validateWebhookURL(channel.WebhookURL)
+}
+
+// signFeishu 计算飞书机器人签名
+// 签名算法：base64(HmacSHA256(key = timestamp + "\n" + secret, data = 空))
+func signFeishu(secret string, timestamp int64) string {
+	stringToSign := strconv.FormatInt(timestamp, 10) + "\n" + secret
+	mac := hmac.New(sha256.New, []byte(stringToSign))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// buildFeishuCard 构建告警卡片：触发时按级别着色（严重为红色、警告为橙色、提示为蓝色），恢复为绿色
+func buildFeishuCard(channel *model.NotifyChannel, alert *model.Alert) map[string]any {
+	title := "🚨 告警通知"
+	color := "red"
+	if alert.Status == model.AlertStatusResolved {
+		title = "✅ 恢复通知"
+		color = "green"
+	} else if alert.Severity == model.SeverityWarning {
+		color = "orange"
+	} else if alert.Severity == model.SeverityInfo {
+		color = "blue"
+	}
+
+	fields := []map[string]any{
+		feishuField("级别", alert.Severity.Label()),
+		feishuField("目标", alert.TargetName),
+		feishuField("类型", alert.TargetType),
+		feishuField("触发时间", formatTime(alert.FiredAt)),
+		feishuField("故障时长", FormatDuration(alert.Duration())),
+	}
+	if alert.Group != "" {
+		fields = append(fields, feishuField("分组", alert.Group))
+	}
+	if alert.Endpoint != "" {
+		fields = append(fields, feishuField("地址", alert.Endpoint))
+	}
+	if alert.ResolvedAt != nil {
+		fields = append(fields, feishuField("恢复时间", formatTime(alert.ResolvedAt)))
+	}
+
+	// 配置了消息模板时，卡片正文使用模板渲染结果；否则展示告警原因
+	content := alert.Message
+	if strings.TrimSpace(channel.MessageTpl) != "" {
+		content = RenderMessage(channel, alert)
+	}
+	if channel.MentionAll {
+		content += "\n<at id=all></at>"
+	}
+
+	elements := []map[string]any{
+		{"tag": "div", "fields": fields},
+		{"tag": "hr"},
+		{"tag": "div", "text": map[string]any{"tag": "lark_md", "content": content}},
+	}
+	if alert.DetailURL != "" {
+		elements = append(elements, map[string]any{
+			"tag": "action",
+			"actions": []map[string]any{
+				{
+					"tag":  "button",
+					"text": map[string]any{"tag": "plain_text", "content": "查看详情"},
+					"type": "primary",
+					"url":  alert.DetailURL,
+				},
+			},
+		})
+	}
+
+	return map[string]any{
+		"config": map[string]any{"wide_screen_mode": true},
+		"header": map[string]any{
+			"title":    map[string]any{"tag": "plain_text", "content": title + " - " + alert.TargetName},
+			"template": color,
+		},
+		"elements": elements,
+	}
+}
+
+// feishuField 构建卡片中的短字段
+func feishuField(label, value string) map[string]any {
+	return map[string]any{
+		"is_short": true,
+		"text": map[string]any{
+			"tag":     "lark_md",
+			"content": fmt.Sprintf("**%s**\n%s", label, value),
+		},
+	}
+}
@@ -0,0 +1,47 @@
+package notifier
+
+import (
+	"testing"
+
+	"github.com/thingsboard-rxprobe/internal/model"
+)
+
+func TestSignFeishu(t *testing.T) {
+	// 签名由 base64(HmacSHA256(key = "1700000000\nfeishu-secret", data = 空)) 独立计算得到
+	if got, want := signFeishu("feishu-secret", 1700000000), "OrBzY1Y01Gq+HgJsl+7OfWcMVwc7YocohQm5iiZwjhU="; got != want {
+		t.Fatalf("signFeishu() = %q, want %q", got, want)
+	}
+	if signFeishu("feishu-secret", 1700000001) == signFeishu("feishu-secret", 1700000000) {
+		t.Fatal("不同时间戳的签名应不同")
+	}
+}
+
+func TestBuildFeishuCardColor(t *testing.T) {
+	tests := []struct {
+		name      string
+		status    model.AlertStatus
+		severity  model.Severity
+		wantColor string
+		wantTitle string
+	}{
+		{"严重告警为红色", model.AlertStatusFiring, model.SeverityCritical, "red", "🚨 告警通知 - 测试目标"},
+		{"警告告警为橙色", model.AlertStatusFiring, model.SeverityWarning, "orange", "🚨 告警通知 - 测试目标"},
+		{"提示告警为蓝色", model.AlertStatusFiring, model.SeverityInfo, "blue", "🚨 告警通知 - 测试目标"},
+		{"恢复为绿色", model.AlertStatusResolved, model.SeverityWarning, "green", "✅ 恢复通知 - 测试目标"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			alert := SampleAlert(tt.status)
+			alert.Severity = tt.severity
+			card := buildFeishuCard(&model.NotifyChannel{}, alert)
+
+			header := card["header"].(map[string]any)
+			if header["template"] != tt.wantColor {
+				t.Fatalf("卡片颜色 = %v, want %s", header["template"], tt.wantColor)
+			}
+			if title := header["title"].(map[string]any)["content"]; title != tt.wantTitle {
+				t.Fatalf("卡片标题 = %v, want %s", title, tt.wantTitle)
+			}
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// defaultTimeout 通知请求默认超时时间
+const defaultTimeout = 10 * time.Second
+
+// maxResponseBody 记录的响应体最大长度
+const maxResponseBody = 10240
+
+// Response 通知渠道接口的响应，用于投递记录
+type Response struct {
+	StatusCode int    `json:"status_code"`
+	Body       string `json:"body"`
+}
+
+// postJSON 以 JSON 格式 POST 消息，返回响应
+// 非 2xx 状态码视为发送失败，此时同样返回响应以便记录错误详情
+func postJSON(ctx context.Context, endpoint string, payload any) (*Response, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("序列化消息失败: %w", err)
+	}
+
+	client := &http.Client{Timeout: defaultTimeout}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("创建请求失败: %w", stripURL(err))
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("发送请求失败: %w", stripURL(err))
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxResponseBody)) // 最多读取 10KB
+	result := &Response{StatusCode: resp.StatusCode, Body: string(body)}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return result, fmt.Errorf("API 返回错误: HTTP %d", resp.StatusCode)
+	}
+	return result, nil
+}
+
+// stripURL 去掉 *url.Error 中的请求 URL，只保留底层错误
+// URL 中可能包含密钥（如 Telegram Bot Token、企业微信 key），错误信息会写入投递记录并在接口中返回
+func stripURL(err error) error {
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		return urlErr.Err
+	}
+	return err
+}
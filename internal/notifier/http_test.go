@@ -0,0 +1,27 @@
+package notifier
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+)
+
+func TestPostJSONErrorOmitsURL(t *testing.T) {
+	// 监听后立即关闭，得到一个拒绝连接的地址
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	ln.Close()
+
+	const token = "123456:bot-token-value"
+	_, err = postJSON(context.Background(), "http://"+addr+"/bot"+token+"/sendMessage", map[string]string{"text": "test"})
+	if err == nil {
+		t.Fatal("连接被拒绝时应返回错误")
+	}
+	if strings.Contains(err.Error(), token) {
+		t.Fatalf("错误信息泄露了 URL 中的密钥: %v", err)
+	}
+}
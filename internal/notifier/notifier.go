@@ -0,0 +1,136 @@
+package notifier
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/thingsboard-rxprobe/internal/model"
+	"github.com/thingsboard-rxprobe/internal/prober"
+)
+
+// Notifier 通知渠道接口
+type Notifier interface {
+	// Type 返回渠道类型
+	Type() model.NotifyChannelType
+
+	// Name 返回渠道显示名称
+	Name() string
+
+	// Description 返回渠道描述
+	Description() string
+
+	// Send 发送告警，返回渠道接口的响应（发送失败时可能为空）
+	Send(ctx context.Context, channel *model.NotifyChannel, alert *model.Alert) (*Response, error)
+
+	// Validate 验证渠道配置
+	Validate(channel *model.NotifyChannel) error
+}
+
+// SchemaProvider 通知渠道 Schema 提供者接口
+//
+// 字段名与 NotifyChannel 的 JSON 字段同名时（webhook_url、secret、mention_all、at_mobiles）
+// 对应渠道本身的列，其余字段保存在渠道的 Config 中
+type SchemaProvider interface {
+	ConfigSchema() map[string]prober.FieldSchema
+}
+
+// RateLimiter 通知渠道发送频率限制接口
+// 渠道接口限制了发送频率时实现（如企业微信、钉钉群机器人每分钟最多 20 条），返回默认的每分钟最大消息数
+type RateLimiter interface {
+	RateLimit() int
+}
+
+// TypeInfo 通知渠道类型描述
+type TypeInfo struct {
+	Type        model.NotifyChannelType       `json:"type"`
+	Name        string                        `json:"name"`
+	Description string                        `json:"description"`
+	Schema      map[string]prober.FieldSchema `json:"schema"`
+}
+
+// Registry 通知渠道注册表
+type Registry struct {
+	notifiers map[model.NotifyChannelType]Notifier
+	order     []model.NotifyChannelType // 注册顺序，用于稳定的类型列表展示
+}
+
+// NewRegistry 创建通知渠道注册表
+func NewRegistry() *Registry {
+	r := &Registry{
+		notifiers: make(map[model.NotifyChannelType]Notifier),
+	}
+	// 注册所有通知渠道
+	r.Register(NewWeComNotifier())
+	r.Register(NewDingTalkNotifier())
+	r.Register(NewFeishuNotifier())
+	r.Register(NewWebhookNotifier())
+	r.Register(NewEmailNotifier())
+	r.Register(NewSlackNotifier())
+	r.Register(NewTelegramNotifier())
+	return r
+}
+
+// Register 注册通知渠道
+func (r *Registry) Register(n Notifier) {
+	if _, exists := r.notifiers[n.Type()]; !exists {
+		r.order = append(r.order, n.Type())
+	}
+	r.notifiers[n.Type()] = n
+}
+
+// Get 获取通知渠道
+func (r *Registry) Get(channelType model.NotifyChannelType) (Notifier, bool) {
+	n, ok := r.notifiers[channelType]
+	return n, ok
+}
+
+// Types 获取所有支持的通知渠道类型及其配置 Schema
+func (r *Registry) Types() []TypeInfo {
+	types := make([]TypeInfo, 0, len(r.order))
+	for _, t := range r.order {
+		n := r.notifiers[t]
+		info := TypeInfo{
+			Type:        t,
+			Name:        n.Name(),
+			Description: n.Description(),
+			Schema:      make(map[string]prober.FieldSchema),
+		}
+		if provider, ok := n.(SchemaProvider); ok {
+			info.Schema = provider.ConfigSchema()
+		}
+		types = append(types, info)
+	}
+	return types
+}
+
+// Validate 按渠道类型验证通知渠道配置
+func (r *Registry) Validate(channel *model.NotifyChannel) error {
+	n, ok := r.Get(channel.Type)
+	if !ok {
+		return fmt.Errorf("不支持的通知类型: %s", channel.Type)
+	}
+	return n.Validate(channel)
+}
+
+// RateLimit 获取渠道每分钟最大消息数
+// 优先使用渠道配置的 rate_limit_per_minute，其次为渠道类型的默认限制，返回 0 表示未限制
+func (r *Registry) RateLimit(channel *model.NotifyChannel) int {
+	if limit := getIntConfig(ChannelConfig(channel), "rate_limit_per_minute", 0); limit > 0 {
+		return limit
+	}
+	if n, ok := r.Get(channel.Type); ok {
+		if limiter, ok := n.(RateLimiter); ok {
+			return limiter.RateLimit()
+		}
+	}
+	return 0
+}
+
+// Send 按渠道类型发送告警
+func (r *Registry) Send(ctx context.Context, channel *model.NotifyChannel, alert *model.Alert) (*Response, error) {
+	n, ok := r.Get(channel.Type)
+	if !ok {
+		return nil, fmt.Errorf("不支持的通知渠道类型: %s", channel.Type)
+	}
+	return n.Send(ctx, channel, alert)
+}
@@ -0,0 +1,179 @@
+package notifier
+
+import (
+	"encoding/json"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/thingsboard-rxprobe/internal/model"
+)
+
+// SecretMask 敏感配置在 API 响应中的掩码，更新时提交掩码表示保持原值
+const SecretMask = "******"
+
+// headersConfigKey 请求头配置项，其中的值常包含认证信息（如 Authorization）
+const headersConfigKey = "headers"
+
+// webhookSecretParams Webhook URL 中作为凭据的查询参数（企业微信 key、钉钉 access_token、通用 Webhook 常用的 token）
+var webhookSecretParams = []string{"key", "access_token", "token"}
+
+// webhookSecretPaths 路径本身包含凭据的 Webhook（Slack /services/…、飞书 /hook/…），其后的部分为凭据
+var webhookSecretPaths = []string{"/services/", "/hook/"}
+
+// secretConfigKeys 渠道 Config 中的敏感字段：schema 中 password 类型的字段（渠道本身的 secret 列除外）
+func (r *Registry) secretConfigKeys(channelType model.NotifyChannelType) []string {
+	n, ok := r.Get(channelType)
+	if !ok {
+		return nil
+	}
+	provider, ok := n.(SchemaProvider)
+	if !ok {
+		return nil
+	}
+	var keys []string
+	for key, field := range provider.ConfigSchema() {
+		if field.Type == "password" && key != "secret" {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// MaskSecrets 将渠道 Config 中的敏感字段替换为掩码，用于 API 响应
+// password 类型字段整体替换，请求头保留名称、替换值，Webhook URL 中的凭据部分替换为掩码
+func (r *Registry) MaskSecrets(channel *model.NotifyChannel) {
+	channel.WebhookURL = MaskWebhookURL(channel.WebhookURL)
+	config := ChannelConfig(channel)
+	if len(config) == 0 {
+		return
+	}
+	for _, key := range r.secretConfigKeys(channel.Type) {
+		if s, ok := config[key].(string); ok && s != "" {
+			config[key] = SecretMask
+		}
+	}
+	if headers, ok := config[headersConfigKey].(string); ok && headers != "" {
+		config[headersConfigKey] = maskHeaders(headers)
+	}
+	if data, err := json.Marshal(config); err == nil {
+		channel.Config = data
+	}
+}
+
+// RestoreSecrets 将 Config 中省略或仍为掩码的敏感字段恢复为已保存渠道中的值
+// 显式提交空字符串表示清除；请求头中值为掩码的行恢复为已保存的同名请求头
+func (r *Registry) RestoreSecrets(channel, stored *model.NotifyChannel) {
+	if stored == nil || stored.Type != channel.Type {
+		return
+	}
+	config := ChannelConfig(channel)
+	storedConfig := ChannelConfig(stored)
+	for _, key := range r.secretConfigKeys(channel.Type) {
+		if value, ok := config[key]; ok && value != SecretMask {
+			continue
+		}
+		if saved, ok := storedConfig[key]; ok {
+			config[key] = saved
+		} else {
+			delete(config, key)
+		}
+	}
+	if saved, ok := storedConfig[headersConfigKey].(string); ok && saved != "" {
+		value, present := config[headersConfigKey]
+		if !present {
+			config[headersConfigKey] = saved
+		} else if headers, ok := value.(string); ok {
+			config[headersConfigKey] = restoreHeaders(headers, saved)
+		}
+	}
+	if data, err := json.Marshal(config); err == nil {
+		channel.Config = data
+	}
+}
+
+// MaskWebhookURL 将 Webhook URL 中的凭据替换为掩码，保留地址与其余部分便于识别
+// 企业微信、钉钉等渠道的凭据在查询参数中，Slack、飞书的凭据在路径中；URL 中的用户信息同样隐藏
+func MaskWebhookURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return rawURL
+	}
+
+	masked := false
+	path := u.EscapedPath()
+	for _, prefix := range webhookSecretPaths {
+		if i := strings.Index(path, prefix); i >= 0 && len(path) > i+len(prefix) {
+			path = path[:i+len(prefix)] + SecretMask
+			masked = true
+			break
+		}
+	}
+	var params []string
+	if u.RawQuery != "" {
+		params = strings.Split(u.RawQuery, "&")
+	}
+	for i, param := range params {
+		if name, _, _ := strings.Cut(param, "="); slices.Contains(webhookSecretParams, name) {
+			params[i] = name + "=" + SecretMask
+			masked = true
+		}
+	}
+	if u.User != nil {
+		masked = true
+	}
+	if !masked {
+		return rawURL
+	}
+
+	result := u.Scheme + "://"
+	if u.User != nil {
+		result += SecretMask + "@"
+	}
+	result += u.Host + path
+	if len(params) > 0 {
+		result += "?" + strings.Join(params, "&")
+	}
+	return result
+}
+
+// RestoreWebhookURL 提交的 Webhook URL 与已保存 URL 的掩码形式相同（未修改）时返回已保存的 URL
+func RestoreWebhookURL(submitted, stored string) string {
+	if stored != "" && submitted != stored && submitted == MaskWebhookURL(stored) {
+		return stored
+	}
+	return submitted
+}
+
+// maskHeaders 将每行请求头的值替换为掩码
+func maskHeaders(headers string) string {
+	lines := strings.Split(headers, "\n")
+	for i, line := range lines {
+		if key, _, ok := strings.Cut(line, ":"); ok && strings.TrimSpace(key) != "" {
+			lines[i] = strings.TrimSpace(key) + ": " + SecretMask
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// restoreHeaders 将值为掩码的请求头行替换为已保存的同名请求头（名称不区分大小写）
+func restoreHeaders(headers, saved string) string {
+	savedLines := make(map[string]string)
+	for _, line := range strings.Split(saved, "\n") {
+		if key, _, ok := strings.Cut(line, ":"); ok {
+			savedLines[strings.ToLower(strings.TrimSpace(key))] = strings.TrimSpace(line)
+		}
+	}
+
+	lines := strings.Split(headers, "\n")
+	for i, line := range lines {
+		key, value, ok := strings.Cut(line, ":")
+		if !ok || strings.TrimSpace(value) != SecretMask {
+			continue
+		}
+		if savedLine, ok := savedLines[strings.ToLower(strings.TrimSpace(key))]; ok {
+			lines[i] = savedLine
+		}
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,181 @@
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/thingsboard-rxprobe/internal/model"
+	"github.com/thingsboard-rxprobe/internal/prober"
+)
+
+// slackMrkdwnEscaper 转义 Slack mrkdwn 中的控制字符
+var slackMrkdwnEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// Block Kit 的长度限制，超过时 Slack 拒绝整条消息
+const (
+	slackHeaderMaxLength  = 150  // header 块文本
+	slackSectionMaxLength = 3000 // section 块文本
+)
+
+// slackMention 提醒频道内所有成员
+const slackMention = "<!channel>\n"
+
+// SlackNotifier Slack 通知
+type SlackNotifier struct{}
+
+// NewSlackNotifier 创建 Slack 通知
+func NewSlackNotifier() *SlackNotifier {
+	return &SlackNotifier{}
+}
+
+// Type 返回渠道类型
+func (n *SlackNotifier) Type() model.NotifyChannelType {
+	return model.NotifyChannelTypeSlack
+}
+
+// Name 返回渠道显示名称
+func (n *SlackNotifier) Name() string {
+	return "Slack"
+}
+
+// Description 返回渠道描述
+func (n *SlackNotifier) Description() string {
+	return "通过 Slack Incoming Webhook 发送 Block Kit 格式通知"
+}
+
+// ConfigSchema 返回配置表单 schema
+func (n *SlackNotifier) ConfigSchema() map[string]prober.FieldSchema {
+	return map[string]prober.FieldSchema{
+		"webhook_url": {
+			Type:        "string",
+			Label:       "Webhook URL",
+			Required:    true,
+			Placeholder: "https://hooks.slack.com/services/xxx",
+			Hint:        "Slack Incoming Webhook 地址",
+		},
+		"mention_all": {
+			Type:         "boolean",
+			Label:        "@channel",
+			DefaultValue: true,
+			Hint:         "开启后消息将提醒频道内所有成员",
+		},
+	}
+}
+
+// Send 发送到 Slack Incoming Webhook（Block Kit 消息）
+func (n *SlackNotifier) Send(ctx context.Context, channel *model.NotifyChannel, alert *model.Alert) (*Response, error) {
+	content := RenderMessage(channel, alert)
+
+	msg := map[string]any{
+		// text 作为通知栏与不支持 Block Kit 的客户端的回退内容
+		"text":   messageTitle(content) + " - " + alert.TargetName,
+		"blocks": buildSlackBlocks(channel, alert, content),
+	}
+
+	// Incoming Webhook 成功时返回纯文本 "ok"，失败时返回非 2xx 状态码与错误描述
+	resp, err := postJSON(ctx, channel.WebhookURL, msg)
+	if err != nil {
+		if resp != nil && strings.TrimSpace(resp.Body) != "" {
+			return resp, fmt.Errorf("Slack API 错误: %s", strings.TrimSpace(resp.Body))
+		}
+		return resp, err
+	}
+	return resp, nil
+}
+
+// Validate 验证 Slack 渠道配置
+func (n *SlackNotifier) Validate(channel *model.NotifyChannel) error {
+	return validateWebhookURL(channel.WebhookURL)
+}
+
+// buildSlackBlocks 构建 Block Kit 消息块
+func buildSlackBlocks(channel *model.NotifyChannel, alert *model.Alert, content string) []map[string]any {
+	title := "🚨 告警通知"
+	if alert.Status == model.AlertStatusResolved {
+		title = "✅ 恢复通知"
+	}
+
+	fields := []map[string]any{
+		slackField("级别", alert.Severity.Label()),
+		slackField("目标", alert.TargetName),
+		slackField("类型", alert.TargetType),
+		slackField("触发时间", formatTime(alert.FiredAt)),
+		slackField("故障时长", FormatDuration(alert.Duration())),
+	}
+	if alert.Group != "" {
+		fields = append(fields, slackField("分组", alert.Group))
+	}
+	if alert.Endpoint != "" {
+		fields = append(fields, slackField("地址", alert.Endpoint))
+	}
+	if alert.ResolvedAt != nil {
+		fields = append(fields, slackField("恢复时间", formatTime(alert.ResolvedAt)))
+	}
+	// section 块最多支持 10 个字段
+	if len(fields) > 10 {
+		fields = fields[:10]
+	}
+
+	var text string
+	if channel.MentionAll {
+		text = slackMention + slackMrkdwn(content, slackSectionMaxLength-utf8.RuneCountInString(slackMention))
+	} else {
+		text = slackMrkdwn(content, slackSectionMaxLength)
+	}
+
+	blocks := []map[string]any{
+		{
+			"type": "header",
+			"text": map[string]any{"type": "plain_text", "text": truncateText(title+" - "+alert.TargetName, slackHeaderMaxLength), "emoji": true},
+		},
+		{"type": "section", "fields": fields},
+		{"type": "divider"},
+		{
+			"type": "section",
+			"text": map[string]any{"type": "mrkdwn", "text": text},
+		},
+	}
+	if alert.DetailURL != "" {
+		blocks = append(blocks, map[string]any{
+			"type": "actions",
+			"elements": []map[string]any{
+				{
+					"type":  "button",
+					"text":  map[string]any{"type": "plain_text", "text": "查看详情"},
+					"url":   alert.DetailURL,
+					"style": "primary",
+				},
+			},
+		})
+	}
+	return blocks
+}
+
+// slackMrkdwn 转义 mrkdwn 文本并截断为最多 max 个字符，逐字符转义以免截断转义后的实体
+func slackMrkdwn(s string, max int) string {
+	text := slackMrkdwnEscaper.Replace(s)
+	if utf8.RuneCountInString(text) <= max {
+		return text
+	}
+	var b strings.Builder
+	n := 0
+	for _, r := range s {
+		escaped := slackMrkdwnEscaper.Replace(string(r))
+		if n+utf8.RuneCountInString(escaped) > max-1 {
+			break
+		}
+		b.WriteString(escaped)
+		n += utf8.RuneCountInString(escaped)
+	}
+	return b.String() + "…"
+}
+
+// slackField 构建 section 块中的字段
+func slackField(label, value string) map[string]any {
+	return map[string]any{
+		"type": "mrkdwn",
+		"text": fmt.Sprintf("*%s*\n%s", label, slackMrkdwnEscaper.Replace(value)),
+	}
+}
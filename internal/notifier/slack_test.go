@@ -0,0 +1,90 @@
+package notifier
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/thingsboard-rxprobe/internal/model"
+)
+
+// slackBlockText 获取指定类型的第 n 个消息块的文本
+func slackBlockText(t *testing.T, blocks []map[string]any, blockType string, n int) string {
+	t.Helper()
+	for _, block := range blocks {
+		if block["type"] != blockType {
+			continue
+		}
+		text, ok := block["text"].(map[string]any)
+		if !ok {
+			continue
+		}
+		if n == 0 {
+			return text["text"].(string)
+		}
+		n--
+	}
+	t.Fatalf("缺少 %s 块", blockType)
+	return ""
+}
+
+func TestBuildSlackBlocks(t *testing.T) {
+	alert := SampleAlert(model.AlertStatusFiring)
+	alert.DetailURL = "https://rxprobe.example.com/alerts/1"
+	blocks := buildSlackBlocks(&model.NotifyChannel{MentionAll: true}, alert, "标题\n<a & b>")
+
+	if header := slackBlockText(t, blocks, "header", 0); header != "🚨 告警通知 - "+alert.TargetName {
+		t.Fatalf("header = %q", header)
+	}
+	if text := slackBlockText(t, blocks, "section", 0); text != "<!channel>\n标题\n&lt;a &amp; b&gt;" {
+		t.Fatalf("section = %q", text)
+	}
+	if last := blocks[len(blocks)-1]; last["type"] != "actions" {
+		t.Fatalf("存在详情链接时最后一个块应为按钮: %v", last)
+	}
+
+	alert = SampleAlert(model.AlertStatusResolved)
+	blocks = buildSlackBlocks(&model.NotifyChannel{}, alert, "恢复")
+	if header := slackBlockText(t, blocks, "header", 0); !strings.HasPrefix(header, "✅ 恢复通知") {
+		t.Fatalf("恢复通知的 header = %q", header)
+	}
+	if text := slackBlockText(t, blocks, "section", 0); text != "恢复" {
+		t.Fatalf("未开启 @channel 时不应提醒: %q", text)
+	}
+}
+
+func TestBuildSlackBlocksTruncates(t *testing.T) {
+	alert := SampleAlert(model.AlertStatusFiring)
+	alert.TargetName = strings.Repeat("长", 200)
+
+	tests := []struct {
+		name       string
+		mentionAll bool
+		content    string
+	}{
+		{"普通文本", false, strings.Repeat("告", 4000)},
+		{"@channel 前缀计入长度", true, strings.Repeat("告", 4000)},
+		{"不截断转义后的实体", false, strings.Repeat("&", 1000)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			blocks := buildSlackBlocks(&model.NotifyChannel{MentionAll: tt.mentionAll}, alert, tt.content)
+
+			header := slackBlockText(t, blocks, "header", 0)
+			if n := utf8.RuneCountInString(header); n != slackHeaderMaxLength || !strings.HasSuffix(header, "…") {
+				t.Fatalf("header 长度 = %d，应截断为 %d 个字符并以省略号结尾", n, slackHeaderMaxLength)
+			}
+
+			text := slackBlockText(t, blocks, "section", 0)
+			if n := utf8.RuneCountInString(text); n > slackSectionMaxLength || !strings.HasSuffix(text, "…") {
+				t.Fatalf("section 长度 = %d，应截断为不超过 %d 个字符并以省略号结尾", n, slackSectionMaxLength)
+			}
+			if tt.mentionAll && !strings.HasPrefix(text, slackMention) {
+				t.Fatalf("截断后应保留 @channel: %q", text[:20])
+			}
+			if strings.HasPrefix(tt.content, "&") && strings.ReplaceAll(strings.TrimSuffix(text, "…"), "&amp;", "") != "" {
+				t.Fatalf("截断了转义后的实体: %q", text[len(text)-20:])
+			}
+		})
+	}
+}
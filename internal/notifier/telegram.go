@@ -0,0 +1,185 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/thingsboard-rxprobe/internal/model"
+	"github.com/thingsboard-rxprobe/internal/prober"
+)
+
+// defaultTelegramAPIURL Telegram Bot API 默认地址
+const defaultTelegramAPIURL = "https://api.telegram.org"
+
+// telegramMaxMessageLength 单条消息解析格式后的最大字符数
+const telegramMaxMessageLength = 4096
+
+// telegramEscaper 转义 MarkdownV2 中的保留字符
+// 参见 https://core.telegram.org/bots/api#markdownv2-style
+var telegramEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	"_", `\_`, "*", `\*`, "[", `\[`, "]", `\]`, "(", `\(`, ")", `\)`,
+	"~", `\~`, "`", "\\`", ">", `\>`, "#", `\#`, "+", `\+`, "-", `\-`,
+	"=", `\=`, "|", `\|`, "{", `\{`, "}", `\}`, ".", `\.`, "!", `\!`,
+)
+
+// telegramConfig Telegram 渠道配置
+type telegramConfig struct {
+	BotToken            string
+	ChatID              string
+	APIURL              string
+	DisableNotification bool
+}
+
+// TelegramNotifier Telegram 通知
+type TelegramNotifier struct{}
+
+// NewTelegramNotifier 创建 Telegram 通知
+func NewTelegramNotifier() *TelegramNotifier {
+	return &TelegramNotifier{}
+}
+
+// Type 返回渠道类型
+func (n *TelegramNotifier) Type() model.NotifyChannelType {
+	return model.NotifyChannelTypeTelegram
+}
+
+// Name 返回渠道显示名称
+func (n *TelegramNotifier) Name() string {
+	return "Telegram"
+}
+
+// Description 返回渠道描述
+func (n *TelegramNotifier) Description() string {
+	return "通过 Telegram 机器人发送 MarkdownV2 格式通知"
+}
+
+// ConfigSchema 返回配置表单 schema
+func (n *TelegramNotifier) ConfigSchema() map[string]prober.FieldSchema {
+	return map[string]prober.FieldSchema{
+		"bot_token": {
+			Type:        "password",
+			Label:       "Bot Token",
+			Required:    true,
+			Placeholder: "123456789:ABCdef...",
+			Hint:        "通过 @BotFather 创建机器人获得",
+		},
+		"chat_id": {
+			Type:        "string",
+			Label:       "Chat ID",
+			Required:    true,
+			Placeholder: "-1001234567890",
+			Hint:        "群组/频道 ID，或 @频道用户名",
+		},
+		"api_url": {
+			Type:         "string",
+			Label:        "API 地址",
+			DefaultValue: "https://api.telegram.org",
+			Hint:         "可指向自建的 Bot API 代理",
+		},
+		"disable_notification": {
+			Type:         "boolean",
+			Label:        "静默发送",
+			DefaultValue: false,
+		},
+	}
+}
+
+// Send 通过 Telegram Bot API 发送消息（MarkdownV2 格式）
+//
+// 渠道配置（Config）：
+//   - bot_token：机器人 Token
+//   - chat_id：会话 ID（群组为负数）或 @频道用户名
+//   - api_url：Bot API 地址，默认 https://api.telegram.org，可指向自建代理
+//   - disable_notification：静默发送
+func (n *TelegramNotifier) Send(ctx context.Context, channel *model.NotifyChannel, alert *model.Alert) (*Response, error) {
+	cfg := parseTelegramConfig(channel)
+	// 转义字符不计入长度，截断原始内容即可保证消息不超过限制
+	content := truncateText(RenderMessage(channel, alert), telegramMaxMessageLength)
+
+	title, rest, _ := strings.Cut(content, "\n")
+	text := "*" + escapeTelegramMarkdown(strings.TrimSpace(title)) + "*"
+	if rest = strings.TrimSpace(rest); rest != "" {
+		text += "\n" + escapeTelegramMarkdown(rest)
+	}
+
+	msg := map[string]any{
+		"chat_id":                  cfg.ChatID,
+		"text":                     text,
+		"parse_mode":               "MarkdownV2",
+		"disable_web_page_preview": true,
+		"disable_notification":     cfg.DisableNotification,
+	}
+	if alert.DetailURL != "" {
+		msg["reply_markup"] = map[string]any{
+			"inline_keyboard": [][]map[string]string{
+				{{"text": "查看详情", "url": alert.DetailURL}},
+			},
+		}
+	}
+
+	apiURL := fmt.Sprintf("%s/bot%s/sendMessage", strings.TrimRight(cfg.APIURL, "/"), cfg.BotToken)
+	resp, err := postJSON(ctx, apiURL, msg)
+	if resp == nil {
+		return nil, err
+	}
+
+	// Bot API 在失败时同样返回 JSON 描述，优先使用其中的错误信息
+	var result struct {
+		OK          bool   `json:"ok"`
+		ErrorCode   int    `json:"error_code"`
+		Description string `json:"description"`
+	}
+	if jsonErr := json.Unmarshal([]byte(resp.Body), &result); jsonErr != nil {
+		if err != nil {
+			return resp, err
+		}
+		return resp, fmt.Errorf("解析响应失败: %w", jsonErr)
+	}
+	if !result.OK {
+		return resp, fmt.Errorf("Telegram API 错误: %d - %s", result.ErrorCode, result.Description)
+	}
+	return resp, err
+}
+
+// Validate 验证 Telegram 渠道配置
+func (n *TelegramNotifier) Validate(channel *model.NotifyChannel) error {
+	cfg := parseTelegramConfig(channel)
+	if cfg.BotToken == "" {
+		return fmt.Errorf("缺少必填字段: bot_token")
+	}
+	if !strings.Contains(cfg.BotToken, ":") {
+		return fmt.Errorf("bot_token 格式无效")
+	}
+	if cfg.ChatID == "" {
+		return fmt.Errorf("缺少必填字段: chat_id")
+	}
+	return validateWebhookURL(cfg.APIURL)
+}
+
+// escapeTelegramMarkdown 转义 MarkdownV2 保留字符
+func escapeTelegramMarkdown(s string) string {
+	return telegramEscaper.Replace(s)
+}
+
+// parseTelegramConfig 解析 Telegram 渠道配置
+func parseTelegramConfig(channel *model.NotifyChannel) *telegramConfig {
+	config := ChannelConfig(channel)
+
+	// chat_id 可能以数字形式提交
+	chatID := getStringConfig(config, "chat_id", "")
+	if chatID == "" {
+		if id := getIntConfig(config, "chat_id", 0); id != 0 {
+			chatID = fmt.Sprint(id)
+		}
+	}
+
+	return &telegramConfig{
+		BotToken:            strings.TrimSpace(getStringConfig(config, "bot_token", "")),
+		ChatID:              strings.TrimSpace(chatID),
+		APIURL:              getStringConfig(config, "api_url", defaultTelegramAPIURL),
+		DisableNotification: getBoolConfig(config, "disable_notification", false),
+	}
+}
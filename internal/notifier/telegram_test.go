@@ -0,0 +1,78 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/thingsboard-rxprobe/internal/model"
+)
+
+func TestEscapeTelegramMarkdown(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"plain text", "plain text"},
+		{"tb-node_1", `tb\-node\_1`},
+		{"*bold* [link](url)", `\*bold\* \[link\]\(url\)`},
+		{"~`>#+=|{}.!", "\\~\\`\\>\\#\\+\\=\\|\\{\\}\\.\\!"},
+		{`C:\path`, `C:\\path`},
+		{"故障时长: 5分钟", "故障时长: 5分钟"},
+	}
+	for _, tt := range tests {
+		if got := escapeTelegramMarkdown(tt.in); got != tt.want {
+			t.Errorf("escapeTelegramMarkdown(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestTelegramSendTruncatesLongMessage(t *testing.T) {
+	var sent struct {
+		ChatID string `json:"chat_id"`
+		Text   string `json:"text"`
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/bot123456:token/sendMessage" {
+			t.Errorf("请求路径 = %s", r.URL.Path)
+		}
+		if err := json.NewDecoder(r.Body).Decode(&sent); err != nil {
+			t.Error(err)
+		}
+		w.Write([]byte(`{"ok":true}`))
+	}))
+	defer srv.Close()
+
+	config, _ := json.Marshal(map[string]any{"bot_token": "123456:token", "chat_id": "-100123", "api_url": srv.URL})
+	channel := &model.NotifyChannel{
+		Type:       model.NotifyChannelTypeTelegram,
+		Config:     config,
+		MessageTpl: "{{.TargetName}}\n{{.Message}}",
+	}
+	alert := SampleAlert(model.AlertStatusFiring)
+	alert.Message = strings.Repeat("a.b-", 2000)
+
+	if _, err := NewTelegramNotifier().Send(context.Background(), channel, alert); err != nil {
+		t.Fatal(err)
+	}
+	if sent.ChatID != "-100123" {
+		t.Fatalf("chat_id = %q", sent.ChatID)
+	}
+
+	// 解析 MarkdownV2 后的可见文本：去掉标题的粗体标记与转义字符
+	visible := regexp.MustCompile(`\\(.)`).ReplaceAllString(strings.Replace(sent.Text, "*", "", 2), "$1")
+	if n := utf8.RuneCountInString(visible); n > telegramMaxMessageLength {
+		t.Fatalf("消息长度 %d 超过 %d", n, telegramMaxMessageLength)
+	}
+	if !strings.HasSuffix(sent.Text, "…") {
+		t.Fatalf("截断的消息应以省略号结尾: %q", sent.Text[len(sent.Text)-20:])
+	}
+	if !strings.HasPrefix(sent.Text, "*"+escapeTelegramMarkdown(alert.TargetName)+"*\n") {
+		t.Fatalf("标题应保留粗体: %q", sent.Text[:40])
+	}
+}
@@ -0,0 +1,179 @@
+// Package notifier 告警通知消息渲染与发送
+package notifier
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/thingsboard-rxprobe/internal/model"
+	"github.com/thingsboard-rxprobe/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// TimeLayout 通知消息中使用的时间格式
+const TimeLayout = "2006-01-02 15:04:05"
+
+// templateFuncs 消息模板可用的函数
+var templateFuncs = template.FuncMap{
+	"formatTime":     formatTime,
+	"formatDuration": FormatDuration,
+	"upper":          strings.ToUpper,
+	"lower":          strings.ToLower,
+	"json":           toJSON,
+	"default": func(def, v any) any {
+		if v == nil || v == "" {
+			return def
+		}
+		return v
+	},
+}
+
+// ParseTemplate 解析消息模板
+func ParseTemplate(tpl string) (*template.Template, error) {
+	t, err := template.New("message").Funcs(templateFuncs).Option("missingkey=zero").Parse(tpl)
+	if err != nil {
+		return nil, fmt.Errorf("解析消息模板失败: %w", err)
+	}
+	return t, nil
+}
+
+// ValidateTemplate 校验消息模板
+// 除语法检查外，还会分别使用触发/恢复示例告警试渲染，以便提前发现字段名错误等运行时问题
+func ValidateTemplate(tpl string) error {
+	if strings.TrimSpace(tpl) == "" {
+		return nil
+	}
+	t, err := ParseTemplate(tpl)
+	if err != nil {
+		return err
+	}
+	for _, status := range []model.AlertStatus{model.AlertStatusFiring, model.AlertStatusResolved} {
+		if _, err := execute(t, SampleAlert(status)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Render 使用指定模板渲染告警，模板为空时按告警状态使用默认模板
+func Render(tpl string, alert *model.Alert) (string, error) {
+	if strings.TrimSpace(tpl) == "" {
+		tpl = DefaultTemplate(alert.Status)
+	}
+	t, err := ParseTemplate(tpl)
+	if err != nil {
+		return "", err
+	}
+	return execute(t, alert)
+}
+
+// RenderMessage 渲染渠道的通知消息
+// 渠道模板渲染失败时回退到默认模板，避免因模板错误导致告警丢失
+func RenderMessage(channel *model.NotifyChannel, alert *model.Alert) string {
+	content, err := Render(channel.MessageTpl, alert)
+	if err == nil {
+		return content
+	}
+	logger.Warn("渠道消息模板渲染失败，使用默认模板",
+		zap.String("channel", channel.Name),
+		zap.Error(err),
+	)
+	content, defaultErr := Render("", alert)
+	if defaultErr != nil {
+		return alert.Message
+	}
+	return content
+}
+
+// DefaultTemplate 获取告警状态对应的默认模板
+func DefaultTemplate(status model.AlertStatus) string {
+	if status == model.AlertStatusResolved {
+		return model.DefaultResolvedMessageTemplate
+	}
+	return model.DefaultFiringMessageTemplate
+}
+
+// SampleAlert 生成用于模板校验、预览和测试发送的示例告警
+func SampleAlert(status model.AlertStatus) *model.Alert {
+	firedAt := time.Now().Add(-5 * time.Minute).Truncate(time.Second)
+	alert := &model.Alert{
+		ID:         1,
+		TargetID:   1,
+		TargetName: "测试目标",
+		TargetType: "http",
+		Group:      "默认分组",
+		Endpoint:   "http://example.com/api/health",
+		Status:     status,
+		Severity:   model.SeverityCritical,
+		Message:    "这是一条测试消息",
+		Latency:    1200 * time.Millisecond,
+		FiredAt:    firedAt,
+		DetailURL:  "http://localhost:8088/services/1",
+		Metrics: map[string]any{
+			"status_code": 503,
+		},
+	}
+	if status == model.AlertStatusResolved {
+		resolvedAt := firedAt.Add(5 * time.Minute)
+		alert.ResolvedAt = &resolvedAt
+	}
+	return alert
+}
+
+// execute 执行模板渲染
+func execute(t *template.Template, alert *model.Alert) (string, error) {
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, alert); err != nil {
+		return "", fmt.Errorf("渲染消息模板失败: %w", err)
+	}
+	return strings.TrimSpace(buf.String()), nil
+}
+
+// formatTime 格式化时间，支持 time.Time 与 *time.Time
+func formatTime(v any) string {
+	switch t := v.(type) {
+	case time.Time:
+		return t.Format(TimeLayout)
+	case *time.Time:
+		if t == nil {
+			return ""
+		}
+		return t.Format(TimeLayout)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// toJSON 将值序列化为 JSON 字面量，用于在 JSON 模板中安全地嵌入字符串等字段
+func toJSON(v any) (string, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// FormatDuration 格式化时长
+func FormatDuration(d time.Duration) string {
+	if d < time.Minute {
+		return fmt.Sprintf("%d秒", int(d.Seconds()))
+	}
+	if d < time.Hour {
+		minutes := int(d.Minutes())
+		seconds := int(d.Seconds()) % 60
+		if seconds > 0 {
+			return fmt.Sprintf("%d分%d秒", minutes, seconds)
+		}
+		return fmt.Sprintf("%d分钟", minutes)
+	}
+	hours := int(d.Hours())
+	minutes := int(d.Minutes()) % 60
+	if minutes > 0 {
+		return fmt.Sprintf("%d小时%d分", hours, minutes)
+	}
+	return fmt.Sprintf("%d小时", hours)
+}
@@ -0,0 +1,69 @@
+package notifier
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/thingsboard-rxprobe/internal/model"
+)
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		name   string
+		tpl    string
+		status model.AlertStatus
+		want   []string // 渲染结果应包含的内容
+	}{
+		{"空模板使用默认触发模板", "", model.AlertStatusFiring, []string{"🚨 告警通知", "目标：测试目标", "原因：这是一条测试消息"}},
+		{"空白模板使用默认恢复模板", "  \n", model.AlertStatusResolved, []string{"✅ 恢复通知", "故障时长：5分钟"}},
+		{"自定义模板", "[{{.Status}}] {{upper .TargetType}} {{.Metrics.status_code}}", model.AlertStatusFiring, []string{"[firing] HTTP 503"}},
+		{"模板函数", `{{default "无" .Group}} {{formatDuration .Duration}} {{json .Metrics}}`, model.AlertStatusResolved, []string{`默认分组 5分钟 {"status_code":503}`}},
+		{"缺失的指标使用默认值", `[{{default "-" .Metrics.missing}}]`, model.AlertStatusFiring, []string{"[-]"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Render(tt.tpl, SampleAlert(tt.status))
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(got, want) {
+					t.Fatalf("渲染结果缺少 %q:\n%s", want, got)
+				}
+			}
+		})
+	}
+
+	if _, err := Render("{{.TargetName", SampleAlert(model.AlertStatusFiring)); err == nil {
+		t.Fatal("语法错误的模板应返回错误")
+	}
+}
+
+func TestValidateTemplate(t *testing.T) {
+	tests := []struct {
+		name    string
+		tpl     string
+		wantErr bool
+	}{
+		{"空模板使用默认模板", "", false},
+		{"有效模板", "{{.TargetName}} {{if .ResolvedAt}}{{formatTime .ResolvedAt}}{{end}}", false},
+		{"语法错误", "{{.TargetName", true},
+		{"未定义的函数", "{{unknown .TargetName}}", true},
+		{"不存在的字段", "{{.Nonexistent}}", true},
+		{"仅恢复告警渲染失败", "{{if .ResolvedAt}}{{.ResolvedAt.Nonexistent}}{{end}}", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidateTemplate(tt.tpl); (err != nil) != tt.wantErr {
+				t.Fatalf("ValidateTemplate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRenderMessageFallsBackToDefault(t *testing.T) {
+	channel := &model.NotifyChannel{Name: "wecom", MessageTpl: "{{.Nonexistent}}"}
+	if got := RenderMessage(channel, SampleAlert(model.AlertStatusFiring)); !strings.HasPrefix(got, "🚨 告警通知") {
+		t.Fatalf("模板渲染失败时应使用默认模板: %q", got)
+	}
+}
@@ -0,0 +1,101 @@
+package notifier
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/thingsboard-rxprobe/internal/model"
+)
+
+// SplitList 拆分逗号或换行分隔的列表，忽略空项
+func SplitList(s string) []string {
+	fields := strings.FieldsFunc(s, func(r rune) bool {
+		return r == ',' || r == '\n'
+	})
+	result := make([]string, 0, len(fields))
+	for _, f := range fields {
+		if trimmed := strings.TrimSpace(f); trimmed != "" {
+			result = append(result, trimmed)
+		}
+	}
+	return result
+}
+
+// validateWebhookURL 验证 webhook URL 格式
+func validateWebhookURL(webhookURL string) error {
+	if !strings.HasPrefix(webhookURL, "http://") && !strings.HasPrefix(webhookURL, "https://") {
+		return fmt.Errorf("Webhook URL 格式无效")
+	}
+	if strings.Contains(webhookURL, SecretMask) {
+		return fmt.Errorf("Webhook URL 中的凭据已隐藏，修改地址时请填写完整的 Webhook URL")
+	}
+	return nil
+}
+
+// messageTitle 取消息第一行作为标题
+func messageTitle(content string) string {
+	title, _, _ := strings.Cut(content, "\n")
+	return strings.TrimSpace(title)
+}
+
+// truncateText 将文本截断为最多 max 个字符，截断时以省略号结尾
+func truncateText(s string, max int) string {
+	if utf8.RuneCountInString(s) <= max {
+		return s
+	}
+	if max <= 0 {
+		return ""
+	}
+	return string([]rune(s)[:max-1]) + "…"
+}
+
+// ChannelConfig 解析渠道的类型专属配置
+func ChannelConfig(channel *model.NotifyChannel) map[string]any {
+	config := make(map[string]any)
+	if len(channel.Config) > 0 {
+		_ = json.Unmarshal(channel.Config, &config)
+	}
+	return config
+}
+
+// getStringConfig 获取字符串配置
+func getStringConfig(config map[string]any, key string, defaultValue string) string {
+	if v, ok := config[key]; ok {
+		if s, ok := v.(string); ok && s != "" {
+			return s
+		}
+	}
+	return defaultValue
+}
+
+// getIntConfig 获取整数配置
+func getIntConfig(config map[string]any, key string, defaultValue int) int {
+	if v, ok := config[key]; ok {
+		switch val := v.(type) {
+		case int:
+			return val
+		case int64:
+			return int(val)
+		case float64:
+			return int(val)
+		case string:
+			if i, err := strconv.Atoi(strings.TrimSpace(val)); err == nil {
+				return i
+			}
+		}
+	}
+	return defaultValue
+}
+
+// getBoolConfig 获取布尔配置
+func getBoolConfig(config map[string]any, key string, defaultValue bool) bool {
+	if v, ok := config[key]; ok {
+		if b, ok := v.(bool); ok {
+			return b
+		}
+	}
+	return defaultValue
+}
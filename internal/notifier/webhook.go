@@ -0,0 +1,296 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/thingsboard-rxprobe/internal/model"
+	"github.com/thingsboard-rxprobe/internal/prober"
+)
+
+// 通用 Webhook 签名相关请求头
+const (
+	WebhookSignatureHeader = "X-Rxprobe-Signature" // sha256=<hex(HmacSHA256(secret, timestamp + "." + body))>
+	WebhookTimestampHeader = "X-Rxprobe-Timestamp" // Unix 秒级时间戳
+)
+
+// webhookMethods 通用 Webhook 支持的请求方法
+var webhookMethods = map[string]bool{
+	http.MethodPost:  true,
+	http.MethodPut:   true,
+	http.MethodPatch: true,
+}
+
+// WebhookPayload 通用 Webhook 默认 JSON 消息体
+type WebhookPayload struct {
+	ID              uint64            `json:"id"`
+	TargetID        uint64            `json:"target_id"`
+	TargetName      string            `json:"target_name"`
+	TargetType      string            `json:"target_type"`
+	Group           string            `json:"group"`
+	Endpoint        string            `json:"endpoint"`
+	Status          model.AlertStatus `json:"status"`
+	Severity        model.Severity    `json:"severity"`
+	Message         string            `json:"message"`
+	LatencyMs       int64             `json:"latency_ms"`
+	FiredAt         time.Time         `json:"fired_at"`
+	ResolvedAt      *time.Time        `json:"resolved_at,omitempty"`
+	DurationSeconds int64             `json:"duration_seconds"`
+	DetailURL       string            `json:"detail_url,omitempty"`
+	Metrics         map[string]any    `json:"metrics,omitempty"`
+	Alerts          []*WebhookPayload `json:"alerts,omitempty"` // 分组通知中合并的各条告警
+}
+
+// WebhookNotifier 通用 Webhook 通知
+type WebhookNotifier struct{}
+
+// NewWebhookNotifier 创建通用 Webhook 通知
+func NewWebhookNotifier() *WebhookNotifier {
+	return &WebhookNotifier{}
+}
+
+// Type 返回渠道类型
+func (n *WebhookNotifier) Type() model.NotifyChannelType {
+	return model.NotifyChannelTypeWebhook
+}
+
+// Name 返回渠道显示名称
+func (n *WebhookNotifier) Name() string {
+	return "通用 Webhook"
+}
+
+// Description 返回渠道描述
+func (n *WebhookNotifier) Description() string {
+	return "以可配置的请求方法、请求头和 JSON 消息体推送告警，支持 HMAC 签名"
+}
+
+// ConfigSchema 返回配置表单 schema
+func (n *WebhookNotifier) ConfigSchema() map[string]prober.FieldSchema {
+	return map[string]prober.FieldSchema{
+		"webhook_url": {
+			Type:        "string",
+			Label:       "Webhook URL",
+			Required:    true,
+			Placeholder: "https://example.com/hooks/rxprobe",
+		},
+		"secret": {
+			Type:  "password",
+			Label: "签名密钥",
+			Hint:  "填写后通过 X-Rxprobe-Signature 请求头携带 HmacSHA256 签名",
+		},
+		"method": {
+			Type:         "select",
+			Label:        "请求方法",
+			DefaultValue: "POST",
+			Options: []prober.Option{
+				{Value: "POST", Label: "POST"},
+				{Value: "PUT", Label: "PUT"},
+				{Value: "PATCH", Label: "PATCH"},
+			},
+		},
+		"headers": {
+			Type:        "textarea",
+			Label:       "请求头",
+			Placeholder: "Authorization: Bearer xxx",
+			Hint:        "每行一个，格式: Key: Value，支持模板",
+		},
+		"body": {
+			Type:        "textarea",
+			Label:       "消息体模板",
+			Placeholder: `{"text": {{json .Message}}}`,
+			Hint:        "渲染结果须为合法 JSON，为空时发送默认消息体",
+		},
+		"expected_status": {
+			Type:  "number",
+			Label: "期望状态码",
+			Hint:  "为空时任意 2xx 均视为成功",
+		},
+		"timeout_seconds": {
+			Type:         "number",
+			Label:        "超时时间（秒）",
+			DefaultValue: 10,
+		},
+	}
+}
+
+// Send 发送到通用 Webhook
+//
+// 渠道配置（Config）：
+//   - method：请求方法，默认 POST
+//   - headers：请求头模板，每行一个，格式 Key: Value
+//   - body：JSON 消息体模板，为空时发送 WebhookPayload
+//   - expected_status：期望状态码，为空时任意 2xx 均视为成功
+//   - timeout_seconds：请求超时时间，默认 10 秒
+//
+// 配置了 Secret 时，使用 HmacSHA256 对“时间戳.消息体”签名并通过请求头携带
+func (n *WebhookNotifier) Send(ctx context.Context, channel *model.NotifyChannel, alert *model.Alert) (*Response, error) {
+	config := ChannelConfig(channel)
+
+	method := strings.ToUpper(getStringConfig(config, "method", http.MethodPost))
+	timeout := time.Duration(getIntConfig(config, "timeout_seconds", int(defaultTimeout/time.Second))) * time.Second
+	expectedStatus := getIntConfig(config, "expected_status", 0)
+
+	body, err := renderWebhookBody(getStringConfig(config, "body", ""), alert)
+	if err != nil {
+		return nil, err
+	}
+	headers, err := renderWebhookHeaders(getStringConfig(config, "headers", ""), alert)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, channel.WebhookURL, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("创建请求失败: %w", stripURL(err))
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+	if channel.Secret != "" {
+		timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+		req.Header.Set(WebhookTimestampHeader, timestamp)
+		req.Header.Set(WebhookSignatureHeader, "sha256="+signWebhook(channel.Secret, timestamp, body))
+	}
+
+	client := &http.Client{Timeout: timeout}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("发送请求失败: %w", stripURL(err))
+	}
+	defer resp.Body.Close()
+
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, maxResponseBody))
+	result := &Response{StatusCode: resp.StatusCode, Body: string(respBody)}
+	if expectedStatus > 0 {
+		if resp.StatusCode != expectedStatus {
+			return result, fmt.Errorf("状态码 %d 不符合期望 %d", resp.StatusCode, expectedStatus)
+		}
+		return result, nil
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return result, fmt.Errorf("API 返回错误: HTTP %d", resp.StatusCode)
+	}
+	return result, nil
+}
+
+// Validate 验证通用 Webhook 渠道配置
+func (n *WebhookNotifier) Validate(channel *model.NotifyChannel) error {
+	if err := validateWebhookURL(channel.WebhookURL); err != nil {
+		return err
+	}
+
+	config := ChannelConfig(channel)
+	method := strings.ToUpper(getStringConfig(config, "method", http.MethodPost))
+	if !webhookMethods[method] {
+		return fmt.Errorf("不支持的请求方法: %s", method)
+	}
+	if status := getIntConfig(config, "expected_status", 0); status != 0 && (status < 100 || status > 599) {
+		return fmt.Errorf("期望状态码无效: %d", status)
+	}
+	if timeout := getIntConfig(config, "timeout_seconds", 10); timeout < 1 || timeout > 60 {
+		return fmt.Errorf("超时时间必须在 1-60 秒之间")
+	}
+
+	// 使用示例告警试渲染请求头与消息体
+	for _, status := range []model.AlertStatus{model.AlertStatusFiring, model.AlertStatusResolved} {
+		alert := SampleAlert(status)
+		if _, err := renderWebhookBody(getStringConfig(config, "body", ""), alert); err != nil {
+			return err
+		}
+		if _, err := renderWebhookHeaders(getStringConfig(config, "headers", ""), alert); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// renderWebhookBody 渲染消息体，结果必须是合法 JSON
+func renderWebhookBody(tpl string, alert *model.Alert) ([]byte, error) {
+	if strings.TrimSpace(tpl) == "" {
+		body, err := json.Marshal(newWebhookPayload(alert))
+		if err != nil {
+			return nil, fmt.Errorf("序列化消息失败: %w", err)
+		}
+		return body, nil
+	}
+
+	content, err := Render(tpl, alert)
+	if err != nil {
+		return nil, err
+	}
+	if !json.Valid([]byte(content)) {
+		return nil, fmt.Errorf("消息体模板渲染结果不是合法的 JSON，字符串字段请使用 {{json .Message}} 进行转义")
+	}
+	return []byte(content), nil
+}
+
+// renderWebhookHeaders 渲染请求头，每行一个，格式 Key: Value
+func renderWebhookHeaders(tpl string, alert *model.Alert) (map[string]string, error) {
+	headers := make(map[string]string)
+	if strings.TrimSpace(tpl) == "" {
+		return headers, nil
+	}
+
+	content, err := Render(tpl, alert)
+	if err != nil {
+		return nil, err
+	}
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(line, ":")
+		if !ok || strings.TrimSpace(key) == "" {
+			return nil, fmt.Errorf("请求头格式无效: %s", line)
+		}
+		headers[strings.TrimSpace(key)] = strings.TrimSpace(value)
+	}
+	return headers, nil
+}
+
+// newWebhookPayload 构建默认消息体
+func newWebhookPayload(alert *model.Alert) *WebhookPayload {
+	var alerts []*WebhookPayload
+	for _, a := range alert.Alerts {
+		alerts = append(alerts, newWebhookPayload(a))
+	}
+	return &WebhookPayload{
+		ID:              alert.ID,
+		TargetID:        alert.TargetID,
+		TargetName:      alert.TargetName,
+		TargetType:      alert.TargetType,
+		Group:           alert.Group,
+		Endpoint:        alert.Endpoint,
+		Status:          alert.Status,
+		Severity:        alert.Severity,
+		Message:         alert.Message,
+		LatencyMs:       alert.Latency.Milliseconds(),
+		FiredAt:         alert.FiredAt,
+		ResolvedAt:      alert.ResolvedAt,
+		DurationSeconds: int64(alert.Duration().Seconds()),
+		DetailURL:       alert.DetailURL,
+		Metrics:         alert.Metrics,
+		Alerts:          alerts,
+	}
+}
+
+// signWebhook 计算通用 Webhook 签名：hex(HmacSHA256(secret, timestamp + "." + body))
+func signWebhook(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,159 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/thingsboard-rxprobe/internal/model"
+)
+
+func webhookChannel(t *testing.T, url, secret string, config map[string]any) *model.NotifyChannel {
+	t.Helper()
+	data, err := json.Marshal(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &model.NotifyChannel{Name: "Webhook", Type: model.NotifyChannelTypeWebhook, WebhookURL: url, Secret: secret, Config: data, Enabled: true}
+}
+
+func TestSignWebhook(t *testing.T) {
+	// 签名由 hex(HmacSHA256("webhook-secret", `1700000000.{"text":"hi"}`)) 独立计算得到
+	const want = "5b62b8d062ff38187a799923c53be6103d67aa406cdd0746b0fe2d40b19f78fb"
+	if got := signWebhook("webhook-secret", "1700000000", []byte(`{"text":"hi"}`)); got != want {
+		t.Fatalf("signWebhook() = %q, want %q", got, want)
+	}
+}
+
+func TestRenderWebhookBody(t *testing.T) {
+	alert := SampleAlert(model.AlertStatusFiring)
+
+	body, err := renderWebhookBody("", alert)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var payload WebhookPayload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		t.Fatalf("默认消息体不是合法的 JSON: %v", err)
+	}
+	if payload.TargetName != alert.TargetName || payload.Status != model.AlertStatusFiring || payload.Severity != alert.Severity {
+		t.Fatalf("默认消息体 = %+v", payload)
+	}
+
+	body, err = renderWebhookBody(`{"text": {{json .Message}}, "target": {{json .TargetName}}}`, alert)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(body) != `{"text": "这是一条测试消息", "target": "测试目标"}` {
+		t.Fatalf("模板消息体 = %s", body)
+	}
+
+	if _, err := renderWebhookBody(`{"text": {{.Message}}}`, alert); err == nil {
+		t.Fatal("渲染结果不是合法 JSON 时应返回错误")
+	}
+}
+
+func TestWebhookSend(t *testing.T) {
+	var (
+		gotMethod string
+		gotHeader http.Header
+		gotBody   []byte
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotMethod = r.Method
+		gotHeader = r.Header.Clone()
+		gotBody, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer srv.Close()
+
+	channel := webhookChannel(t, srv.URL, "webhook-secret", map[string]any{
+		"method":  "PUT",
+		"headers": "X-Target: {{.TargetName}}",
+		"body":    `{"text": {{json .Message}}}`,
+	})
+	if _, err := NewWebhookNotifier().Send(context.Background(), channel, SampleAlert(model.AlertStatusFiring)); err != nil {
+		t.Fatal(err)
+	}
+
+	if gotMethod != http.MethodPut {
+		t.Fatalf("请求方法 = %s, want PUT", gotMethod)
+	}
+	if gotHeader.Get("X-Target") != "测试目标" {
+		t.Fatalf("X-Target = %q", gotHeader.Get("X-Target"))
+	}
+	if string(gotBody) != `{"text": "这是一条测试消息"}` {
+		t.Fatalf("消息体 = %s", gotBody)
+	}
+	timestamp := gotHeader.Get(WebhookTimestampHeader)
+	if timestamp == "" {
+		t.Fatal("配置密钥时应携带时间戳请求头")
+	}
+	if want := "sha256=" + signWebhook("webhook-secret", timestamp, gotBody); gotHeader.Get(WebhookSignatureHeader) != want {
+		t.Fatalf("签名 = %q, want %q", gotHeader.Get(WebhookSignatureHeader), want)
+	}
+}
+
+func TestWebhookSendStatus(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer srv.Close()
+
+	tests := []struct {
+		name    string
+		config  map[string]any
+		wantErr string
+	}{
+		{"未配置期望状态码时 2xx 均为成功", map[string]any{}, ""},
+		{"状态码符合期望", map[string]any{"expected_status": 202}, ""},
+		{"状态码不符合期望", map[string]any{"expected_status": 200}, "状态码 202 不符合期望 200"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			channel := webhookChannel(t, srv.URL, "", tt.config)
+			resp, err := NewWebhookNotifier().Send(context.Background(), channel, SampleAlert(model.AlertStatusFiring))
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatal(err)
+				}
+			} else if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("err = %v, want %q", err, tt.wantErr)
+			}
+			if resp == nil || resp.StatusCode != http.StatusAccepted {
+				t.Fatalf("resp = %+v", resp)
+			}
+		})
+	}
+}
+
+func TestWebhookValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  map[string]any
+		wantErr bool
+	}{
+		{"默认配置", map[string]any{}, false},
+		{"超时时间下限", map[string]any{"timeout_seconds": 1}, false},
+		{"超时时间上限", map[string]any{"timeout_seconds": 60}, false},
+		{"超时时间过小", map[string]any{"timeout_seconds": 0}, true},
+		{"超时时间过大", map[string]any{"timeout_seconds": 61}, true},
+		{"不支持的请求方法", map[string]any{"method": "GET"}, true},
+		{"期望状态码无效", map[string]any{"expected_status": 99}, true},
+		{"消息体不是合法 JSON", map[string]any{"body": `{"text": {{.Message}}}`}, true},
+		{"请求头格式无效", map[string]any{"headers": "Authorization"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			channel := webhookChannel(t, "https://example.com/hooks/rxprobe", "", tt.config)
+			err := NewWebhookNotifier().Validate(channel)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Validate() err = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
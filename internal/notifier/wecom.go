@@ -0,0 +1,106 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/thingsboard-rxprobe/internal/model"
+	"github.com/thingsboard-rxprobe/internal/prober"
+)
+
+// weComRateLimit 群机器人每分钟最多发送 20 条消息
+const weComRateLimit = 20
+
+// WeComNotifier 企业微信通知
+type WeComNotifier struct{}
+
+// NewWeComNotifier 创建企业微信通知
+func NewWeComNotifier() *WeComNotifier {
+	return &WeComNotifier{}
+}
+
+// Type 返回渠道类型
+func (n *WeComNotifier) Type() model.NotifyChannelType {
+	return model.NotifyChannelTypeWeCom
+}
+
+// Name 返回渠道显示名称
+func (n *WeComNotifier) Name() string {
+	return "企业微信"
+}
+
+// Description 返回渠道描述
+func (n *WeComNotifier) Description() string {
+	return "通过企业微信群机器人发送通知"
+}
+
+// RateLimit 返回默认的每分钟最大消息数
+func (n *WeComNotifier) RateLimit() int {
+	return weComRateLimit
+}
+
+// ConfigSchema 返回配置表单 schema
+func (n *WeComNotifier) ConfigSchema() map[string]prober.FieldSchema {
+	return map[string]prober.FieldSchema{
+		"webhook_url": {
+			Type:        "string",
+			Label:       "Webhook URL",
+			Required:    true,
+			Placeholder: "https://qyapi.weixin.qq.com/cgi-bin/webhook/send?key=xxx",
+			Hint:        "企业微信群机器人的 Webhook 地址",
+		},
+		"rate_limit_per_minute": {
+			Type:         "number",
+			Label:        "每分钟最大消息数",
+			DefaultValue: weComRateLimit,
+			Hint:         "企业微信群机器人每分钟最多发送 20 条消息，超出的通知排队发送",
+		},
+		"mention_all": {
+			Type:         "boolean",
+			Label:        "@所有人",
+			DefaultValue: true,
+			Hint:         "开启后消息将@群内所有成员",
+		},
+	}
+}
+
+// Send 发送到企业微信群机器人（文本消息）
+func (n *WeComNotifier) Send(ctx context.Context, channel *model.NotifyChannel, alert *model.Alert) (*Response, error) {
+	msg := struct {
+		MsgType string `json:"msgtype"`
+		Text    struct {
+			Content       string   `json:"content"`
+			MentionedList []string `json:"mentioned_list,omitempty"`
+		} `json:"text"`
+	}{
+		MsgType: "text",
+	}
+	msg.Text.Content = RenderMessage(channel, alert)
+	// 根据配置决定是否@所有人
+	if channel.MentionAll {
+		msg.Text.MentionedList = []string{"@all"}
+	}
+
+	resp, err := postJSON(ctx, channel.WebhookURL, msg)
+	if err != nil {
+		return resp, err
+	}
+
+	var result struct {
+		ErrCode int    `json:"errcode"`
+		ErrMsg  string `json:"errmsg"`
+	}
+	if err := json.Unmarshal([]byte(resp.Body), &result); err != nil {
+		return resp, fmt.Errorf("解析响应失败: %w", err)
+	}
+	if result.ErrCode != 0 {
+		return resp, fmt.Errorf("企业微信 API 错误: %d - %s", result.ErrCode, result.ErrMsg)
+	}
+	return resp, nil
+}
+
+// Validate 验证企业微信渠道配置
+func (n *WeComNotifier) Validate(channel *model.NotifyChannel) error {
+	return validateWebhookURL(channel.WebhookURL)
+}
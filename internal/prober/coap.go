@@ -0,0 +1,378 @@
+package prober
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"sort"
+	"strings"
+	"time"
+)
+
+// 最小 CoAP 客户端（RFC 7252），用于 CoAP 与 LwM2M 探针
+
+// CoAP 消息类型
+const (
+	coapConfirmable    byte = 0
+	coapNonConfirmable byte = 1
+	coapAcknowledgment byte = 2
+	coapReset          byte = 3
+)
+
+// CoAP 请求方法
+const (
+	coapGET    byte = 0x01
+	coapPOST   byte = 0x02
+	coapPUT    byte = 0x03
+	coapDELETE byte = 0x04
+)
+
+// CoAP 响应码
+const (
+	coapCreated  byte = 0x41 // 2.01
+	coapDeleted  byte = 0x42 // 2.02
+	coapChanged  byte = 0x44 // 2.04
+	coapNotFound byte = 0x84 // 4.04
+)
+
+// CoAP 选项编号
+const (
+	coapOptionLocationPath  uint16 = 8
+	coapOptionURIPath       uint16 = 11
+	coapOptionContentFormat uint16 = 12
+	coapOptionURIQuery      uint16 = 15
+)
+
+// CoAP 内容格式
+const (
+	coapFormatText    uint16 = 0
+	coapFormatLinkFmt uint16 = 40
+	coapFormatJSON    uint16 = 50
+)
+
+// CoAP 传输参数
+const (
+	coapMaxMessageSize    = 1152 // RFC 7252 建议的最大消息长度
+	coapRandomFactor      = 1.5  // ACK_RANDOM_FACTOR
+	coapDefaultRetries    = 4    // MAX_RETRANSMIT
+	coapDefaultAckTimeout = 2 * time.Second
+)
+
+// coapMethods 请求方法名称
+var coapMethods = map[string]byte{
+	"GET":    coapGET,
+	"POST":   coapPOST,
+	"PUT":    coapPUT,
+	"DELETE": coapDELETE,
+}
+
+// coapOption CoAP 选项
+type coapOption struct {
+	number uint16
+	value  []byte
+}
+
+// coapMessage CoAP 消息
+type coapMessage struct {
+	typ       byte
+	code      byte
+	messageID uint16
+	token     []byte
+	options   []coapOption
+	payload   []byte
+}
+
+// coapDatagramConn CoAP 底层数据报连接（UDP 或 DTLS）
+type coapDatagramConn interface {
+	Read(b []byte) (int, error)
+	Write(b []byte) (int, error)
+	SetReadDeadline(t time.Time) error
+}
+
+// coapClient CoAP 客户端，按 RFC 7252 的指数退避重传可靠消息
+type coapClient struct {
+	conn            coapDatagramConn
+	messageID       uint16
+	ackTimeout      time.Duration
+	maxRetransmit   int
+	retransmissions int // 累计重传次数
+}
+
+// newCoAPClient 创建 CoAP 客户端
+func newCoAPClient(conn coapDatagramConn, ackTimeout time.Duration, maxRetransmit int) *coapClient {
+	if ackTimeout <= 0 {
+		ackTimeout = coapDefaultAckTimeout
+	}
+	if maxRetransmit < 0 {
+		maxRetransmit = coapDefaultRetries
+	}
+	id, _ := rand.Int(rand.Reader, big.NewInt(1<<16))
+	return &coapClient{
+		conn:          conn,
+		messageID:     uint16(id.Int64()),
+		ackTimeout:    ackTimeout,
+		maxRetransmit: maxRetransmit,
+	}
+}
+
+// newCoAPRequest 创建请求，path 按 / 拆分为 Uri-Path 选项，? 之后按 & 拆分为 Uri-Query 选项
+func newCoAPRequest(code byte, path string) *coapMessage {
+	req := &coapMessage{code: code}
+	path, query, _ := strings.Cut(path, "?")
+	for _, segment := range strings.Split(strings.Trim(path, "/"), "/") {
+		if segment != "" {
+			req.addOption(coapOptionURIPath, []byte(segment))
+		}
+	}
+	if query != "" {
+		for _, q := range strings.Split(query, "&") {
+			req.addOption(coapOptionURIQuery, []byte(q))
+		}
+	}
+	return req
+}
+
+// addOption 添加选项
+func (m *coapMessage) addOption(number uint16, value []byte) {
+	m.options = append(m.options, coapOption{number: number, value: value})
+}
+
+// addQuery 添加一个 Uri-Query 选项，参数值按原样写入（可包含 & 与 =，无需转义）
+func (m *coapMessage) addQuery(key, value string) {
+	m.addOption(coapOptionURIQuery, []byte(key+"="+value))
+}
+
+// setContentFormat 设置内容格式
+func (m *coapMessage) setContentFormat(format uint16) {
+	var value []byte
+	if format > 0xff {
+		value = binary.BigEndian.AppendUint16(nil, format)
+	} else if format > 0 {
+		value = []byte{byte(format)}
+	}
+	m.addOption(coapOptionContentFormat, value)
+}
+
+// optionStrings 获取指定编号的所有选项值
+func (m *coapMessage) optionStrings(number uint16) []string {
+	var values []string
+	for _, opt := range m.options {
+		if opt.number == number {
+			values = append(values, string(opt.value))
+		}
+	}
+	return values
+}
+
+// coapCodeString 以 c.dd 形式表示响应码，如 2.05
+func coapCodeString(code byte) string {
+	return fmt.Sprintf("%d.%02d", code>>5, code&0x1f)
+}
+
+// parseCoAPCode 解析 c.dd 形式的响应码
+func parseCoAPCode(s string) (byte, error) {
+	var class, detail int
+	if _, err := fmt.Sscanf(s, "%d.%d", &class, &detail); err != nil || class < 0 || class > 7 || detail < 0 || detail > 31 {
+		return 0, fmt.Errorf("响应码格式无效: %q，格式应为 2.05", s)
+	}
+	return byte(class<<5 | detail), nil
+}
+
+// marshal 编码消息
+func (m *coapMessage) marshal() []byte {
+	out := []byte{1<<6 | m.typ<<4 | byte(len(m.token)), m.code, 0, 0}
+	binary.BigEndian.PutUint16(out[2:], m.messageID)
+	out = append(out, m.token...)
+
+	options := append([]coapOption(nil), m.options...)
+	sort.SliceStable(options, func(i, j int) bool { return options[i].number < options[j].number })
+	var last uint16
+	for _, opt := range options {
+		delta := int(opt.number - last)
+		last = opt.number
+		deltaNibble, deltaExt := coapOptionNibble(delta)
+		lengthNibble, lengthExt := coapOptionNibble(len(opt.value))
+		out = append(out, deltaNibble<<4|lengthNibble)
+		out = append(out, deltaExt...)
+		out = append(out, lengthExt...)
+		out = append(out, opt.value...)
+	}
+
+	if len(m.payload) > 0 {
+		out = append(out, 0xff)
+		out = append(out, m.payload...)
+	}
+	return out
+}
+
+// coapOptionNibble 编码选项差值或长度
+func coapOptionNibble(v int) (byte, []byte) {
+	switch {
+	case v < 13:
+		return byte(v), nil
+	case v < 269:
+		return 13, []byte{byte(v - 13)}
+	default:
+		return 14, binary.BigEndian.AppendUint16(nil, uint16(v-269))
+	}
+}
+
+// parseCoAPMessage 解码消息
+func parseCoAPMessage(data []byte) (*coapMessage, error) {
+	if len(data) < 4 || data[0]>>6 != 1 {
+		return nil, fmt.Errorf("CoAP 消息格式错误")
+	}
+	tkl := int(data[0] & 0x0f)
+	if tkl > 8 || len(data) < 4+tkl {
+		return nil, fmt.Errorf("CoAP 消息格式错误")
+	}
+	m := &coapMessage{
+		typ:       data[0] >> 4 & 0x03,
+		code:      data[1],
+		messageID: binary.BigEndian.Uint16(data[2:4]),
+		token:     data[4 : 4+tkl],
+	}
+
+	data = data[4+tkl:]
+	var number int
+	for len(data) > 0 {
+		if data[0] == 0xff {
+			m.payload = data[1:]
+			break
+		}
+		delta, length := int(data[0]>>4), int(data[0]&0x0f)
+		data = data[1:]
+		var err error
+		if delta, data, err = coapOptionValue(delta, data); err != nil {
+			return nil, err
+		}
+		if length, data, err = coapOptionValue(length, data); err != nil {
+			return nil, err
+		}
+		if len(data) < length {
+			return nil, fmt.Errorf("CoAP 选项格式错误")
+		}
+		number += delta
+		m.options = append(m.options, coapOption{number: uint16(number), value: data[:length]})
+		data = data[length:]
+	}
+	return m, nil
+}
+
+// coapOptionValue 解码选项差值或长度的扩展字节
+func coapOptionValue(nibble int, data []byte) (int, []byte, error) {
+	switch nibble {
+	case 13:
+		if len(data) < 1 {
+			return 0, nil, fmt.Errorf("CoAP 选项格式错误")
+		}
+		return int(data[0]) + 13, data[1:], nil
+	case 14:
+		if len(data) < 2 {
+			return 0, nil, fmt.Errorf("CoAP 选项格式错误")
+		}
+		return int(binary.BigEndian.Uint16(data)) + 269, data[2:], nil
+	case 15:
+		return 0, nil, fmt.Errorf("CoAP 选项格式错误")
+	}
+	return nibble, data, nil
+}
+
+// do 发送请求并等待响应
+// 可靠消息（CON）在 ACK_TIMEOUT 内未收到确认时按指数退避重传，收到空 ACK 后继续等待分离响应
+func (c *coapClient) do(ctx context.Context, req *coapMessage, confirmable bool) (*coapMessage, error) {
+	c.messageID++
+	req.messageID = c.messageID
+	req.typ = coapNonConfirmable
+	if confirmable {
+		req.typ = coapConfirmable
+	}
+	req.token = make([]byte, 4)
+	if _, err := rand.Read(req.token); err != nil {
+		return nil, err
+	}
+	data := req.marshal()
+
+	// 首次超时为 ACK_TIMEOUT 到 ACK_TIMEOUT*ACK_RANDOM_FACTOR 之间的随机值
+	jitter, _ := rand.Int(rand.Reader, big.NewInt(int64(float64(c.ackTimeout)*(coapRandomFactor-1))+1))
+	timeout := c.ackTimeout + time.Duration(jitter.Int64())
+	retries := 0
+	acked := !confirmable
+
+	if _, err := c.conn.Write(data); err != nil {
+		return nil, err
+	}
+	retransmitAt := time.Now().Add(timeout)
+	// 未设置截止时间时最多等待到所有重传结束后的一个 ACK_TIMEOUT 周期
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(timeout << (c.maxRetransmit + 1))
+	}
+	buf := make([]byte, coapMaxMessageSize*2)
+	for {
+		readDeadline := deadline
+		if !acked && retransmitAt.Before(deadline) {
+			readDeadline = retransmitAt
+		}
+		if err := c.conn.SetReadDeadline(readDeadline); err != nil {
+			return nil, err
+		}
+
+		n, err := c.conn.Read(buf)
+		if err != nil {
+			var netErr net.Error
+			if !errors.As(err, &netErr) || !netErr.Timeout() {
+				return nil, err
+			}
+			if acked || !time.Now().Before(deadline) {
+				return nil, fmt.Errorf("等待响应超时（重传 %d 次）", retries)
+			}
+			if retries >= c.maxRetransmit {
+				return nil, fmt.Errorf("重传 %d 次后仍未收到确认", retries)
+			}
+			retries++
+			c.retransmissions++
+			timeout *= 2
+			if _, err := c.conn.Write(data); err != nil {
+				return nil, err
+			}
+			retransmitAt = time.Now().Add(timeout)
+			continue
+		}
+
+		resp, err := parseCoAPMessage(buf[:n])
+		if err != nil {
+			continue
+		}
+		switch {
+		case resp.typ == coapReset && resp.messageID == req.messageID:
+			return nil, fmt.Errorf("服务器拒绝了请求（RST）")
+		case resp.typ == coapAcknowledgment && resp.messageID == req.messageID:
+			acked = true
+			if resp.code == 0 {
+				continue // 空 ACK，响应将单独发送
+			}
+			if string(resp.token) == string(req.token) {
+				return resp, nil
+			}
+		case (resp.typ == coapConfirmable || resp.typ == coapNonConfirmable) && string(resp.token) == string(req.token):
+			if resp.typ == coapConfirmable {
+				ack := &coapMessage{typ: coapAcknowledgment, messageID: resp.messageID}
+				if _, err := c.conn.Write(ack.marshal()); err != nil {
+					return nil, err
+				}
+			}
+			return resp, nil
+		case resp.typ == coapConfirmable && resp.code>>5 == 0 && resp.code != 0:
+			// 对端主动发来的请求（如 LwM2M 服务器在注册后读取资源），探针不提供资源，回复 4.04
+			ack := &coapMessage{typ: coapAcknowledgment, code: coapNotFound, messageID: resp.messageID, token: resp.token}
+			if _, err := c.conn.Write(ack.marshal()); err != nil {
+				return nil, err
+			}
+		}
+	}
+}
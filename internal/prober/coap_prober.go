@@ -0,0 +1,327 @@
+package prober
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"time"
+)
+
+// CoAPProber CoAP 探针，支持 UDP 与 DTLS（预共享密钥或证书）
+// 可用于 ThingsBoard CoAP 传输，如 POST /api/v1/{token}/telemetry
+type CoAPProber struct{}
+
+// NewCoAPProber 创建 CoAP 探针
+func NewCoAPProber() *CoAPProber {
+	return &CoAPProber{}
+}
+
+// Type 返回探针类型
+func (p *CoAPProber) Type() string {
+	return "coap"
+}
+
+// ConfigSchema 返回配置表单 schema
+func (p *CoAPProber) ConfigSchema() map[string]FieldSchema {
+	return map[string]FieldSchema{
+		"host": {
+			Type:        "string",
+			Label:       "主机地址",
+			Required:    true,
+			Placeholder: "localhost",
+		},
+		"port": {
+			Type:        "number",
+			Label:       "端口",
+			Required:    false,
+			Placeholder: "5683",
+			Hint:        "默认 UDP 为 5683，DTLS 为 5684",
+		},
+		"path": {
+			Type:         "string",
+			Label:        "资源路径",
+			Required:     false,
+			DefaultValue: "/.well-known/core",
+			Placeholder:  "/api/v1/$ACCESS_TOKEN/telemetry",
+			Hint:         "可包含查询参数，如 /rd?ep=test",
+		},
+		"method": {
+			Type:         "select",
+			Label:        "请求方法",
+			Required:     false,
+			DefaultValue: "GET",
+			Options: []Option{
+				{Value: "GET", Label: "GET"},
+				{Value: "POST", Label: "POST"},
+				{Value: "PUT", Label: "PUT"},
+				{Value: "DELETE", Label: "DELETE"},
+			},
+		},
+		"payload": {
+			Type:        "string",
+			Label:       "请求体",
+			Required:    false,
+			Placeholder: `{"rxprobe": 1}`,
+		},
+		"content_format": {
+			Type:         "select",
+			Label:        "内容格式",
+			Required:     false,
+			DefaultValue: "json",
+			Options: []Option{
+				{Value: "json", Label: "application/json"},
+				{Value: "text", Label: "text/plain"},
+			},
+		},
+		"confirmable": {
+			Type:         "boolean",
+			Label:        "可靠消息 (CON)",
+			Required:     false,
+			DefaultValue: true,
+			Hint:         "开启后未收到确认时按指数退避重传",
+		},
+		"expected_code": {
+			Type:        "string",
+			Label:       "期望响应码",
+			Required:    false,
+			Placeholder: "2.05",
+			Hint:        "为空时任意 2.xx 均视为成功",
+		},
+		"ack_timeout_ms": {
+			Type:         "number",
+			Label:        "确认超时（毫秒）",
+			Required:     false,
+			DefaultValue: 2000,
+			Hint:         "首次重传前的等待时间，之后每次翻倍",
+		},
+		"max_retransmit": {
+			Type:         "number",
+			Label:        "最大重传次数",
+			Required:     false,
+			DefaultValue: 4,
+		},
+		"security": {
+			Type:         "select",
+			Label:        "安全模式",
+			Required:     false,
+			DefaultValue: "none",
+			Options: []Option{
+				{Value: "none", Label: "无 (UDP)"},
+				{Value: "psk", Label: "DTLS 预共享密钥"},
+				{Value: "certificate", Label: "DTLS 证书"},
+			},
+		},
+		"psk_identity": {
+			Type:     "string",
+			Label:    "PSK 身份",
+			Required: false,
+			ShowWhen: map[string]any{"security": "psk"},
+		},
+		"psk_key": {
+			Type:     "password",
+			Label:    "PSK 密钥",
+			Required: false,
+			Hint:     "十六进制格式",
+			ShowWhen: map[string]any{"security": "psk"},
+		},
+		"insecure_skip_verify": {
+			Type:         "boolean",
+			Label:        "跳过证书验证",
+			Required:     false,
+			DefaultValue: false,
+			ShowWhen:     map[string]any{"security": "certificate"},
+		},
+	}
+}
+
+// Probe 执行探测
+func (p *CoAPProber) Probe(ctx context.Context, target Target) (*ProbeResult, error) {
+	start := time.Now()
+
+	fail := func(format string, args ...any) (*ProbeResult, error) {
+		return &ProbeResult{
+			Success:   false,
+			Latency:   time.Since(start),
+			Message:   fmt.Sprintf(format, args...),
+			CheckedAt: time.Now(),
+		}, nil
+	}
+
+	method := strings.ToUpper(getStringConfig(target.Config, "method", "GET"))
+	code, ok := coapMethods[method]
+	if !ok {
+		return fail("不支持的请求方法: %s", method)
+	}
+	var expected byte
+	if s := getStringConfig(target.Config, "expected_code", ""); s != "" {
+		var err error
+		if expected, err = parseCoAPCode(s); err != nil {
+			return fail("%v", err)
+		}
+	}
+
+	port := 5683
+	if getStringConfig(target.Config, "security", "none") != "none" {
+		port = 5684
+	}
+	session, err := dialCoAP(ctx, target, getIntConfig(target.Config, "port", port))
+	if err != nil {
+		return fail("%v", err)
+	}
+	defer session.Close()
+
+	req := newCoAPRequest(code, getStringConfig(target.Config, "path", "/.well-known/core"))
+	if payload := getStringConfig(target.Config, "payload", ""); payload != "" && code != coapGET && code != coapDELETE {
+		req.payload = []byte(payload)
+		if getStringConfig(target.Config, "content_format", "json") == "text" {
+			req.setContentFormat(coapFormatText)
+		} else {
+			req.setContentFormat(coapFormatJSON)
+		}
+	}
+
+	requestStart := time.Now()
+	resp, err := session.client.do(ctx, req, getBoolConfig(target.Config, "confirmable", true))
+	rtt := time.Since(requestStart)
+	metrics := session.metrics()
+	if err != nil {
+		return &ProbeResult{
+			Success:   false,
+			Latency:   time.Since(start),
+			Message:   fmt.Sprintf("CoAP %s 请求失败: %v", method, err),
+			Metrics:   metrics,
+			CheckedAt: time.Now(),
+		}, nil
+	}
+	metrics["response_code"] = coapCodeString(resp.code)
+	metrics["rtt_ms"] = rtt.Milliseconds()
+	metrics["payload_bytes"] = len(resp.payload)
+
+	success := resp.code>>5 == 2
+	if expected != 0 {
+		success = resp.code == expected
+	}
+	message := fmt.Sprintf("CoAP %s 响应 %s，耗时 %dms", method, coapCodeString(resp.code), rtt.Milliseconds())
+	if !success {
+		message = fmt.Sprintf("CoAP %s 响应码异常: %s", method, coapCodeString(resp.code))
+		if expected != 0 {
+			message += fmt.Sprintf("，期望 %s", coapCodeString(expected))
+		}
+		if len(resp.payload) > 0 && len(resp.payload) <= 256 {
+			message += fmt.Sprintf("（%s）", resp.payload)
+		}
+	}
+
+	return &ProbeResult{
+		Success:   success,
+		Latency:   time.Since(start),
+		Message:   message,
+		Metrics:   metrics,
+		CheckedAt: time.Now(),
+	}, nil
+}
+
+// Validate 验证目标配置
+func (p *CoAPProber) Validate(target Target) error {
+	if _, ok := target.Config["host"]; !ok {
+		return fmt.Errorf("缺少必填字段: host")
+	}
+	method := strings.ToUpper(getStringConfig(target.Config, "method", "GET"))
+	if _, ok := coapMethods[method]; !ok {
+		return fmt.Errorf("不支持的请求方法: %s", method)
+	}
+	if s := getStringConfig(target.Config, "expected_code", ""); s != "" {
+		if _, err := parseCoAPCode(s); err != nil {
+			return err
+		}
+	}
+	return validateCoAPSecurity(target.Config)
+}
+
+// coapSession CoAP 会话：底层连接与客户端
+type coapSession struct {
+	conn      io.Closer
+	client    *coapClient
+	security  string
+	handshake time.Duration
+}
+
+// dialCoAP 按目标配置的主机与安全模式建立到指定端口的 CoAP 会话（UDP 或 DTLS），CoAP 与 LwM2M 探针共用
+func dialCoAP(ctx context.Context, target Target, port int) (*coapSession, error) {
+	security := getStringConfig(target.Config, "security", "none")
+	host := getStringConfig(target.Config, "host", "localhost")
+	addr := net.JoinHostPort(host, fmt.Sprint(port))
+
+	var dialer net.Dialer
+	udp, err := dialer.DialContext(ctx, "udp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("连接失败: %v", err)
+	}
+
+	session := &coapSession{conn: udp, security: security}
+	var conn coapDatagramConn = udp
+	if security != "none" {
+		config := dtlsConfig{
+			ServerName:         host,
+			InsecureSkipVerify: getBoolConfig(target.Config, "insecure_skip_verify", false),
+		}
+		if security == "psk" {
+			config.PSKIdentity = getStringConfig(target.Config, "psk_identity", "")
+			if config.PSK, err = hex.DecodeString(getStringConfig(target.Config, "psk_key", "")); err != nil {
+				udp.Close()
+				return nil, fmt.Errorf("PSK 密钥不是有效的十六进制: %v", err)
+			}
+		}
+		handshakeStart := time.Now()
+		secure, err := dialDTLS(ctx, udp, config)
+		if err != nil {
+			udp.Close()
+			return nil, fmt.Errorf("DTLS 握手失败: %v", err)
+		}
+		session.handshake = time.Since(handshakeStart)
+		session.conn = secure
+		conn = secure
+	}
+
+	ackTimeout := time.Duration(getIntConfig(target.Config, "ack_timeout_ms", 2000)) * time.Millisecond
+	session.client = newCoAPClient(conn, ackTimeout, getIntConfig(target.Config, "max_retransmit", coapDefaultRetries))
+	return session, nil
+}
+
+// metrics 会话指标：安全模式、DTLS 握手耗时与累计重传次数
+func (s *coapSession) metrics() map[string]any {
+	metrics := map[string]any{
+		"security":        s.security,
+		"retransmissions": s.client.retransmissions,
+	}
+	if s.security != "none" {
+		metrics["handshake_ms"] = s.handshake.Milliseconds()
+	}
+	return metrics
+}
+
+// Close 关闭会话
+func (s *coapSession) Close() error {
+	return s.conn.Close()
+}
+
+// validateCoAPSecurity 验证 CoAP 安全模式配置
+func validateCoAPSecurity(config map[string]any) error {
+	switch security := getStringConfig(config, "security", "none"); security {
+	case "none", "certificate":
+	case "psk":
+		if getStringConfig(config, "psk_identity", "") == "" {
+			return fmt.Errorf("缺少必填字段: psk_identity")
+		}
+		key, err := hex.DecodeString(getStringConfig(config, "psk_key", ""))
+		if err != nil || len(key) == 0 {
+			return fmt.Errorf("psk_key 必须是非空的十六进制字符串")
+		}
+	default:
+		return fmt.Errorf("不支持的安全模式: %s", security)
+	}
+	return nil
+}
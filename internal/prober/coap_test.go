@@ -0,0 +1,256 @@
+package prober
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// coapTestServer 本地 UDP CoAP 测试服务器，handler 按收到的第 n 条消息（从 1 开始）返回需要回复的消息
+type coapTestServer struct {
+	conn *net.UDPConn
+
+	mu       sync.Mutex
+	received []*coapMessage
+}
+
+func newCoAPTestServer(t *testing.T, handler func(msg *coapMessage, n int) []*coapMessage) (*coapTestServer, *net.UDPConn) {
+	t.Helper()
+	conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &coapTestServer{conn: conn}
+	go func() {
+		buf := make([]byte, coapMaxMessageSize)
+		for {
+			n, addr, err := conn.ReadFromUDP(buf)
+			if err != nil {
+				return
+			}
+			msg, err := parseCoAPMessage(append([]byte{}, buf[:n]...))
+			if err != nil {
+				continue
+			}
+			s.mu.Lock()
+			s.received = append(s.received, msg)
+			count := len(s.received)
+			s.mu.Unlock()
+			for _, reply := range handler(msg, count) {
+				conn.WriteToUDP(reply.marshal(), addr)
+			}
+		}
+	}()
+
+	client, err := net.DialUDP("udp", nil, conn.LocalAddr().(*net.UDPAddr))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		client.Close()
+		conn.Close()
+	})
+	return s, client
+}
+
+// messages 返回服务器已收到的消息
+func (s *coapTestServer) messages() []*coapMessage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]*coapMessage{}, s.received...)
+}
+
+// piggybacked 生成携带响应的 ACK
+func piggybacked(req *coapMessage, code byte, payload string) *coapMessage {
+	return &coapMessage{typ: coapAcknowledgment, code: code, messageID: req.messageID, token: req.token, payload: []byte(payload)}
+}
+
+func testContext(t *testing.T) context.Context {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	t.Cleanup(cancel)
+	return ctx
+}
+
+func TestCoAPMessageRoundTrip(t *testing.T) {
+	req := newCoAPRequest(coapPOST, "/rd/abc?ep=test&lt=300")
+	req.setContentFormat(coapFormatLinkFmt)
+	req.addOption(2048, bytes.Repeat([]byte{'x'}, 300)) // 差值与长度都需要 2 字节扩展
+	req.addOption(coapOptionLocationPath, bytes.Repeat([]byte{'y'}, 20))
+	req.typ = coapConfirmable
+	req.messageID = 0x1234
+	req.token = []byte{1, 2, 3, 4}
+	req.payload = []byte("</1/0>")
+
+	msg, err := parseCoAPMessage(req.marshal())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if msg.typ != coapConfirmable || msg.code != coapPOST || msg.messageID != 0x1234 || !bytes.Equal(msg.token, req.token) {
+		t.Fatalf("消息头不一致: %+v", msg)
+	}
+	if got := strings.Join(msg.optionStrings(coapOptionURIPath), "/"); got != "rd/abc" {
+		t.Fatalf("Uri-Path = %q", got)
+	}
+	if got := strings.Join(msg.optionStrings(coapOptionURIQuery), "&"); got != "ep=test&lt=300" {
+		t.Fatalf("Uri-Query = %q", got)
+	}
+	if got := msg.optionStrings(2048); len(got) != 1 || len(got[0]) != 300 {
+		t.Fatalf("扩展选项解析错误: %d", len(got))
+	}
+	if got := msg.optionStrings(coapOptionLocationPath); len(got) != 1 || len(got[0]) != 20 {
+		t.Fatalf("Location-Path 解析错误: %v", got)
+	}
+	if string(msg.payload) != "</1/0>" {
+		t.Fatalf("payload = %q", msg.payload)
+	}
+
+	if _, err := parseCoAPMessage([]byte{0x40, 0x01}); err == nil {
+		t.Fatal("过短消息应解析失败")
+	}
+	if _, err := parseCoAPMessage([]byte{0x40, 0x01, 0, 1, 0xf0}); err == nil {
+		t.Fatal("保留的选项差值 15 应解析失败")
+	}
+}
+
+func TestCoAPCodeString(t *testing.T) {
+	code, err := parseCoAPCode("2.05")
+	if err != nil || code != 0x45 || coapCodeString(code) != "2.05" {
+		t.Fatalf("parseCoAPCode(2.05) = %#x, %v", code, err)
+	}
+	for _, s := range []string{"", "abc", "8.00", "2.32"} {
+		if _, err := parseCoAPCode(s); err == nil {
+			t.Fatalf("parseCoAPCode(%q) 应返回错误", s)
+		}
+	}
+}
+
+func TestCoAPPiggybackedResponse(t *testing.T) {
+	_, conn := newCoAPTestServer(t, func(msg *coapMessage, n int) []*coapMessage {
+		return []*coapMessage{piggybacked(msg, 0x45, "ok")}
+	})
+	client := newCoAPClient(conn, 50*time.Millisecond, 2)
+	resp, err := client.do(testContext(t), newCoAPRequest(coapGET, "/.well-known/core"), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.code != 0x45 || string(resp.payload) != "ok" || client.retransmissions != 0 {
+		t.Fatalf("响应 %s %q，重传 %d 次", coapCodeString(resp.code), resp.payload, client.retransmissions)
+	}
+}
+
+func TestCoAPRetransmit(t *testing.T) {
+	// 丢弃前两次发送，第三次才确认
+	server, conn := newCoAPTestServer(t, func(msg *coapMessage, n int) []*coapMessage {
+		if n < 3 {
+			return nil
+		}
+		return []*coapMessage{piggybacked(msg, coapChanged, "")}
+	})
+	client := newCoAPClient(conn, 20*time.Millisecond, 4)
+	resp, err := client.do(testContext(t), newCoAPRequest(coapPOST, "/telemetry"), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.code != coapChanged || client.retransmissions != 2 {
+		t.Fatalf("响应 %s，重传 %d 次，期望 2.04 与 2 次", coapCodeString(resp.code), client.retransmissions)
+	}
+	msgs := server.messages()
+	if len(msgs) != 3 || msgs[0].messageID != msgs[2].messageID {
+		t.Fatalf("重传应使用相同的消息 ID: %d 条消息", len(msgs))
+	}
+}
+
+func TestCoAPRetransmitExhausted(t *testing.T) {
+	server, conn := newCoAPTestServer(t, func(msg *coapMessage, n int) []*coapMessage {
+		return nil
+	})
+	client := newCoAPClient(conn, 10*time.Millisecond, 2)
+	if _, err := client.do(testContext(t), newCoAPRequest(coapGET, "/"), true); err == nil {
+		t.Fatal("服务器无响应时应返回错误")
+	}
+	if got := len(server.messages()); got != 3 {
+		t.Fatalf("服务器收到 %d 条消息，期望首次发送加 2 次重传", got)
+	}
+}
+
+func TestCoAPSeparateResponse(t *testing.T) {
+	const responseID = 0x7777
+	server, conn := newCoAPTestServer(t, func(msg *coapMessage, n int) []*coapMessage {
+		if msg.typ != coapConfirmable || msg.code == 0 {
+			return nil
+		}
+		// 先回复空 ACK，再以 CON 单独发送响应
+		return []*coapMessage{
+			{typ: coapAcknowledgment, messageID: msg.messageID},
+			{typ: coapConfirmable, code: 0x45, messageID: responseID, token: msg.token, payload: []byte("later")},
+		}
+	})
+	client := newCoAPClient(conn, 50*time.Millisecond, 2)
+	resp, err := client.do(testContext(t), newCoAPRequest(coapGET, "/slow"), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(resp.payload) != "later" {
+		t.Fatalf("payload = %q", resp.payload)
+	}
+
+	// 客户端必须确认单独发送的 CON 响应
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		for _, msg := range server.messages() {
+			if msg.typ == coapAcknowledgment && msg.messageID == responseID && msg.code == 0 {
+				return
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatal("客户端未确认单独发送的响应")
+}
+
+func TestCoAPReset(t *testing.T) {
+	_, conn := newCoAPTestServer(t, func(msg *coapMessage, n int) []*coapMessage {
+		return []*coapMessage{{typ: coapReset, messageID: msg.messageID}}
+	})
+	client := newCoAPClient(conn, 50*time.Millisecond, 2)
+	_, err := client.do(testContext(t), newCoAPRequest(coapGET, "/"), true)
+	if err == nil || !strings.Contains(err.Error(), "RST") {
+		t.Fatalf("收到 RST 时错误 = %v", err)
+	}
+}
+
+func TestCoAPUnsolicitedRequest(t *testing.T) {
+	// 服务器在响应前主动发来请求（如 LwM2M 服务器读取资源），客户端应回复 4.04 并继续等待
+	const serverRequestID = 0x4242
+	server, conn := newCoAPTestServer(t, func(msg *coapMessage, n int) []*coapMessage {
+		if msg.typ != coapConfirmable {
+			return nil
+		}
+		read := newCoAPRequest(coapGET, "/3/0")
+		read.typ = coapConfirmable
+		read.messageID = serverRequestID
+		read.token = []byte{9}
+		return []*coapMessage{read, piggybacked(msg, coapCreated, "")}
+	})
+	client := newCoAPClient(conn, 50*time.Millisecond, 2)
+	resp, err := client.do(testContext(t), newCoAPRequest(coapPOST, "/rd?ep=test"), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.code != coapCreated {
+		t.Fatalf("响应 %s", coapCodeString(resp.code))
+	}
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		for _, msg := range server.messages() {
+			if msg.typ == coapAcknowledgment && msg.messageID == serverRequestID && msg.code == coapNotFound {
+				return
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatal("客户端未以 4.04 回复服务器请求")
+}
@@ -30,7 +30,7 @@ func (p *CPUProber) ConfigSchema() map[string]FieldSchema {
 			Required:     true,
 			DefaultValue: 80.0,
 			Placeholder:  "80",
-			Hint:         "当 CPU 占用率超过此值时触发告警，范围: 0-100",
+			Hint:         "当 CPU 占用率超过此值时判定为降级（开启降级告警时按警告级别告警），范围: 0-100",
 		},
 		"sample_duration": {
 			Type:         "number",
@@ -108,27 +108,20 @@ func (p *CPUProber) Probe(ctx context.Context, target Target) (*ProbeResult, err
 		metrics["per_cpu_percent"] = perCpuPercent
 	}
 
-	// 判断是否超过告警阈值
+	// 超过告警阈值时主机仍可用，判定为降级
+	message := fmt.Sprintf("CPU 占用率: %.2f%% (阈值: %.1f%%)", cpuPercent, threshold)
+	var warnings []string
 	if cpuPercent > threshold {
-		message := fmt.Sprintf("CPU 占用率 %.2f%% 超过告警阈值 %.1f%%", cpuPercent, threshold)
-		return &ProbeResult{
-			Success:   false,
-			Latency:   time.Since(start),
-			Message:   message,
-			Metrics:   metrics,
-			CheckedAt: time.Now(),
-		}, nil
+		warnings = append(warnings, fmt.Sprintf("CPU 占用率 %.2f%% 超过告警阈值 %.1f%%", cpuPercent, threshold))
 	}
 
-	// 正常状态
-	message := fmt.Sprintf("CPU 占用率: %.2f%% (阈值: %.1f%%)", cpuPercent, threshold)
-
 	return &ProbeResult{
 		Success:   true,
 		Latency:   time.Since(start),
 		Message:   message,
 		Metrics:   metrics,
 		CheckedAt: time.Now(),
+		Warnings:  warnings,
 	}, nil
 }
 
@@ -0,0 +1,68 @@
+package prober
+
+import (
+	"context"
+	"crypto/x509"
+	"fmt"
+	"net"
+
+	"github.com/pion/dtls/v2"
+)
+
+// dtlsConfig DTLS 客户端配置，PSK 非空时使用预共享密钥模式，否则使用证书模式
+type dtlsConfig struct {
+	PSKIdentity        string
+	PSK                []byte
+	ServerName         string         // 证书模式下校验服务器证书的主机名
+	InsecureSkipVerify bool           // 证书模式下跳过服务器证书校验
+	RootCAs            *x509.CertPool // 证书模式下校验服务器证书的根证书，为空时使用系统根证书
+}
+
+// pion/dtls 的默认套件不包含 CCM_8 与 PSK 套件，需显式列出
+var (
+	dtlsPSKCipherSuites = []dtls.CipherSuiteID{
+		dtls.TLS_PSK_WITH_AES_128_CCM_8,
+		dtls.TLS_PSK_WITH_AES_128_CCM,
+		dtls.TLS_PSK_WITH_AES_128_GCM_SHA256,
+		dtls.TLS_PSK_WITH_AES_128_CBC_SHA256,
+	}
+	dtlsCertCipherSuites = []dtls.CipherSuiteID{
+		dtls.TLS_ECDHE_ECDSA_WITH_AES_128_CCM_8,
+		dtls.TLS_ECDHE_ECDSA_WITH_AES_128_CCM,
+		dtls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256,
+		dtls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,
+		dtls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384,
+		dtls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384,
+	}
+)
+
+// dialDTLS 在已连接的 UDP 连接上完成 DTLS 1.2 握手
+// 预共享密钥模式协商 TLS_PSK_WITH_AES_128_CCM_8 等 PSK 套件，证书模式协商 TLS_ECDHE_ECDSA_WITH_AES_128_CCM_8 等 ECDHE 套件
+// （包含 CoAP/LwM2M 规定必须支持的套件，RFC 7252 §9.1.3）；不发送客户端证书
+func dialDTLS(ctx context.Context, conn net.Conn, config dtlsConfig) (*dtls.Conn, error) {
+	cfg := &dtls.Config{
+		ServerName:           config.ServerName,
+		InsecureSkipVerify:   config.InsecureSkipVerify,
+		RootCAs:              config.RootCAs,
+		ExtendedMasterSecret: dtls.RequestExtendedMasterSecret,
+	}
+	// 主机为 IP 地址时 pion/dtls 不发送 SNI，也不校验证书中的 IP，需要单独校验
+	if !config.InsecureSkipVerify && net.ParseIP(config.ServerName) != nil {
+		host := config.ServerName
+		cfg.VerifyPeerCertificate = func(_ [][]byte, chains [][]*x509.Certificate) error {
+			if len(chains) == 0 || len(chains[0]) == 0 {
+				return fmt.Errorf("服务器证书未通过校验")
+			}
+			return chains[0][0].VerifyHostname(host)
+		}
+	}
+	if len(config.PSK) > 0 {
+		psk := config.PSK
+		cfg.PSK = func([]byte) ([]byte, error) { return psk, nil }
+		cfg.PSKIdentityHint = []byte(config.PSKIdentity)
+		cfg.CipherSuites = dtlsPSKCipherSuites
+	} else {
+		cfg.CipherSuites = dtlsCertCipherSuites
+	}
+	return dtls.ClientWithContext(ctx, conn, cfg)
+}
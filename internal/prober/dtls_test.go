@@ -0,0 +1,255 @@
+package prober
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/pion/dtls/v2"
+)
+
+// testCA 测试用的证书颁发机构
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pool *x509.CertPool
+}
+
+func newTestCA(t *testing.T) *testCA {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "rxprobe test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pool := x509.NewCertPool()
+	pool.AddCert(cert)
+	return &testCA{cert: cert, key: key, pool: pool}
+}
+
+// issue 签发 localhost / 127.0.0.1 的服务器证书
+func (ca *testCA) issue(t *testing.T) tls.Certificate {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "localhost"},
+		DNSNames:     []string{"localhost"},
+		IPAddresses:  []net.IP{net.IPv4(127, 0, 0, 1)},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, &key.PublicKey, ca.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+}
+
+// newDTLSTestServer 启动基于 pion/dtls 的回显服务器，返回连接到该服务器的 UDP 客户端与服务端握手结果
+func newDTLSTestServer(t *testing.T, config *dtls.Config) (net.Conn, chan error) {
+	t.Helper()
+	listener, err := dtls.Listen("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)}, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan error, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			done <- err
+			return
+		}
+		defer conn.Close()
+		buf := make([]byte, 1024)
+		n, err := conn.Read(buf)
+		if err == nil {
+			_, err = conn.Write(buf[:n])
+		}
+		done <- err
+	}()
+
+	client, err := net.DialUDP("udp", nil, listener.Addr().(*net.UDPAddr))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		client.Close()
+		listener.Close()
+	})
+	return client, done
+}
+
+// dtlsEcho 握手后发送数据并校验回显
+func dtlsEcho(t *testing.T, conn *dtls.Conn, serverDone chan error) {
+	t.Helper()
+	defer conn.Close()
+	if _, err := conn.Write([]byte("ping")); err != nil {
+		t.Fatal(err)
+	}
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	buf := make([]byte, 64)
+	n, err := conn.Read(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(buf[:n]) != "ping" {
+		t.Fatalf("回显 = %q", buf[:n])
+	}
+	if err := <-serverDone; err != nil {
+		t.Fatalf("服务端: %v", err)
+	}
+}
+
+func pskServerConfig(psk []byte) *dtls.Config {
+	return &dtls.Config{
+		PSK:          func([]byte) ([]byte, error) { return psk, nil },
+		CipherSuites: []dtls.CipherSuiteID{dtls.TLS_PSK_WITH_AES_128_CCM_8},
+	}
+}
+
+func TestDTLSPSKHandshake(t *testing.T) {
+	psk := []byte("0123456789abcdef")
+	udp, serverDone := newDTLSTestServer(t, pskServerConfig(psk))
+
+	conn, err := dialDTLS(testContext(t), udp, dtlsConfig{PSKIdentity: "client", PSK: psk})
+	if err != nil {
+		t.Fatalf("握手失败: %v", err)
+	}
+	dtlsEcho(t, conn, serverDone)
+}
+
+func TestDTLSPSKMismatch(t *testing.T) {
+	udp, _ := newDTLSTestServer(t, pskServerConfig([]byte("server-key")))
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	if _, err := dialDTLS(ctx, udp, dtlsConfig{PSKIdentity: "client", PSK: []byte("client-key")}); err == nil {
+		t.Fatal("PSK 不一致时握手应失败")
+	}
+}
+
+func TestDTLSCertificate(t *testing.T) {
+	ca := newTestCA(t)
+	cert := ca.issue(t)
+
+	// 服务器证书的公钥与签名私钥不匹配，ServerKeyExchange 的签名无法通过校验
+	otherKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	badSignature := tls.Certificate{Certificate: cert.Certificate, PrivateKey: otherKey}
+
+	tests := []struct {
+		name    string
+		cert    tls.Certificate
+		config  dtlsConfig
+		wantErr bool
+	}{
+		{name: "证书链与主机名有效", cert: cert, config: dtlsConfig{ServerName: "localhost", RootCAs: ca.pool}},
+		{name: "IP 地址匹配证书", cert: cert, config: dtlsConfig{ServerName: "127.0.0.1", RootCAs: ca.pool}},
+		{name: "主机名不匹配", cert: cert, config: dtlsConfig{ServerName: "example.com", RootCAs: ca.pool}, wantErr: true},
+		{name: "IP 地址不匹配", cert: cert, config: dtlsConfig{ServerName: "127.0.0.2", RootCAs: ca.pool}, wantErr: true},
+		{name: "不受信任的颁发机构", cert: newTestCA(t).issue(t), config: dtlsConfig{ServerName: "localhost", RootCAs: ca.pool}, wantErr: true},
+		{name: "签名无效", cert: badSignature, config: dtlsConfig{ServerName: "localhost", RootCAs: ca.pool}, wantErr: true},
+		{name: "跳过校验", cert: newTestCA(t).issue(t), config: dtlsConfig{ServerName: "example.com", InsecureSkipVerify: true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			udp, serverDone := newDTLSTestServer(t, &dtls.Config{
+				Certificates: []tls.Certificate{tt.cert},
+				CipherSuites: []dtls.CipherSuiteID{dtls.TLS_ECDHE_ECDSA_WITH_AES_128_CCM_8},
+			})
+			ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+			defer cancel()
+			conn, err := dialDTLS(ctx, udp, tt.config)
+			if tt.wantErr {
+				if err == nil {
+					conn.Close()
+					t.Fatal("握手应失败")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("握手失败: %v", err)
+			}
+			dtlsEcho(t, conn, serverDone)
+		})
+	}
+}
+
+func TestCoAPOverDTLS(t *testing.T) {
+	psk := []byte("0123456789abcdef")
+	listener, err := dtls.Listen("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)}, pskServerConfig(psk))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		buf := make([]byte, coapMaxMessageSize)
+		n, err := conn.Read(buf)
+		if err != nil {
+			return
+		}
+		if req, err := parseCoAPMessage(append([]byte{}, buf[:n]...)); err == nil {
+			conn.Write(piggybacked(req, 0x45, "secure").marshal())
+		}
+	}()
+
+	port := listener.Addr().(*net.UDPAddr).Port
+	session, err := dialCoAP(testContext(t), Target{Config: map[string]any{
+		"host":         "127.0.0.1",
+		"security":     "psk",
+		"psk_identity": "client",
+		"psk_key":      "30313233343536373839616263646566",
+	}}, port)
+	if err != nil {
+		t.Fatalf("建立 CoAP 会话失败: %v", err)
+	}
+	defer session.Close()
+
+	resp, err := session.client.do(testContext(t), newCoAPRequest(coapGET, "/test"), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.code != 0x45 || string(resp.payload) != "secure" {
+		t.Fatalf("响应 = %s %q", coapCodeString(resp.code), resp.payload)
+	}
+	if session.metrics()["handshake_ms"] == nil {
+		t.Fatalf("缺少 DTLS 握手耗时指标: %v", session.metrics())
+	}
+}
@@ -69,6 +69,13 @@ func (p *HTTPProber) ConfigSchema() map[string]FieldSchema {
 			Placeholder: "ok",
 			Hint:        "响应体需包含此字符串",
 		},
+		"slow_threshold_ms": {
+			Type:        "number",
+			Label:       "慢响应阈值（毫秒）",
+			Required:    false,
+			Placeholder: "0",
+			Hint:        "响应成功但耗时超过此值时判定为降级，0 表示不检查",
+		},
 		"insecure_skip_verify": {
 			Type:         "boolean",
 			Label:        "跳过证书验证",
@@ -184,6 +191,12 @@ func (p *HTTPProber) Probe(ctx context.Context, target Target) (*ProbeResult, er
 		}, nil
 	}
 
+	// 响应成功但耗时过长时判定为降级
+	slowThreshold := getIntConfig(target.Config, "slow_threshold_ms", 0)
+	if slowThreshold > 0 && latency > time.Duration(slowThreshold)*time.Millisecond {
+		warnings = append(warnings, fmt.Sprintf("响应耗时 %dms 超过慢响应阈值 %dms", latency.Milliseconds(), slowThreshold))
+	}
+
 	message := fmt.Sprintf("HTTP %d %s", resp.StatusCode, http.StatusText(resp.StatusCode))
 
 	return &ProbeResult{
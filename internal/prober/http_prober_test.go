@@ -0,0 +1,54 @@
+package prober
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHTTPProberSlowThreshold(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(50 * time.Millisecond)
+		if r.URL.Path == "/missing" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+
+	tests := []struct {
+		name        string
+		path        string
+		config      map[string]any
+		wantOutcome Outcome
+	}{
+		{"未配置慢响应阈值", "/", map[string]any{}, OutcomeOK},
+		{"阈值为 0 时不检查", "/", map[string]any{"slow_threshold_ms": 0}, OutcomeOK},
+		{"耗时未超过阈值", "/", map[string]any{"slow_threshold_ms": 10000}, OutcomeOK},
+		{"耗时超过阈值时判定为降级", "/", map[string]any{"slow_threshold_ms": 10.0}, OutcomeDegraded},
+		{"状态码不符合时判定为故障而不是降级", "/missing", map[string]any{"slow_threshold_ms": 10}, OutcomeDown},
+	}
+	p := NewHTTPProber()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := map[string]any{"url": srv.URL + tt.path}
+			for k, v := range tt.config {
+				config[k] = v
+			}
+			result, err := p.Probe(context.Background(), Target{Timeout: 5 * time.Second, Config: config})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := result.Outcome(); got != tt.wantOutcome {
+				t.Fatalf("Outcome() = %q, want %q（%s）", got, tt.wantOutcome, result.Summary())
+			}
+			if tt.wantOutcome == OutcomeDegraded && !strings.Contains(result.Summary(), "超过慢响应阈值 10ms") {
+				t.Fatalf("降级摘要中缺少慢响应警告: %q", result.Summary())
+			}
+		})
+	}
+}
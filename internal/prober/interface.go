@@ -3,17 +3,47 @@ package prober
 
 import (
 	"context"
+	"strings"
 	"time"
 )
 
 // ProbeResult 探测结果
 type ProbeResult struct {
-	Success   bool           `json:"success"`
-	Latency   time.Duration  `json:"latency"`
-	Message   string         `json:"message"`
-	Metrics   map[string]any `json:"metrics,omitempty"`
-	Warnings  []string       `json:"warnings,omitempty"`
-	CheckedAt time.Time      `json:"checked_at"`
+	Success      bool           `json:"success"`
+	Latency      time.Duration  `json:"latency"`
+	Message      string         `json:"message"`
+	Metrics      map[string]any `json:"metrics,omitempty"`
+	Warnings     []string       `json:"warnings,omitempty"`
+	CheckedAt    time.Time      `json:"checked_at"`
+	RuleSeverity string         `json:"rule_severity,omitempty"` // 命中的指标阈值规则中最高的告警级别，由调度器填写
+}
+
+// Outcome 探测结论
+type Outcome string
+
+const (
+	OutcomeOK       Outcome = "ok"       // 正常
+	OutcomeDegraded Outcome = "degraded" // 降级：服务可用，但存在警告（如响应慢、部分节点离线）
+	OutcomeDown     Outcome = "down"     // 故障
+)
+
+// Outcome 获取探测结论：失败为故障，成功但带有警告为降级
+func (r *ProbeResult) Outcome() Outcome {
+	if !r.Success {
+		return OutcomeDown
+	}
+	if len(r.Warnings) > 0 {
+		return OutcomeDegraded
+	}
+	return OutcomeOK
+}
+
+// Summary 探测结果摘要：降级时在消息后附加警告内容
+func (r *ProbeResult) Summary() string {
+	if r.Outcome() != OutcomeDegraded {
+		return r.Message
+	}
+	return r.Message + "（" + strings.Join(r.Warnings, "；") + "）"
 }
 
 // Target 探测目标
@@ -41,7 +71,7 @@ type Prober interface {
 
 // FieldSchema 表单字段 schema
 type FieldSchema struct {
-	Type         string         `json:"type"`          // string, number, password, boolean, select
+	Type         string         `json:"type"`          // string, number, password, boolean, select, textarea
 	Label        string         `json:"label"`         // 显示标签
 	Required     bool           `json:"required"`      // 是否必填
 	Placeholder  string         `json:"placeholder"`   // 占位符
@@ -81,6 +111,10 @@ func NewFactory() *Factory {
 	f.Register(NewTCPProber())
 	f.Register(NewPingProber())
 	f.Register(NewCPUProber())
+	f.Register(NewMQTTProber())
+	f.Register(NewCoAPProber())
+	f.Register(NewLwM2MProber())
+	f.Register(NewZooKeeperProber())
 	return f
 }
 
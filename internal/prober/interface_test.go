@@ -0,0 +1,28 @@
+package prober
+
+import "testing"
+
+func TestProbeResultOutcome(t *testing.T) {
+	tests := []struct {
+		name        string
+		result      ProbeResult
+		wantOutcome Outcome
+		wantSummary string
+	}{
+		{"成功", ProbeResult{Success: true, Message: "HTTP 200 OK"}, OutcomeOK, "HTTP 200 OK"},
+		{"成功但带有警告为降级", ProbeResult{Success: true, Message: "HTTP 200 OK", Warnings: []string{"响应慢"}}, OutcomeDegraded, "HTTP 200 OK（响应慢）"},
+		{"多条警告", ProbeResult{Success: true, Message: "集群可用", Warnings: []string{"a", "b"}}, OutcomeDegraded, "集群可用（a；b）"},
+		{"失败", ProbeResult{Success: false, Message: "connection refused"}, OutcomeDown, "connection refused"},
+		{"失败时不附加警告", ProbeResult{Success: false, Message: "timeout", Warnings: []string{"响应慢"}}, OutcomeDown, "timeout"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.result.Outcome(); got != tt.wantOutcome {
+				t.Fatalf("Outcome() = %q, want %q", got, tt.wantOutcome)
+			}
+			if got := tt.result.Summary(); got != tt.wantSummary {
+				t.Fatalf("Summary() = %q, want %q", got, tt.wantSummary)
+			}
+		})
+	}
+}
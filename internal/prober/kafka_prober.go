@@ -32,6 +32,13 @@ func (p *KafkaProber) ConfigSchema() map[string]FieldSchema {
 			Placeholder: "kafka1:9092,kafka2:9092,kafka3:9092",
 			Hint:        "多个地址用逗号分隔",
 		},
+		"expected_brokers": {
+			Type:        "number",
+			Label:       "期望 Broker 数量",
+			Required:    false,
+			Placeholder: "3",
+			Hint:        "在线 Broker 少于此值时判定为降级，默认为配置的地址数量",
+		},
 		"sasl_enabled": {
 			Type:         "boolean",
 			Label:        "启用 SASL 认证",
@@ -145,10 +152,19 @@ func (p *KafkaProber) Probe(ctx context.Context, target Target) (*ProbeResult, e
 		}, nil
 	}
 
+	// 集群可用但部分 Broker 离线时判定为降级
+	var warnings []string
+	expectedBrokers := getIntConfig(target.Config, "expected_brokers", len(brokers))
+	if len(brokerList) < expectedBrokers {
+		warnings = append(warnings, fmt.Sprintf("在线 Broker 数量 %d 少于期望的 %d 个", len(brokerList), expectedBrokers))
+	}
+
 	return &ProbeResult{
 		Success:   true,
 		Latency:   time.Since(start),
 		Message:   fmt.Sprintf("Kafka 集群服务可用，%d 个 Broker 在线", len(brokerList)),
+		Metrics:   map[string]any{"online_brokers": len(brokerList), "expected_brokers": expectedBrokers},
+		Warnings:  warnings,
 		CheckedAt: time.Now(),
 	}, nil
 }
@@ -0,0 +1,57 @@
+package prober
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/IBM/sarama"
+)
+
+func TestKafkaProberExpectedBrokers(t *testing.T) {
+	seed := sarama.NewMockBroker(t, 1)
+	defer seed.Close()
+	// 集群中只有种子 Broker 在线
+	seed.SetHandlerByMap(map[string]sarama.MockResponse{
+		"ApiVersionsRequest": sarama.NewMockApiVersionsResponse(t),
+		"MetadataRequest": sarama.NewMockMetadataResponse(t).
+			SetController(seed.BrokerID()).
+			SetBroker(seed.Addr(), seed.BrokerID()),
+	})
+
+	tests := []struct {
+		name        string
+		brokers     string
+		expected    any
+		wantOutcome Outcome
+		wantWarning string
+	}{
+		{"默认期望数量为配置的地址数量", seed.Addr(), nil, OutcomeOK, ""},
+		{"在线数量达到期望", seed.Addr(), 1, OutcomeOK, ""},
+		{"在线数量少于期望时判定为降级", seed.Addr(), 3.0, OutcomeDegraded, "在线 Broker 数量 1 少于期望的 3 个"},
+		{"配置的地址多于在线数量", seed.Addr() + ",127.0.0.1:1", nil, OutcomeDegraded, "在线 Broker 数量 1 少于期望的 2 个"},
+	}
+	p := NewKafkaProber()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := map[string]any{"brokers": tt.brokers}
+			if tt.expected != nil {
+				config["expected_brokers"] = tt.expected
+			}
+			result, err := p.Probe(context.Background(), Target{Timeout: 5 * time.Second, Config: config})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := result.Outcome(); got != tt.wantOutcome {
+				t.Fatalf("Outcome() = %q, want %q（%s）", got, tt.wantOutcome, result.Summary())
+			}
+			if tt.wantWarning != "" && !strings.Contains(result.Summary(), tt.wantWarning) {
+				t.Fatalf("摘要 = %q，缺少 %q", result.Summary(), tt.wantWarning)
+			}
+			if result.Metrics["online_brokers"] != 1 {
+				t.Fatalf("online_brokers = %v", result.Metrics["online_brokers"])
+			}
+		})
+	}
+}
@@ -0,0 +1,245 @@
+package prober
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// LwM2MProber LwM2M 探针
+// 以测试终端名称向 LwM2M 服务器执行一次完整的客户端注册周期：Register、Update、Deregister，
+// 可选先向引导服务器发送 Bootstrap-Request；每一步的耗时记录在 Metrics 中
+type LwM2MProber struct{}
+
+// lwm2mObjectLinks 注册时上报的对象实例：Security、Server、Device
+const lwm2mObjectLinks = `</>;rt="oma.lwm2m",</1/0>,</3/0>`
+
+// NewLwM2MProber 创建 LwM2M 探针
+func NewLwM2MProber() *LwM2MProber {
+	return &LwM2MProber{}
+}
+
+// Type 返回探针类型
+func (p *LwM2MProber) Type() string {
+	return "lwm2m"
+}
+
+// ConfigSchema 返回配置表单 schema
+func (p *LwM2MProber) ConfigSchema() map[string]FieldSchema {
+	return map[string]FieldSchema{
+		"host": {
+			Type:        "string",
+			Label:       "主机地址",
+			Required:    true,
+			Placeholder: "localhost",
+		},
+		"port": {
+			Type:        "number",
+			Label:       "端口",
+			Required:    false,
+			Placeholder: "5685",
+			Hint:        "ThingsBoard 默认无加密为 5685，DTLS 为 5686",
+		},
+		"endpoint": {
+			Type:        "string",
+			Label:       "终端名称",
+			Required:    true,
+			Placeholder: "rxprobe-lwm2m",
+			Hint:        "ThingsBoard 中需预先创建使用该终端名称作为凭证的 LwM2M 设备",
+		},
+		"lifetime": {
+			Type:         "number",
+			Label:        "注册有效期（秒）",
+			Required:     false,
+			DefaultValue: 300,
+		},
+		"version": {
+			Type:         "select",
+			Label:        "协议版本",
+			Required:     false,
+			DefaultValue: "1.0",
+			Options: []Option{
+				{Value: "1.0", Label: "1.0"},
+				{Value: "1.1", Label: "1.1"},
+			},
+		},
+		"bootstrap_port": {
+			Type:        "number",
+			Label:       "引导服务器端口",
+			Required:    false,
+			Placeholder: "5687",
+			Hint:        "填写后先向同一主机的引导服务器发送 Bootstrap-Request，0 或为空表示不检查",
+		},
+		"ack_timeout_ms": {
+			Type:         "number",
+			Label:        "确认超时（毫秒）",
+			Required:     false,
+			DefaultValue: 2000,
+		},
+		"security": {
+			Type:         "select",
+			Label:        "安全模式",
+			Required:     false,
+			DefaultValue: "none",
+			Options: []Option{
+				{Value: "none", Label: "无 (NoSec)"},
+				{Value: "psk", Label: "DTLS 预共享密钥"},
+				{Value: "certificate", Label: "DTLS 证书"},
+			},
+		},
+		"psk_identity": {
+			Type:     "string",
+			Label:    "PSK 身份",
+			Required: false,
+			ShowWhen: map[string]any{"security": "psk"},
+		},
+		"psk_key": {
+			Type:     "password",
+			Label:    "PSK 密钥",
+			Required: false,
+			Hint:     "十六进制格式",
+			ShowWhen: map[string]any{"security": "psk"},
+		},
+		"insecure_skip_verify": {
+			Type:         "boolean",
+			Label:        "跳过证书验证",
+			Required:     false,
+			DefaultValue: false,
+			ShowWhen:     map[string]any{"security": "certificate"},
+		},
+	}
+}
+
+// Probe 执行探测
+func (p *LwM2MProber) Probe(ctx context.Context, target Target) (*ProbeResult, error) {
+	start := time.Now()
+	endpoint := getStringConfig(target.Config, "endpoint", "")
+	metrics := map[string]any{}
+
+	result := func(success bool, format string, args ...any) (*ProbeResult, error) {
+		return &ProbeResult{
+			Success:   success,
+			Latency:   time.Since(start),
+			Message:   fmt.Sprintf(format, args...),
+			Metrics:   metrics,
+			CheckedAt: time.Now(),
+		}, nil
+	}
+
+	if port := getIntConfig(target.Config, "bootstrap_port", 0); port > 0 {
+		elapsed, err := p.bootstrap(ctx, target, port, endpoint)
+		if err != nil {
+			return result(false, "Bootstrap-Request 失败: %v", err)
+		}
+		metrics["bootstrap_ms"] = elapsed.Milliseconds()
+	}
+
+	port := 5685
+	if getStringConfig(target.Config, "security", "none") != "none" {
+		port = 5686
+	}
+	session, err := dialCoAP(ctx, target, getIntConfig(target.Config, "port", port))
+	if err != nil {
+		return result(false, "%v", err)
+	}
+	defer session.Close()
+	defer func() {
+		for k, v := range session.metrics() {
+			metrics[k] = v
+		}
+	}()
+
+	// Register：POST /rd?ep=...，服务器返回 2.01 与注册位置
+	lifetime := getIntConfig(target.Config, "lifetime", 300)
+	register := newCoAPRequest(coapPOST, "/rd")
+	register.addQuery("ep", endpoint)
+	register.addQuery("lt", fmt.Sprint(lifetime))
+	register.addQuery("lwm2m", getStringConfig(target.Config, "version", "1.0"))
+	register.addQuery("b", "U")
+	register.setContentFormat(coapFormatLinkFmt)
+	register.payload = []byte(lwm2mObjectLinks)
+
+	resp, elapsed, err := lwm2mStep(ctx, session.client, register, coapCreated)
+	if err != nil {
+		return result(false, "Register 失败: %v", err)
+	}
+	metrics["register_ms"] = elapsed.Milliseconds()
+	location := resp.optionStrings(coapOptionLocationPath)
+	if len(location) == 0 {
+		return result(false, "Register 响应缺少注册位置 (Location-Path)")
+	}
+	locationPath := "/" + strings.Join(location, "/")
+	metrics["location"] = locationPath
+
+	// Update：POST 注册位置
+	update := newCoAPRequest(coapPOST, locationPath)
+	update.addQuery("lt", fmt.Sprint(lifetime))
+	_, elapsed, updateErr := lwm2mStep(ctx, session.client, update, coapChanged)
+	if updateErr == nil {
+		metrics["update_ms"] = elapsed.Milliseconds()
+	}
+
+	// Deregister：DELETE 注册位置，Update 失败时也尝试注销以免残留注册
+	_, elapsed, err = lwm2mStep(ctx, session.client, newCoAPRequest(coapDELETE, locationPath), coapDeleted)
+	if updateErr != nil {
+		return result(false, "Update 失败: %v", updateErr)
+	}
+	if err != nil {
+		return result(false, "Deregister 失败: %v", err)
+	}
+	metrics["deregister_ms"] = elapsed.Milliseconds()
+
+	return result(true, "LwM2M 注册周期完成: %s (%s)", endpoint, locationPath)
+}
+
+// bootstrap 向引导服务器发送 Bootstrap-Request，期望 2.04
+func (p *LwM2MProber) bootstrap(ctx context.Context, target Target, port int, endpoint string) (time.Duration, error) {
+	session, err := dialCoAP(ctx, target, port)
+	if err != nil {
+		return 0, err
+	}
+	defer session.Close()
+
+	req := newCoAPRequest(coapPOST, "/bs")
+	req.addQuery("ep", endpoint)
+	_, elapsed, err := lwm2mStep(ctx, session.client, req, coapChanged)
+	return elapsed, err
+}
+
+// Validate 验证目标配置
+func (p *LwM2MProber) Validate(target Target) error {
+	if _, ok := target.Config["host"]; !ok {
+		return fmt.Errorf("缺少必填字段: host")
+	}
+	if getStringConfig(target.Config, "endpoint", "") == "" {
+		return fmt.Errorf("缺少必填字段: endpoint")
+	}
+	if lifetime := getIntConfig(target.Config, "lifetime", 300); lifetime <= 0 {
+		return fmt.Errorf("注册有效期必须大于 0")
+	}
+	switch version := getStringConfig(target.Config, "version", "1.0"); version {
+	case "1.0", "1.1":
+	default:
+		return fmt.Errorf("不支持的 LwM2M 版本: %s", version)
+	}
+	return validateCoAPSecurity(target.Config)
+}
+
+// lwm2mStep 发送一个注册周期请求并检查响应码，返回响应与耗时
+func lwm2mStep(ctx context.Context, client *coapClient, req *coapMessage, expected byte) (*coapMessage, time.Duration, error) {
+	start := time.Now()
+	resp, err := client.do(ctx, req, true)
+	elapsed := time.Since(start)
+	if err != nil {
+		return nil, elapsed, err
+	}
+	if resp.code != expected {
+		msg := fmt.Sprintf("响应码 %s，期望 %s", coapCodeString(resp.code), coapCodeString(expected))
+		if len(resp.payload) > 0 && len(resp.payload) <= 256 {
+			msg += fmt.Sprintf("（%s）", resp.payload)
+		}
+		return resp, elapsed, fmt.Errorf("%s", msg)
+	}
+	return resp, elapsed, nil
+}
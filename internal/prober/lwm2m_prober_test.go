@@ -0,0 +1,210 @@
+package prober
+
+import (
+	"net"
+	"strings"
+	"testing"
+)
+
+// lwm2mTestTarget 指向本地测试服务器的 LwM2M 目标
+func lwm2mTestTarget(server *coapTestServer, endpoint string) Target {
+	return Target{Config: map[string]any{
+		"host":           "127.0.0.1",
+		"port":           server.conn.LocalAddr().(*net.UDPAddr).Port,
+		"endpoint":       endpoint,
+		"lifetime":       60,
+		"version":        "1.1",
+		"ack_timeout_ms": 100,
+		"max_retransmit": 1,
+	}}
+}
+
+// lwm2mServer 按请求方法与路径回复的 LwM2M 服务器，codes 中未列出的请求回复 4.04
+func lwm2mServer(codes map[string]byte, location ...string) func(msg *coapMessage, n int) []*coapMessage {
+	return func(msg *coapMessage, n int) []*coapMessage {
+		if msg.typ != coapConfirmable {
+			return nil
+		}
+		key := coapCodeString(msg.code) + " /" + strings.Join(msg.optionStrings(coapOptionURIPath), "/")
+		code, ok := codes[key]
+		if !ok {
+			code = coapNotFound
+		}
+		reply := piggybacked(msg, code, "")
+		if code == coapCreated {
+			for _, segment := range location {
+				reply.addOption(coapOptionLocationPath, []byte(segment))
+			}
+		}
+		return []*coapMessage{reply}
+	}
+}
+
+// 注册周期各步骤的请求
+const (
+	lwm2mRegister   = "0.02 /rd"
+	lwm2mUpdate     = "0.02 /rd/5a3f"
+	lwm2mDeregister = "0.04 /rd/5a3f"
+	lwm2mBootstrap  = "0.02 /bs"
+)
+
+func TestLwM2MRegistrationCycle(t *testing.T) {
+	const endpoint = "urn:dev:a&b=c"
+	server, _ := newCoAPTestServer(t, lwm2mServer(map[string]byte{
+		lwm2mRegister:   coapCreated,
+		lwm2mUpdate:     coapChanged,
+		lwm2mDeregister: coapDeleted,
+	}, "rd", "5a3f"))
+
+	result, err := NewLwM2MProber().Probe(testContext(t), lwm2mTestTarget(server, endpoint))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.Success {
+		t.Fatalf("注册周期失败: %s", result.Message)
+	}
+	for _, key := range []string{"register_ms", "update_ms", "deregister_ms", "security", "retransmissions"} {
+		if _, ok := result.Metrics[key]; !ok {
+			t.Fatalf("缺少指标 %s: %v", key, result.Metrics)
+		}
+	}
+	if result.Metrics["location"] != "/rd/5a3f" {
+		t.Fatalf("location = %v", result.Metrics["location"])
+	}
+
+	msgs := server.messages()
+	if len(msgs) != 3 {
+		t.Fatalf("服务器收到 %d 条消息，期望 Register、Update、Deregister", len(msgs))
+	}
+	// 终端名称中的 & 与 = 保留在同一个 Uri-Query 选项中
+	query := msgs[0].optionStrings(coapOptionURIQuery)
+	want := []string{"ep=" + endpoint, "lt=60", "lwm2m=1.1", "b=U"}
+	if strings.Join(query, "|") != strings.Join(want, "|") {
+		t.Fatalf("Register Uri-Query = %q, want %q", query, want)
+	}
+	if string(msgs[0].payload) != lwm2mObjectLinks {
+		t.Fatalf("Register payload = %q", msgs[0].payload)
+	}
+	if got := msgs[1].optionStrings(coapOptionURIQuery); len(got) != 1 || got[0] != "lt=60" {
+		t.Fatalf("Update Uri-Query = %q", got)
+	}
+	if got := strings.Join(msgs[2].optionStrings(coapOptionURIPath), "/"); msgs[2].code != coapDELETE || got != "rd/5a3f" {
+		t.Fatalf("Deregister 请求 %s /%s", coapCodeString(msgs[2].code), got)
+	}
+}
+
+func TestLwM2MRegistrationFailure(t *testing.T) {
+	tests := []struct {
+		name        string
+		codes       map[string]byte
+		location    []string
+		wantMessage string
+		wantMetrics []string // 应记录的步骤耗时
+		wantDelete  bool     // 是否发送了 Deregister
+	}{
+		{
+			name:        "Register 被拒绝",
+			codes:       map[string]byte{lwm2mRegister: 0x83}, // 4.03
+			wantMessage: "Register 失败: 响应码 4.03，期望 2.01",
+		},
+		{
+			name:        "Register 响应缺少注册位置",
+			codes:       map[string]byte{lwm2mRegister: coapCreated},
+			wantMessage: "Location-Path",
+			wantMetrics: []string{"register_ms"},
+		},
+		{
+			name:        "Update 失败时仍注销",
+			codes:       map[string]byte{lwm2mRegister: coapCreated, lwm2mDeregister: coapDeleted},
+			location:    []string{"rd", "5a3f"},
+			wantMessage: "Update 失败: 响应码 4.04",
+			wantMetrics: []string{"register_ms"},
+			wantDelete:  true,
+		},
+		{
+			name:        "Deregister 失败",
+			codes:       map[string]byte{lwm2mRegister: coapCreated, lwm2mUpdate: coapChanged},
+			location:    []string{"rd", "5a3f"},
+			wantMessage: "Deregister 失败",
+			wantMetrics: []string{"register_ms", "update_ms"},
+			wantDelete:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, _ := newCoAPTestServer(t, lwm2mServer(tt.codes, tt.location...))
+			result, err := NewLwM2MProber().Probe(testContext(t), lwm2mTestTarget(server, "probe-1"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.Success || !strings.Contains(result.Message, tt.wantMessage) {
+				t.Fatalf("结果 %v %q，期望失败并包含 %q", result.Success, result.Message, tt.wantMessage)
+			}
+			for _, key := range []string{"register_ms", "update_ms", "deregister_ms"} {
+				_, got := result.Metrics[key]
+				want := false
+				for _, k := range tt.wantMetrics {
+					want = want || k == key
+				}
+				if got != want {
+					t.Fatalf("指标 %s 存在 = %v, want %v: %v", key, got, want, result.Metrics)
+				}
+			}
+			deleted := false
+			for _, msg := range server.messages() {
+				deleted = deleted || msg.code == coapDELETE
+			}
+			if deleted != tt.wantDelete {
+				t.Fatalf("发送 Deregister = %v, want %v", deleted, tt.wantDelete)
+			}
+		})
+	}
+}
+
+func TestLwM2MBootstrap(t *testing.T) {
+	codes := map[string]byte{lwm2mRegister: coapCreated, lwm2mUpdate: coapChanged, lwm2mDeregister: coapDeleted}
+
+	t.Run("引导成功后注册", func(t *testing.T) {
+		server, _ := newCoAPTestServer(t, lwm2mServer(codes, "rd", "5a3f"))
+		bootstrap, _ := newCoAPTestServer(t, lwm2mServer(map[string]byte{lwm2mBootstrap: coapChanged}))
+		target := lwm2mTestTarget(server, "a&b")
+		target.Config["bootstrap_port"] = bootstrap.conn.LocalAddr().(*net.UDPAddr).Port
+
+		result, err := NewLwM2MProber().Probe(testContext(t), target)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !result.Success {
+			t.Fatalf("探测失败: %s", result.Message)
+		}
+		if _, ok := result.Metrics["bootstrap_ms"]; !ok {
+			t.Fatalf("缺少 bootstrap_ms: %v", result.Metrics)
+		}
+		msgs := bootstrap.messages()
+		if len(msgs) != 1 {
+			t.Fatalf("引导服务器收到 %d 条消息", len(msgs))
+		}
+		if got := msgs[0].optionStrings(coapOptionURIQuery); len(got) != 1 || got[0] != "ep=a&b" {
+			t.Fatalf("Bootstrap-Request Uri-Query = %q", got)
+		}
+	})
+
+	t.Run("引导失败时不注册", func(t *testing.T) {
+		server, _ := newCoAPTestServer(t, lwm2mServer(codes, "rd", "5a3f"))
+		bootstrap, _ := newCoAPTestServer(t, lwm2mServer(nil))
+		target := lwm2mTestTarget(server, "probe-1")
+		target.Config["bootstrap_port"] = bootstrap.conn.LocalAddr().(*net.UDPAddr).Port
+
+		result, err := NewLwM2MProber().Probe(testContext(t), target)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.Success || !strings.Contains(result.Message, "Bootstrap-Request 失败") {
+			t.Fatalf("结果 %v %q", result.Success, result.Message)
+		}
+		if got := len(server.messages()); got != 0 {
+			t.Fatalf("引导失败后 LwM2M 服务器收到 %d 条消息", got)
+		}
+	})
+}
@@ -0,0 +1,344 @@
+package prober
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"time"
+
+	"golang.org/x/net/websocket"
+)
+
+// MQTT 3.1.1 控制报文类型
+const (
+	mqttConnect     byte = 1
+	mqttConnAck     byte = 2
+	mqttPublish     byte = 3
+	mqttPubAck      byte = 4
+	mqttSubscribe   byte = 8
+	mqttSubAck      byte = 9
+	mqttDisconnect  byte = 14
+	mqttMaxBodySize      = 256 * 1024 // 探测只接收小报文，超过此长度视为异常
+)
+
+// mqttConnAckErrors CONNACK 返回码对应的错误
+var mqttConnAckErrors = map[byte]string{
+	1: "不支持的协议版本",
+	2: "客户端 ID 被拒绝",
+	3: "服务不可用",
+	4: "用户名或密码错误",
+	5: "未授权",
+}
+
+// mqttPacket MQTT 报文
+type mqttPacket struct {
+	typ   byte
+	flags byte
+	body  []byte
+}
+
+// mqttClient 用于探测的最小 MQTT 3.1.1 客户端，仅支持 QoS 0/1
+type mqttClient struct {
+	conn     net.Conn
+	r        *bufio.Reader
+	packetID uint16
+
+	expectTopic   string    // 等待回环的主题
+	expectPayload []byte    // 等待回环的消息内容
+	receivedAt    time.Time // 收到回环消息的时间
+}
+
+// dialMQTT 建立 MQTT 底层连接，transport 为 tcp、tls、ws 或 wss
+func dialMQTT(ctx context.Context, transport, addr, path string, timeout time.Duration, tlsConfig *tls.Config) (*mqttClient, error) {
+	dialer := &net.Dialer{Timeout: timeout}
+
+	var conn net.Conn
+	var err error
+	switch transport {
+	case "tcp":
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+	case "tls":
+		conn, err = (&tls.Dialer{NetDialer: dialer, Config: tlsConfig}).DialContext(ctx, "tcp", addr)
+	case "ws", "wss":
+		u := url.URL{Scheme: transport, Host: addr, Path: path}
+		origin := url.URL{Scheme: "http", Host: addr}
+		if transport == "wss" {
+			origin.Scheme = "https"
+		}
+		var config *websocket.Config
+		config, err = websocket.NewConfig(u.String(), origin.String())
+		if err != nil {
+			return nil, err
+		}
+		config.Protocol = []string{"mqtt"}
+		config.Dialer = dialer
+		config.TlsConfig = tlsConfig
+		var ws *websocket.Conn
+		ws, err = websocket.DialConfig(config)
+		if err == nil {
+			ws.PayloadType = websocket.BinaryFrame
+			conn = ws
+		}
+	default:
+		return nil, fmt.Errorf("不支持的传输方式: %s", transport)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	} else if timeout > 0 {
+		_ = conn.SetDeadline(time.Now().Add(timeout))
+	}
+	return &mqttClient{conn: conn, r: bufio.NewReader(conn)}, nil
+}
+
+// Close 关闭连接
+func (c *mqttClient) Close() error {
+	return c.conn.Close()
+}
+
+// connect 发送 CONNECT 并等待 CONNACK
+func (c *mqttClient) connect(clientID, username, password string, keepAlive time.Duration) error {
+	var body bytes.Buffer
+	writeMQTTString(&body, "MQTT")
+	body.WriteByte(4) // 协议级别 3.1.1
+
+	flags := byte(0x02) // Clean Session
+	if username != "" {
+		flags |= 0x80
+		if password != "" {
+			flags |= 0x40
+		}
+	}
+	body.WriteByte(flags)
+	_ = binary.Write(&body, binary.BigEndian, uint16(keepAlive/time.Second))
+
+	writeMQTTString(&body, clientID)
+	if username != "" {
+		writeMQTTString(&body, username)
+		if password != "" {
+			writeMQTTString(&body, password)
+		}
+	}
+	if err := c.write(mqttConnect, 0, body.Bytes()); err != nil {
+		return err
+	}
+
+	pkt, err := c.await(mqttConnAck, 0)
+	if err != nil {
+		return err
+	}
+	if len(pkt.body) < 2 {
+		return fmt.Errorf("CONNACK 报文格式错误")
+	}
+	if code := pkt.body[1]; code != 0 {
+		if msg, ok := mqttConnAckErrors[code]; ok {
+			return fmt.Errorf("连接被拒绝: %s", msg)
+		}
+		return fmt.Errorf("连接被拒绝，返回码 %d", code)
+	}
+	return nil
+}
+
+// subscribe 订阅主题并等待 SUBACK
+func (c *mqttClient) subscribe(topic string, qos byte) error {
+	id := c.nextID()
+	var body bytes.Buffer
+	_ = binary.Write(&body, binary.BigEndian, id)
+	writeMQTTString(&body, topic)
+	body.WriteByte(qos)
+	if err := c.write(mqttSubscribe, 0x02, body.Bytes()); err != nil {
+		return err
+	}
+
+	pkt, err := c.await(mqttSubAck, id)
+	if err != nil {
+		return err
+	}
+	if len(pkt.body) < 3 || pkt.body[2] == 0x80 {
+		return fmt.Errorf("订阅主题 %s 被拒绝", topic)
+	}
+	return nil
+}
+
+// publish 发布消息，QoS 1 时等待 PUBACK
+func (c *mqttClient) publish(topic string, payload []byte, qos byte) error {
+	var body bytes.Buffer
+	writeMQTTString(&body, topic)
+	var id uint16
+	if qos > 0 {
+		id = c.nextID()
+		_ = binary.Write(&body, binary.BigEndian, id)
+	}
+	body.Write(payload)
+	if err := c.write(mqttPublish, qos<<1, body.Bytes()); err != nil {
+		return err
+	}
+	if qos == 0 {
+		return nil
+	}
+	_, err := c.await(mqttPubAck, id)
+	return err
+}
+
+// expect 设置等待回环的消息
+func (c *mqttClient) expect(topic string, payload []byte) {
+	c.expectTopic = topic
+	c.expectPayload = payload
+	c.receivedAt = time.Time{}
+}
+
+// awaitMessage 等待收到 expect 设置的消息，返回收到的时间
+func (c *mqttClient) awaitMessage() (time.Time, error) {
+	for c.receivedAt.IsZero() {
+		if _, err := c.read(); err != nil {
+			return time.Time{}, err
+		}
+	}
+	return c.receivedAt, nil
+}
+
+// disconnect 发送 DISCONNECT
+func (c *mqttClient) disconnect() error {
+	return c.write(mqttDisconnect, 0, nil)
+}
+
+// nextID 生成报文标识符
+func (c *mqttClient) nextID() uint16 {
+	c.packetID++
+	if c.packetID == 0 {
+		c.packetID = 1
+	}
+	return c.packetID
+}
+
+// await 读取报文直到收到指定类型（及报文标识符）的报文
+func (c *mqttClient) await(typ byte, id uint16) (*mqttPacket, error) {
+	for {
+		pkt, err := c.read()
+		if err != nil {
+			return nil, err
+		}
+		if pkt.typ != typ {
+			continue
+		}
+		if id != 0 && (len(pkt.body) < 2 || binary.BigEndian.Uint16(pkt.body) != id) {
+			continue
+		}
+		return pkt, nil
+	}
+}
+
+// read 读取一个报文；收到 PUBLISH 时检查是否为等待回环的消息，QoS 1 消息回复 PUBACK
+func (c *mqttClient) read() (*mqttPacket, error) {
+	header, err := c.r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+	length, err := readMQTTLength(c.r)
+	if err != nil {
+		return nil, err
+	}
+	if length > mqttMaxBodySize {
+		return nil, fmt.Errorf("报文长度 %d 超过限制", length)
+	}
+	pkt := &mqttPacket{typ: header >> 4, flags: header & 0x0f, body: make([]byte, length)}
+	if _, err := io.ReadFull(c.r, pkt.body); err != nil {
+		return nil, err
+	}
+
+	if pkt.typ == mqttPublish {
+		if err := c.handlePublish(pkt); err != nil {
+			return nil, err
+		}
+	}
+	return pkt, nil
+}
+
+// handlePublish 处理收到的 PUBLISH 报文
+func (c *mqttClient) handlePublish(pkt *mqttPacket) error {
+	body := pkt.body
+	if len(body) < 2 {
+		return fmt.Errorf("PUBLISH 报文格式错误")
+	}
+	topicLen := int(binary.BigEndian.Uint16(body))
+	if len(body) < 2+topicLen {
+		return fmt.Errorf("PUBLISH 报文格式错误")
+	}
+	topic := string(body[2 : 2+topicLen])
+	payload := body[2+topicLen:]
+
+	if qos := (pkt.flags >> 1) & 0x03; qos > 0 {
+		if len(payload) < 2 {
+			return fmt.Errorf("PUBLISH 报文格式错误")
+		}
+		id := payload[:2]
+		payload = payload[2:]
+		if qos == 1 {
+			if err := c.write(mqttPubAck, 0, id); err != nil {
+				return err
+			}
+		}
+	}
+
+	if c.receivedAt.IsZero() && topic == c.expectTopic && bytes.Equal(payload, c.expectPayload) {
+		c.receivedAt = time.Now()
+	}
+	return nil
+}
+
+// write 写入一个报文
+func (c *mqttClient) write(typ, flags byte, body []byte) error {
+	var buf bytes.Buffer
+	buf.WriteByte(typ<<4 | flags)
+	writeMQTTLength(&buf, len(body))
+	buf.Write(body)
+	_, err := c.conn.Write(buf.Bytes())
+	return err
+}
+
+// writeMQTTString 写入带长度前缀的 UTF-8 字符串
+func writeMQTTString(buf *bytes.Buffer, s string) {
+	_ = binary.Write(buf, binary.BigEndian, uint16(len(s)))
+	buf.WriteString(s)
+}
+
+// writeMQTTLength 写入剩余长度（变长编码）
+func writeMQTTLength(buf *bytes.Buffer, length int) {
+	for {
+		b := byte(length % 128)
+		length /= 128
+		if length > 0 {
+			b |= 0x80
+		}
+		buf.WriteByte(b)
+		if length == 0 {
+			return
+		}
+	}
+}
+
+// readMQTTLength 读取剩余长度（变长编码）
+func readMQTTLength(r io.ByteReader) (int, error) {
+	length, multiplier := 0, 1
+	for i := 0; i < 4; i++ {
+		b, err := r.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+		length += int(b&0x7f) * multiplier
+		if b&0x80 == 0 {
+			return length, nil
+		}
+		multiplier *= 128
+	}
+	return 0, fmt.Errorf("剩余长度编码错误")
+}
@@ -0,0 +1,232 @@
+package prober
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newMQTTPipe 创建通过 net.Pipe 连接的客户端与模拟 Broker 端
+func newMQTTPipe(t *testing.T) (*mqttClient, net.Conn) {
+	t.Helper()
+	client, broker := net.Pipe()
+	deadline := time.Now().Add(5 * time.Second)
+	client.SetDeadline(deadline)
+	broker.SetDeadline(deadline)
+	t.Cleanup(func() {
+		client.Close()
+		broker.Close()
+	})
+	return &mqttClient{conn: client, r: bufio.NewReader(client)}, broker
+}
+
+// mqttBroker 按脚本与客户端交互的模拟 Broker：expect 校验收到的报文，send 发送原始字节
+type mqttBroker struct {
+	conn net.Conn
+	errs chan error
+}
+
+func startMQTTBroker(conn net.Conn, script func(b *mqttBroker) error) *mqttBroker {
+	b := &mqttBroker{conn: conn, errs: make(chan error, 1)}
+	go func() {
+		err := script(b)
+		if err != nil {
+			conn.Close() // 脚本失败时断开连接，避免客户端等待到超时
+		}
+		b.errs <- err
+	}()
+	return b
+}
+
+// expect 读取一个报文（剩余长度小于 128）并与期望的原始字节比较
+func (b *mqttBroker) expect(want []byte) error {
+	header := make([]byte, 2)
+	if _, err := io.ReadFull(b.conn, header); err != nil {
+		return err
+	}
+	body := make([]byte, header[1])
+	if _, err := io.ReadFull(b.conn, body); err != nil {
+		return err
+	}
+	if got := append(header, body...); !bytes.Equal(got, want) {
+		return fmt.Errorf("收到报文 % x, want % x", got, want)
+	}
+	return nil
+}
+
+func (b *mqttBroker) send(data []byte) error {
+	_, err := b.conn.Write(data)
+	return err
+}
+
+// wait 等待脚本执行完毕
+func (b *mqttBroker) wait(t *testing.T) {
+	t.Helper()
+	if err := <-b.errs; err != nil {
+		t.Fatalf("Broker: %v", err)
+	}
+}
+
+func TestMQTTClientRoundTrip(t *testing.T) {
+	client, conn := newMQTTPipe(t)
+	broker := startMQTTBroker(conn, func(b *mqttBroker) error {
+		// CONNECT：协议名、级别 4、Clean Session + 用户名 + 密码、KeepAlive 30 秒
+		connect := []byte{0x10, 29, 0, 4, 'M', 'Q', 'T', 'T', 4, 0xc2, 0, 30,
+			0, 5, 'p', 'r', 'o', 'b', 'e', 0, 4, 'u', 's', 'e', 'r', 0, 4, 'p', 'a', 's', 's'}
+		if err := b.expect(connect); err != nil {
+			return err
+		}
+		if err := b.send([]byte{0x20, 2, 0, 0}); err != nil {
+			return err
+		}
+
+		// SUBSCRIBE 报文标识符 1，QoS 1；SUBACK 前先发送一个无关的 SUBACK
+		if err := b.expect([]byte{0x82, 8, 0, 1, 0, 3, 't', '/', '1', 1}); err != nil {
+			return err
+		}
+		if err := b.send([]byte{0x90, 3, 0, 9, 1, 0x90, 3, 0, 1, 1}); err != nil {
+			return err
+		}
+
+		// PUBLISH QoS 1 报文标识符 2，回复 PUBACK 后回环一条 QoS 1 消息，客户端需回复 PUBACK
+		if err := b.expect([]byte{0x32, 9, 0, 3, 't', '/', '1', 0, 2, 'h', 'i'}); err != nil {
+			return err
+		}
+		if err := b.send([]byte{0x40, 2, 0, 2}); err != nil {
+			return err
+		}
+		if err := b.send([]byte{0x32, 9, 0, 3, 't', '/', '2', 0, 6, 'h', 'i'}); err != nil {
+			return err
+		}
+		if err := b.expect([]byte{0x40, 2, 0, 6}); err != nil {
+			return err
+		}
+		if err := b.send([]byte{0x32, 9, 0, 3, 't', '/', '1', 0, 7, 'h', 'i'}); err != nil {
+			return err
+		}
+		if err := b.expect([]byte{0x40, 2, 0, 7}); err != nil {
+			return err
+		}
+		return b.expect([]byte{0xe0, 0})
+	})
+
+	if err := client.connect("probe", "user", "pass", 30*time.Second); err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	if err := client.subscribe("t/1", 1); err != nil {
+		t.Fatalf("subscribe: %v", err)
+	}
+	client.expect("t/1", []byte("hi"))
+	if err := client.publish("t/1", []byte("hi"), 1); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+	receivedAt, err := client.awaitMessage()
+	if err != nil {
+		t.Fatalf("等待回环消息: %v", err)
+	}
+	if receivedAt.IsZero() {
+		t.Fatal("未记录回环消息的接收时间")
+	}
+	if err := client.disconnect(); err != nil {
+		t.Fatal(err)
+	}
+	broker.wait(t)
+}
+
+func TestMQTTClientRejected(t *testing.T) {
+	tests := []struct {
+		name  string
+		reply []byte
+		call  func(c *mqttClient) error
+		want  string
+	}{
+		{
+			name:  "CONNACK 返回未授权",
+			reply: []byte{0x20, 2, 0, 5},
+			call:  func(c *mqttClient) error { return c.connect("probe", "", "", 0) },
+			want:  "未授权",
+		},
+		{
+			name:  "CONNACK 未知返回码",
+			reply: []byte{0x20, 2, 0, 42},
+			call:  func(c *mqttClient) error { return c.connect("probe", "", "", 0) },
+			want:  "返回码 42",
+		},
+		{
+			name:  "CONNACK 报文过短",
+			reply: []byte{0x20, 1, 0},
+			call:  func(c *mqttClient) error { return c.connect("probe", "", "", 0) },
+			want:  "格式错误",
+		},
+		{
+			name:  "SUBACK 拒绝订阅",
+			reply: []byte{0x90, 3, 0, 1, 0x80},
+			call:  func(c *mqttClient) error { return c.subscribe("t/1", 0) },
+			want:  "被拒绝",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, conn := newMQTTPipe(t)
+			go func() {
+				buf := make([]byte, 256)
+				conn.Read(buf)
+				conn.Write(tt.reply)
+			}()
+			err := tt.call(client)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("错误 = %v, want 包含 %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestMQTTClientMalformedPacket(t *testing.T) {
+	oversized := new(bytes.Buffer)
+	oversized.WriteByte(0x30)
+	writeMQTTLength(oversized, mqttMaxBodySize+1)
+
+	tests := []struct {
+		name string
+		data []byte
+		want string
+	}{
+		{name: "剩余长度超过 4 字节", data: []byte{0x30, 0xff, 0xff, 0xff, 0xff, 0x7f}, want: "剩余长度编码错误"},
+		{name: "剩余长度超过上限", data: oversized.Bytes(), want: "超过限制"},
+		{name: "报文体不完整", data: []byte{0x90, 5, 0, 1}, want: io.ErrUnexpectedEOF.Error()},
+		{name: "剩余长度不完整", data: []byte{0x30, 0x80}, want: io.EOF.Error()},
+		{name: "PUBLISH 主题长度越界", data: []byte{0x30, 3, 0, 9, 't'}, want: "PUBLISH 报文格式错误"},
+		{name: "PUBLISH 缺少报文标识符", data: []byte{0x32, 4, 0, 1, 't', 0}, want: "PUBLISH 报文格式错误"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, conn := newMQTTPipe(t)
+			go func() {
+				conn.Write(tt.data)
+				conn.Close()
+			}()
+			_, err := client.read()
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("错误 = %v, want 包含 %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestMQTTLengthRoundTrip(t *testing.T) {
+	for _, length := range []int{0, 127, 128, 16383, 16384, 2097151, 2097152, 268435455} {
+		var buf bytes.Buffer
+		writeMQTTLength(&buf, length)
+		got, err := readMQTTLength(&buf)
+		if err != nil || got != length {
+			t.Fatalf("剩余长度 %d 编解码后为 %d: %v", length, got, err)
+		}
+	}
+}
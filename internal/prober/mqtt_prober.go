@@ -0,0 +1,253 @@
+package prober
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+)
+
+// MQTTProber MQTT Broker 探针
+// 建立连接后可选订阅主题，发布一条测试消息，并在订阅时等待消息回环以测量往返时延。
+// 默认只检查连接，不发布消息：发布到 ThingsBoard 设备遥测主题会在每次探测时写入遥测数据
+type MQTTProber struct{}
+
+// NewMQTTProber 创建 MQTT 探针
+func NewMQTTProber() *MQTTProber {
+	return &MQTTProber{}
+}
+
+// Type 返回探针类型
+func (p *MQTTProber) Type() string {
+	return "mqtt"
+}
+
+// ConfigSchema 返回配置表单 schema
+func (p *MQTTProber) ConfigSchema() map[string]FieldSchema {
+	return map[string]FieldSchema{
+		"host": {
+			Type:        "string",
+			Label:       "主机地址",
+			Required:    true,
+			Placeholder: "localhost",
+		},
+		"port": {
+			Type:         "number",
+			Label:        "端口",
+			Required:     false,
+			DefaultValue: 1883,
+			Hint:         "通常 TCP 为 1883，TLS 为 8883，WebSocket 为 8080/8084",
+		},
+		"transport": {
+			Type:         "select",
+			Label:        "传输方式",
+			Required:     false,
+			DefaultValue: "tcp",
+			Options: []Option{
+				{Value: "tcp", Label: "TCP"},
+				{Value: "tls", Label: "TLS"},
+				{Value: "ws", Label: "WebSocket"},
+				{Value: "wss", Label: "WebSocket (TLS)"},
+			},
+		},
+		"ws_path": {
+			Type:         "string",
+			Label:        "WebSocket 路径",
+			Required:     false,
+			DefaultValue: "/mqtt",
+			Hint:         "仅 WebSocket 传输方式使用",
+		},
+		"insecure_skip_verify": {
+			Type:         "boolean",
+			Label:        "跳过证书验证",
+			Required:     false,
+			DefaultValue: false,
+			Hint:         "仅 TLS 传输方式使用",
+		},
+		"access_token": {
+			Type:     "password",
+			Label:    "设备访问令牌",
+			Required: false,
+			Hint:     "ThingsBoard 设备的 Access Token，作为用户名登录；填写后忽略用户名和密码",
+		},
+		"username": {
+			Type:     "string",
+			Label:    "用户名",
+			Required: false,
+		},
+		"password": {
+			Type:     "password",
+			Label:    "密码",
+			Required: false,
+		},
+		"client_id": {
+			Type:        "string",
+			Label:       "Client ID",
+			Required:    false,
+			Placeholder: "rxprobe-<主机名>",
+			Hint:        "为空时自动生成",
+		},
+		"publish_topic": {
+			Type:        "string",
+			Label:       "发布主题",
+			Required:    false,
+			Placeholder: "v1/devices/me/telemetry",
+			Hint:        "为空时只检查连接；填写 ThingsBoard 设备遥测主题时，每次探测都会向该设备写入一条遥测数据，建议使用专用的探测设备",
+		},
+		"subscribe_topic": {
+			Type:     "string",
+			Label:    "订阅主题",
+			Required: false,
+			Hint:     "填写后等待测试消息回环并测量往返时延，通常与发布主题相同；ThingsBoard 设备遥测主题不会回环，请留空",
+		},
+		"payload": {
+			Type:        "string",
+			Label:       "测试消息",
+			Required:    false,
+			Placeholder: `{"rxprobe_ts": 1700000000000}`,
+			Hint:        "为空时发送带当前时间戳的 JSON",
+		},
+		"qos": {
+			Type:         "select",
+			Label:        "QoS",
+			Required:     false,
+			DefaultValue: "1",
+			Options: []Option{
+				{Value: "0", Label: "0 - 最多一次"},
+				{Value: "1", Label: "1 - 至少一次"},
+			},
+		},
+	}
+}
+
+// Probe 执行探测
+func (p *MQTTProber) Probe(ctx context.Context, target Target) (*ProbeResult, error) {
+	start := time.Now()
+
+	host := getStringConfig(target.Config, "host", "localhost")
+	port := getIntConfig(target.Config, "port", 1883)
+	transport := getStringConfig(target.Config, "transport", "tcp")
+	addr := fmt.Sprintf("%s:%d", host, port)
+	qos := byte(mqttQoS(target.Config))
+
+	var tlsConfig *tls.Config
+	if transport == "tls" || transport == "wss" {
+		tlsConfig = &tls.Config{
+			ServerName:         host,
+			InsecureSkipVerify: getBoolConfig(target.Config, "insecure_skip_verify", false),
+		}
+	}
+
+	fail := func(format string, args ...any) (*ProbeResult, error) {
+		return &ProbeResult{
+			Success:   false,
+			Latency:   time.Since(start),
+			Message:   fmt.Sprintf(format, args...),
+			CheckedAt: time.Now(),
+		}, nil
+	}
+
+	client, err := dialMQTT(ctx, transport, addr, getStringConfig(target.Config, "ws_path", "/mqtt"), target.Timeout, tlsConfig)
+	if err != nil {
+		return fail("连接失败: %v", err)
+	}
+	defer client.Close()
+
+	username := getStringConfig(target.Config, "username", "")
+	password := getStringConfig(target.Config, "password", "")
+	if token := getStringConfig(target.Config, "access_token", ""); token != "" {
+		username, password = token, ""
+	}
+	clientID := getStringConfig(target.Config, "client_id", "")
+	if clientID == "" {
+		hostname, _ := os.Hostname()
+		clientID = fmt.Sprintf("rxprobe-%s-%d", hostname, start.UnixNano()%100000)
+	}
+
+	if err := client.connect(clientID, username, password, 30*time.Second); err != nil {
+		return fail("MQTT 连接失败: %v", err)
+	}
+	connectLatency := time.Since(start)
+	metrics := map[string]any{
+		"transport":  transport,
+		"connect_ms": connectLatency.Milliseconds(),
+	}
+
+	publishTopic := getStringConfig(target.Config, "publish_topic", "")
+	subscribeTopic := getStringConfig(target.Config, "subscribe_topic", "")
+	if subscribeTopic != "" {
+		if err := client.subscribe(subscribeTopic, qos); err != nil {
+			return fail("订阅失败: %v", err)
+		}
+	}
+
+	message := fmt.Sprintf("MQTT Broker 连接成功: %s (%s)", addr, transport)
+	if publishTopic != "" {
+		payload := []byte(getStringConfig(target.Config, "payload", ""))
+		if len(payload) == 0 {
+			payload = []byte(`{"rxprobe_ts":` + strconv.FormatInt(time.Now().UnixMilli(), 10) + `}`)
+		}
+		if subscribeTopic != "" {
+			client.expect(publishTopic, payload)
+		}
+
+		publishStart := time.Now()
+		if err := client.publish(publishTopic, payload, qos); err != nil {
+			return fail("发布消息失败: %v", err)
+		}
+		metrics["publish_ms"] = time.Since(publishStart).Milliseconds()
+		message = fmt.Sprintf("MQTT 发布成功: %s", publishTopic)
+
+		if subscribeTopic != "" {
+			receivedAt, err := client.awaitMessage()
+			if err != nil {
+				return fail("未收到回环消息: %v", err)
+			}
+			roundTrip := receivedAt.Sub(publishStart)
+			metrics["roundtrip_ms"] = roundTrip.Milliseconds()
+			message = fmt.Sprintf("MQTT 消息往返成功，耗时 %dms", roundTrip.Milliseconds())
+		}
+	}
+	_ = client.disconnect()
+
+	return &ProbeResult{
+		Success:   true,
+		Latency:   time.Since(start),
+		Message:   message,
+		Metrics:   metrics,
+		CheckedAt: time.Now(),
+	}, nil
+}
+
+// Validate 验证目标配置
+func (p *MQTTProber) Validate(target Target) error {
+	if _, ok := target.Config["host"]; !ok {
+		return fmt.Errorf("缺少必填字段: host")
+	}
+	switch transport := getStringConfig(target.Config, "transport", "tcp"); transport {
+	case "tcp", "tls", "ws", "wss":
+	default:
+		return fmt.Errorf("不支持的传输方式: %s", transport)
+	}
+	if qos := mqttQoS(target.Config); qos != 0 && qos != 1 {
+		return fmt.Errorf("QoS 只支持 0 或 1")
+	}
+	if getStringConfig(target.Config, "subscribe_topic", "") != "" && getStringConfig(target.Config, "publish_topic", "") == "" {
+		return fmt.Errorf("订阅主题需要配合发布主题使用")
+	}
+	return nil
+}
+
+// mqttQoS 获取 QoS 配置，表单中为字符串，接口中也可能为数字
+func mqttQoS(config map[string]any) int {
+	if s := getStringConfig(config, "qos", ""); s != "" {
+		qos, err := strconv.Atoi(s)
+		if err != nil {
+			return -1
+		}
+		return qos
+	}
+	return getIntConfig(config, "qos", 1)
+}
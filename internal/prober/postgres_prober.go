@@ -65,6 +65,45 @@ func (p *PostgresProber) ConfigSchema() map[string]FieldSchema {
 				{Value: "verify-full", Label: "完全验证"},
 			},
 		},
+		"collect_metrics": {
+			Type:         "boolean",
+			Label:        "采集详细指标",
+			Required:     false,
+			DefaultValue: false,
+			Hint:         "采集连接、慢查询、复制延迟等指标并按阈值判定降级，需要 PostgreSQL 10 及以上版本以及读取 pg_stat_* 视图的权限（如 pg_monitor 角色）",
+		},
+		"connection_usage_threshold": {
+			Type:         "number",
+			Label:        "连接使用率阈值（%）",
+			Required:     false,
+			DefaultValue: 0,
+			Hint:         "客户端连接数占 max_connections 的比例超过此值时判定为降级，0 表示不检查",
+			ShowWhen:     map[string]any{"collect_metrics": true},
+		},
+		"replication_lag_threshold_s": {
+			Type:         "number",
+			Label:        "复制延迟阈值（秒）",
+			Required:     false,
+			DefaultValue: 0,
+			Hint:         "主库上任一副本或备库自身的重放延迟超过此值时判定为降级，0 表示不检查",
+			ShowWhen:     map[string]any{"collect_metrics": true},
+		},
+		"long_query_threshold_s": {
+			Type:         "number",
+			Label:        "慢查询阈值（秒）",
+			Required:     false,
+			DefaultValue: 0,
+			Hint:         "运行时间最长的查询超过此值时判定为降级，0 表示不检查",
+			ShowWhen:     map[string]any{"collect_metrics": true},
+		},
+		"idle_in_transaction_threshold": {
+			Type:         "number",
+			Label:        "事务空闲连接阈值",
+			Required:     false,
+			DefaultValue: 0,
+			Hint:         "处于 idle in transaction 状态的连接数超过此值时判定为降级，0 表示不检查",
+			ShowWhen:     map[string]any{"collect_metrics": true},
+		},
 	}
 }
 
@@ -107,14 +146,156 @@ func (p *PostgresProber) Probe(ctx context.Context, target Target) (*ProbeResult
 		}, nil
 	}
 
+	if !getBoolConfig(target.Config, "collect_metrics", false) {
+		return &ProbeResult{
+			Success:   true,
+			Latency:   time.Since(start),
+			Message:   "PostgreSQL 服务可用",
+			CheckedAt: time.Now(),
+		}, nil
+	}
+
+	// 指标采集失败（如权限不足）不影响可用性判断，仅判定为降级
+	metrics, err := collectPostgresMetrics(ctx, db)
+	if err != nil {
+		return &ProbeResult{
+			Success:   true,
+			Latency:   time.Since(start),
+			Message:   "PostgreSQL 服务可用",
+			Metrics:   metrics,
+			Warnings:  []string{fmt.Sprintf("采集指标失败: %v", err)},
+			CheckedAt: time.Now(),
+		}, nil
+	}
+
 	return &ProbeResult{
 		Success:   true,
 		Latency:   time.Since(start),
-		Message:   "PostgreSQL 服务可用",
+		Message:   fmt.Sprintf("PostgreSQL 服务可用，%d/%d 个连接", metrics["connections"], metrics["max_connections"]),
+		Metrics:   metrics,
+		Warnings:  postgresWarnings(target.Config, metrics),
 		CheckedAt: time.Now(),
 	}, nil
 }
 
+// postgresMinMetricsVersion 采集详细指标所需的最低版本（server_version_num）
+// backend_type、pg_stat_replication.replay_lag 与 pg_*_wal_* 函数自 PostgreSQL 10 起提供
+const postgresMinMetricsVersion = 100000
+
+// collectPostgresMetrics 采集连接、慢查询、复制延迟、数据库大小与死锁指标
+// 出错时返回已采集的部分指标；PostgreSQL 10 以下版本只返回版本号
+func collectPostgresMetrics(ctx context.Context, db *sql.DB) (map[string]any, error) {
+	metrics := map[string]any{}
+
+	var version int
+	if err := db.QueryRowContext(ctx, `SELECT current_setting('server_version_num')::int`).Scan(&version); err != nil {
+		return metrics, fmt.Errorf("查询服务器版本失败: %v", err)
+	}
+	metrics["server_version_num"] = version
+	if version < postgresMinMetricsVersion {
+		return metrics, fmt.Errorf("详细指标需要 PostgreSQL 10 及以上版本，当前 server_version_num 为 %d", version)
+	}
+
+	// 仅统计客户端连接，排除 autovacuum、walsender 等后台进程
+	var connections, active, idleInTx, maxConnections int
+	var longestQuery float64
+	err := db.QueryRowContext(ctx, `
+		SELECT count(*),
+		       count(*) FILTER (WHERE state = 'active'),
+		       count(*) FILTER (WHERE state IN ('idle in transaction', 'idle in transaction (aborted)')),
+		       current_setting('max_connections')::int,
+		       COALESCE(EXTRACT(EPOCH FROM max(now() - query_start) FILTER (WHERE state = 'active' AND pid <> pg_backend_pid())), 0)
+		FROM pg_stat_activity
+		WHERE backend_type = 'client backend'`,
+	).Scan(&connections, &active, &idleInTx, &maxConnections, &longestQuery)
+	if err != nil {
+		return metrics, fmt.Errorf("查询 pg_stat_activity 失败: %v", err)
+	}
+	metrics["connections"] = connections
+	metrics["active_connections"] = active
+	metrics["idle_in_transaction"] = idleInTx
+	metrics["max_connections"] = maxConnections
+	if maxConnections > 0 {
+		metrics["connection_usage_percent"] = float64(connections) * 100 / float64(maxConnections)
+	}
+	metrics["longest_query_seconds"] = longestQuery
+
+	var inRecovery bool
+	if err := db.QueryRowContext(ctx, `SELECT pg_is_in_recovery()`).Scan(&inRecovery); err != nil {
+		return metrics, fmt.Errorf("查询复制状态失败: %v", err)
+	}
+	metrics["in_recovery"] = inRecovery
+	if inRecovery {
+		// 备库：已接收的 WAL 全部重放完成时延迟为 0，避免主库空闲时误报
+		var lag float64
+		err := db.QueryRowContext(ctx, `
+			SELECT CASE WHEN pg_last_wal_receive_lsn() = pg_last_wal_replay_lsn() THEN 0
+			            ELSE COALESCE(EXTRACT(EPOCH FROM now() - pg_last_xact_replay_timestamp()), 0)
+			       END`,
+		).Scan(&lag)
+		if err != nil {
+			return metrics, fmt.Errorf("查询重放延迟失败: %v", err)
+		}
+		metrics["replication_lag_seconds"] = lag
+	} else {
+		// 主库：取所有副本中最大的重放延迟
+		var replicas int
+		var lag, lagBytes float64
+		err := db.QueryRowContext(ctx, `
+			SELECT count(*),
+			       COALESCE(EXTRACT(EPOCH FROM max(replay_lag)), 0),
+			       COALESCE(max(pg_wal_lsn_diff(pg_current_wal_lsn(), replay_lsn)), 0)
+			FROM pg_stat_replication`,
+		).Scan(&replicas, &lag, &lagBytes)
+		if err != nil {
+			return metrics, fmt.Errorf("查询 pg_stat_replication 失败: %v", err)
+		}
+		metrics["replicas"] = replicas
+		metrics["replication_lag_seconds"] = lag
+		metrics["replication_lag_bytes"] = int64(lagBytes)
+	}
+
+	var size, deadlocks int64
+	err = db.QueryRowContext(ctx, `
+		SELECT pg_database_size(current_database()), deadlocks
+		FROM pg_stat_database
+		WHERE datname = current_database()`,
+	).Scan(&size, &deadlocks)
+	if err != nil {
+		return metrics, fmt.Errorf("查询 pg_stat_database 失败: %v", err)
+	}
+	metrics["database_size_bytes"] = size
+	metrics["deadlocks"] = deadlocks
+
+	return metrics, nil
+}
+
+// postgresWarnings 按配置的阈值检查指标，返回降级原因
+func postgresWarnings(config map[string]any, metrics map[string]any) []string {
+	var warnings []string
+	if threshold := getFloatConfig(config, "connection_usage_threshold", 0); threshold > 0 {
+		if usage, ok := metrics["connection_usage_percent"].(float64); ok && usage > threshold {
+			warnings = append(warnings, fmt.Sprintf("连接使用率 %.1f%% 超过阈值 %.0f%%", usage, threshold))
+		}
+	}
+	if threshold := getFloatConfig(config, "replication_lag_threshold_s", 0); threshold > 0 {
+		if lag, ok := metrics["replication_lag_seconds"].(float64); ok && lag > threshold {
+			warnings = append(warnings, fmt.Sprintf("复制延迟 %.1f 秒超过阈值 %.0f 秒", lag, threshold))
+		}
+	}
+	if threshold := getFloatConfig(config, "long_query_threshold_s", 0); threshold > 0 {
+		if longest, ok := metrics["longest_query_seconds"].(float64); ok && longest > threshold {
+			warnings = append(warnings, fmt.Sprintf("最长查询已运行 %.1f 秒，超过阈值 %.0f 秒", longest, threshold))
+		}
+	}
+	if threshold := getIntConfig(config, "idle_in_transaction_threshold", 0); threshold > 0 {
+		if idle, ok := metrics["idle_in_transaction"].(int); ok && idle > threshold {
+			warnings = append(warnings, fmt.Sprintf("事务空闲连接数 %d 超过阈值 %d", idle, threshold))
+		}
+	}
+	return warnings
+}
+
 // Validate 验证目标配置
 func (p *PostgresProber) Validate(target Target) error {
 	required := []string{"host", "username", "password", "database"}
@@ -0,0 +1,193 @@
+package prober
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakePGRow 查询语句包含 match 时返回的单行结果，err 不为空时查询失败
+type fakePGRow struct {
+	match  string
+	values []driver.Value
+	err    error
+}
+
+// fakePGConnector 按查询语句返回预设结果的 database/sql 连接器，记录执行过的查询
+type fakePGConnector struct {
+	rows    []fakePGRow
+	mu      sync.Mutex
+	queries []string
+}
+
+func (c *fakePGConnector) Connect(context.Context) (driver.Conn, error) { return &fakePGConn{c}, nil }
+func (c *fakePGConnector) Driver() driver.Driver                        { return nil }
+
+// executed 是否执行过包含 match 的查询
+func (c *fakePGConnector) executed(match string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, query := range c.queries {
+		if strings.Contains(query, match) {
+			return true
+		}
+	}
+	return false
+}
+
+type fakePGConn struct{ c *fakePGConnector }
+
+func (c *fakePGConn) Prepare(query string) (driver.Stmt, error) { return &fakePGStmt{c.c, query}, nil }
+func (c *fakePGConn) Close() error                              { return nil }
+func (c *fakePGConn) Begin() (driver.Tx, error)                 { return nil, errors.New("不支持事务") }
+
+type fakePGStmt struct {
+	c     *fakePGConnector
+	query string
+}
+
+func (s *fakePGStmt) Close() error  { return nil }
+func (s *fakePGStmt) NumInput() int { return -1 }
+func (s *fakePGStmt) Exec([]driver.Value) (driver.Result, error) {
+	return nil, errors.New("不支持 Exec")
+}
+
+func (s *fakePGStmt) Query([]driver.Value) (driver.Rows, error) {
+	s.c.mu.Lock()
+	s.c.queries = append(s.c.queries, s.query)
+	s.c.mu.Unlock()
+	for _, row := range s.c.rows {
+		if strings.Contains(s.query, row.match) {
+			if row.err != nil {
+				return nil, row.err
+			}
+			return &fakePGRows{values: row.values}, nil
+		}
+	}
+	return nil, errors.New("未预设的查询: " + s.query)
+}
+
+type fakePGRows struct {
+	values []driver.Value
+	done   bool
+}
+
+func (r *fakePGRows) Columns() []string { return make([]string, len(r.values)) }
+func (r *fakePGRows) Close() error      { return nil }
+func (r *fakePGRows) Next(dest []driver.Value) error {
+	if r.done {
+		return io.EOF
+	}
+	r.done = true
+	copy(dest, r.values)
+	return nil
+}
+
+// collectFakeMetrics 使用预设的查询结果采集指标
+func collectFakeMetrics(t *testing.T, rows ...fakePGRow) (map[string]any, *fakePGConnector, error) {
+	t.Helper()
+	connector := &fakePGConnector{rows: rows}
+	db := sql.OpenDB(connector)
+	t.Cleanup(func() { db.Close() })
+	metrics, err := collectPostgresMetrics(context.Background(), db)
+	return metrics, connector, err
+}
+
+var (
+	pgVersion14  = fakePGRow{match: "server_version_num", values: []driver.Value{int64(140005)}}
+	pgActivity   = fakePGRow{match: "FROM pg_stat_activity", values: []driver.Value{int64(50), int64(12), int64(3), int64(200), float64(42.5)}}
+	pgDatabase   = fakePGRow{match: "FROM pg_stat_database", values: []driver.Value{int64(1 << 30), int64(2)}}
+	pgPrimary    = fakePGRow{match: "pg_is_in_recovery()", values: []driver.Value{false}}
+	pgStandby    = fakePGRow{match: "pg_is_in_recovery()", values: []driver.Value{true}}
+	pgReplicas   = fakePGRow{match: "FROM pg_stat_replication", values: []driver.Value{int64(2), float64(1.5), float64(16384)}}
+	pgReplayLag  = fakePGRow{match: "pg_last_wal_receive_lsn", values: []driver.Value{float64(45)}}
+	pgVersionOld = fakePGRow{match: "server_version_num", values: []driver.Value{int64(90624)}}
+)
+
+func TestCollectPostgresMetricsPrimary(t *testing.T) {
+	metrics, connector, err := collectFakeMetrics(t, pgVersion14, pgActivity, pgPrimary, pgReplicas, pgDatabase)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]any{
+		"server_version_num":       140005,
+		"connections":              50,
+		"active_connections":       12,
+		"idle_in_transaction":      3,
+		"max_connections":          200,
+		"connection_usage_percent": float64(25),
+		"longest_query_seconds":    42.5,
+		"in_recovery":              false,
+		"replicas":                 2,
+		"replication_lag_seconds":  1.5,
+		"replication_lag_bytes":    int64(16384),
+		"database_size_bytes":      int64(1 << 30),
+		"deadlocks":                int64(2),
+	}
+	for key, value := range want {
+		if metrics[key] != value {
+			t.Errorf("%s = %#v, want %#v", key, metrics[key], value)
+		}
+	}
+	if connector.executed("pg_last_wal_receive_lsn") {
+		t.Fatal("主库不应查询备库的重放延迟")
+	}
+
+	warnings := postgresWarnings(map[string]any{
+		"connection_usage_threshold":    20,
+		"replication_lag_threshold_s":   5,
+		"long_query_threshold_s":        30,
+		"idle_in_transaction_threshold": 3,
+	}, metrics)
+	if len(warnings) != 2 || !strings.Contains(warnings[0], "连接使用率 25.0%") || !strings.Contains(warnings[1], "最长查询已运行 42.5 秒") {
+		t.Fatalf("降级原因 = %v", warnings)
+	}
+}
+
+func TestCollectPostgresMetricsStandby(t *testing.T) {
+	metrics, connector, err := collectFakeMetrics(t, pgVersion14, pgActivity, pgStandby, pgReplayLag, pgDatabase)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if metrics["in_recovery"] != true || metrics["replication_lag_seconds"] != float64(45) {
+		t.Fatalf("备库的重放延迟解析错误: %v", metrics)
+	}
+	if _, ok := metrics["replicas"]; ok || connector.executed("FROM pg_stat_replication") {
+		t.Fatal("备库不应查询 pg_stat_replication")
+	}
+	if warnings := postgresWarnings(map[string]any{"replication_lag_threshold_s": 30}, metrics); len(warnings) != 1 {
+		t.Fatalf("重放延迟超过阈值应判定为降级: %v", warnings)
+	}
+}
+
+func TestCollectPostgresMetricsRequiresPG10(t *testing.T) {
+	metrics, connector, err := collectFakeMetrics(t, pgVersionOld)
+	if err == nil || !strings.Contains(err.Error(), "PostgreSQL 10") || !strings.Contains(err.Error(), "90624") {
+		t.Fatalf("低版本应返回明确的错误: %v", err)
+	}
+	if len(metrics) != 1 || metrics["server_version_num"] != 90624 {
+		t.Fatalf("低版本只应返回版本号: %v", metrics)
+	}
+	if connector.executed("pg_stat_activity") {
+		t.Fatal("低版本不应执行指标查询")
+	}
+}
+
+func TestCollectPostgresMetricsPartial(t *testing.T) {
+	denied := fakePGRow{match: "FROM pg_stat_database", err: errors.New("permission denied")}
+	metrics, _, err := collectFakeMetrics(t, pgVersion14, pgActivity, pgPrimary, pgReplicas, denied)
+	if err == nil || !strings.Contains(err.Error(), "pg_stat_database") {
+		t.Fatalf("查询失败时应返回错误: %v", err)
+	}
+	if metrics["connections"] != 50 || metrics["replicas"] != 2 {
+		t.Fatalf("应返回已采集的部分指标: %v", metrics)
+	}
+	if _, ok := metrics["database_size_bytes"]; ok {
+		t.Fatal("失败的查询不应产生指标")
+	}
+}
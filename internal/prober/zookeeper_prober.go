@@ -0,0 +1,450 @@
+package prober
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ZooKeeperProber ZooKeeper 探针
+// 通过四字命令（ruok/srvr/mntr）逐个检查集群成员，汇总 Leader 与法定人数状态，并可建立一次会话验证服务可用
+type ZooKeeperProber struct{}
+
+// zkDefaultPort ZooKeeper 默认客户端端口
+const zkDefaultPort = 2181
+
+// zkNotServing 成员失去法定人数或仍在选举时对四字命令的响应
+const zkNotServing = "not currently serving requests"
+
+// zkMember 单个成员的检查结果
+type zkMember struct {
+	Address         string  `json:"address"`
+	Error           string  `json:"error,omitempty"`
+	Ruok            bool    `json:"ruok"`
+	Mode            string  `json:"mode,omitempty"`
+	Zxid            string  `json:"zxid,omitempty"`
+	Outstanding     int64   `json:"outstanding_requests"`
+	AvgLatency      float64 `json:"avg_latency_ms"`
+	MaxLatency      float64 `json:"max_latency_ms"`
+	NodeCount       int64   `json:"znode_count"`
+	Followers       int64   `json:"followers,omitempty"`
+	SyncedFollowers int64   `json:"synced_followers,omitempty"`
+	hasSynced       bool
+}
+
+// NewZooKeeperProber 创建 ZooKeeper 探针
+func NewZooKeeperProber() *ZooKeeperProber {
+	return &ZooKeeperProber{}
+}
+
+// Type 返回探针类型
+func (p *ZooKeeperProber) Type() string {
+	return "zookeeper"
+}
+
+// ConfigSchema 返回配置表单 schema
+func (p *ZooKeeperProber) ConfigSchema() map[string]FieldSchema {
+	return map[string]FieldSchema{
+		"hosts": {
+			Type:        "string",
+			Label:       "集群成员",
+			Required:    true,
+			Placeholder: "zk1:2181,zk2:2181,zk3:2181",
+			Hint:        "多个成员用逗号分隔，未指定端口时使用 2181",
+		},
+		"expected_members": {
+			Type:        "number",
+			Label:       "集群投票成员数",
+			Required:    false,
+			Placeholder: "3",
+			Hint:        "用于计算法定人数，默认为配置的成员数量",
+		},
+		"session_check": {
+			Type:         "boolean",
+			Label:        "会话检查",
+			Required:     false,
+			DefaultValue: true,
+			Hint:         "建立并关闭一次客户端会话，验证集群可处理请求",
+		},
+		"outstanding_threshold": {
+			Type:         "number",
+			Label:        "积压请求阈值",
+			Required:     false,
+			DefaultValue: 0,
+			Hint:         "任一成员积压请求数超过此值时判定为降级，0 表示不检查",
+		},
+		"latency_threshold_ms": {
+			Type:         "number",
+			Label:        "平均延迟阈值（毫秒）",
+			Required:     false,
+			DefaultValue: 0,
+			Hint:         "任一成员平均请求延迟超过此值时判定为降级，0 表示不检查",
+		},
+	}
+}
+
+// Probe 执行探测
+func (p *ZooKeeperProber) Probe(ctx context.Context, target Target) (*ProbeResult, error) {
+	start := time.Now()
+
+	hosts := getStringSliceConfig(target.Config, "hosts")
+	if len(hosts) == 0 {
+		return &ProbeResult{
+			Success:   false,
+			Latency:   time.Since(start),
+			Message:   "未配置集群成员",
+			CheckedAt: time.Now(),
+		}, nil
+	}
+
+	members := make([]*zkMember, len(hosts))
+	var wg sync.WaitGroup
+	for i, host := range hosts {
+		wg.Add(1)
+		go func(i int, addr string) {
+			defer wg.Done()
+			members[i] = checkZKMember(ctx, addr, target.Timeout)
+		}(i, zkAddress(host))
+	}
+	wg.Wait()
+
+	expected := getIntConfig(target.Config, "expected_members", len(hosts))
+	quorum := expected/2 + 1
+	var (
+		warnings    []string
+		leaders     []*zkMember
+		serving     int
+		voting      int
+		outstanding int64
+		avgLatency  float64
+		maxLatency  float64
+	)
+	for _, m := range members {
+		if m.Error != "" {
+			warnings = append(warnings, fmt.Sprintf("成员 %s 不可用: %s", m.Address, m.Error))
+			continue
+		}
+		switch m.Mode {
+		case "leader", "standalone":
+			leaders = append(leaders, m)
+			voting++
+		case "follower":
+			voting++
+		case "observer":
+		default:
+			warnings = append(warnings, fmt.Sprintf("成员 %s 未返回运行模式，请检查 4lw.commands.whitelist 是否包含 srvr 或 mntr", m.Address))
+			continue
+		}
+		serving++
+		if m.Outstanding > outstanding {
+			outstanding = m.Outstanding
+		}
+		if m.AvgLatency > avgLatency {
+			avgLatency = m.AvgLatency
+		}
+		if m.MaxLatency > maxLatency {
+			maxLatency = m.MaxLatency
+		}
+	}
+
+	metrics := map[string]any{
+		"members":              members,
+		"expected_members":     expected,
+		"serving_members":      serving,
+		"voting_members":       voting,
+		"quorum":               quorum,
+		"outstanding_requests": outstanding,
+		"avg_latency_ms":       avgLatency,
+		"max_latency_ms":       maxLatency,
+	}
+	result := func(success bool, format string, args ...any) (*ProbeResult, error) {
+		return &ProbeResult{
+			Success:   success,
+			Latency:   time.Since(start),
+			Message:   fmt.Sprintf(format, args...),
+			Metrics:   metrics,
+			Warnings:  warnings,
+			CheckedAt: time.Now(),
+		}, nil
+	}
+
+	if len(leaders) == 0 {
+		if serving == 0 {
+			return result(false, "ZooKeeper 集群没有可用成员")
+		}
+		return result(false, "ZooKeeper 集群没有 Leader")
+	}
+	if len(leaders) > 1 {
+		addrs := make([]string, len(leaders))
+		for i, m := range leaders {
+			addrs[i] = m.Address
+		}
+		return result(false, "检测到多个 Leader: %s", strings.Join(addrs, ", "))
+	}
+
+	leader := leaders[0]
+	metrics["leader"] = leader.Address
+	metrics["znode_count"] = leader.NodeCount
+	if leader.Mode == "leader" {
+		metrics["followers"] = leader.Followers
+		// 未配置全部成员时以 Leader 上报的已同步 Follower 数计算投票成员
+		if leader.hasSynced {
+			metrics["synced_followers"] = leader.SyncedFollowers
+			if int(leader.SyncedFollowers)+1 > voting {
+				voting = int(leader.SyncedFollowers) + 1
+				metrics["voting_members"] = voting
+			}
+		}
+	}
+	if voting < quorum {
+		return result(false, "ZooKeeper 集群失去法定人数：在线投票成员 %d 个，需要 %d 个", voting, quorum)
+	}
+
+	if threshold := getIntConfig(target.Config, "outstanding_threshold", 0); threshold > 0 && outstanding > int64(threshold) {
+		warnings = append(warnings, fmt.Sprintf("积压请求数 %d 超过阈值 %d", outstanding, threshold))
+	}
+	if threshold := getFloatConfig(target.Config, "latency_threshold_ms", 0); threshold > 0 && avgLatency > threshold {
+		warnings = append(warnings, fmt.Sprintf("平均请求延迟 %.1fms 超过阈值 %.0fms", avgLatency, threshold))
+	}
+
+	if getBoolConfig(target.Config, "session_check", true) {
+		sessionStart := time.Now()
+		if err := checkZKSession(ctx, leader.Address, target.Timeout); err != nil {
+			return result(false, "建立 ZooKeeper 会话失败: %v", err)
+		}
+		metrics["session_ms"] = time.Since(sessionStart).Milliseconds()
+	}
+
+	return result(true, "ZooKeeper 集群正常，Leader %s，%d/%d 个投票成员在线", leader.Address, voting, expected)
+}
+
+// Validate 验证目标配置
+func (p *ZooKeeperProber) Validate(target Target) error {
+	if len(getStringSliceConfig(target.Config, "hosts")) == 0 {
+		return fmt.Errorf("缺少必填字段: hosts")
+	}
+	if expected, ok := target.Config["expected_members"]; ok && expected != nil {
+		if getIntConfig(target.Config, "expected_members", 0) <= 0 {
+			return fmt.Errorf("集群投票成员数必须大于 0")
+		}
+	}
+	return nil
+}
+
+// zkAddress 补全成员地址的默认端口
+func zkAddress(host string) string {
+	if _, _, err := net.SplitHostPort(host); err == nil {
+		return host
+	}
+	return net.JoinHostPort(host, strconv.Itoa(zkDefaultPort))
+}
+
+// checkZKMember 使用四字命令检查单个成员
+// ruok 与 mntr 可能未加入白名单，仅 srvr 与 mntr 都不可用时无法获取运行模式
+func checkZKMember(ctx context.Context, addr string, timeout time.Duration) *zkMember {
+	m := &zkMember{Address: addr}
+
+	resp, err := zkFourLetterWord(ctx, addr, "ruok", timeout)
+	if err != nil {
+		m.Error = err.Error()
+		return m
+	}
+	m.Ruok = strings.TrimSpace(resp) == "imok"
+
+	if resp, err = zkFourLetterWord(ctx, addr, "srvr", timeout); err == nil {
+		if strings.Contains(resp, zkNotServing) {
+			m.Error = "成员未在提供服务（可能正在选举或已与集群断开）"
+			return m
+		}
+		parseZKSrvr(m, resp)
+	}
+	if resp, err = zkFourLetterWord(ctx, addr, "mntr", timeout); err == nil {
+		if strings.Contains(resp, zkNotServing) {
+			m.Error = "成员未在提供服务（可能正在选举或已与集群断开）"
+			return m
+		}
+		parseZKMntr(m, resp)
+	}
+	return m
+}
+
+// zkFourLetterWord 发送四字命令并读取完整响应，服务端在响应后关闭连接
+func zkFourLetterWord(ctx context.Context, addr, cmd string, timeout time.Duration) (string, error) {
+	conn, err := zkDial(ctx, addr, timeout)
+	if err != nil {
+		return "", err
+	}
+	defer conn.Close()
+
+	if _, err := conn.Write([]byte(cmd)); err != nil {
+		return "", fmt.Errorf("发送 %s 失败: %v", cmd, err)
+	}
+	data, err := io.ReadAll(conn)
+	if err != nil {
+		return "", fmt.Errorf("读取 %s 响应失败: %v", cmd, err)
+	}
+	return string(data), nil
+}
+
+// zkDial 建立连接并按探测超时设置读写截止时间
+func zkDial(ctx context.Context, addr string, timeout time.Duration) (net.Conn, error) {
+	dialer := net.Dialer{Timeout: timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("连接失败: %v", err)
+	}
+	deadline, ok := ctx.Deadline()
+	if !ok && timeout > 0 {
+		deadline, ok = time.Now().Add(timeout), true
+	}
+	if ok {
+		if err := conn.SetDeadline(deadline); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("设置超时失败: %v", err)
+		}
+	}
+	return conn, nil
+}
+
+// parseZKSrvr 解析 srvr 输出，如 "Mode: follower"、"Latency min/avg/max: 0/0.5/12"
+func parseZKSrvr(m *zkMember, resp string) {
+	scanner := bufio.NewScanner(strings.NewReader(resp))
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(scanner.Text(), ":")
+		if !ok {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		switch key {
+		case "Mode":
+			m.Mode = value
+		case "Zxid":
+			m.Zxid = value
+		case "Outstanding":
+			m.Outstanding, _ = strconv.ParseInt(value, 10, 64)
+		case "Node count":
+			m.NodeCount, _ = strconv.ParseInt(value, 10, 64)
+		case "Latency min/avg/max":
+			if parts := strings.Split(value, "/"); len(parts) == 3 {
+				m.AvgLatency, _ = strconv.ParseFloat(parts[1], 64)
+				m.MaxLatency, _ = strconv.ParseFloat(parts[2], 64)
+			}
+		}
+	}
+}
+
+// parseZKMntr 解析 mntr 输出（制表符分隔的键值对），Follower 数量仅 Leader 上报
+func parseZKMntr(m *zkMember, resp string) {
+	scanner := bufio.NewScanner(strings.NewReader(resp))
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(scanner.Text(), "\t")
+		if !ok {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		switch key {
+		case "zk_server_state":
+			m.Mode = value
+		case "zk_outstanding_requests":
+			m.Outstanding, _ = strconv.ParseInt(value, 10, 64)
+		case "zk_znode_count":
+			m.NodeCount, _ = strconv.ParseInt(value, 10, 64)
+		case "zk_avg_latency":
+			m.AvgLatency, _ = strconv.ParseFloat(value, 64)
+		case "zk_max_latency":
+			m.MaxLatency, _ = strconv.ParseFloat(value, 64)
+		case "zk_followers":
+			m.Followers, _ = strconv.ParseInt(value, 10, 64)
+		case "zk_synced_followers":
+			m.SyncedFollowers, _ = strconv.ParseInt(value, 10, 64)
+			m.hasSynced = true
+		}
+	}
+}
+
+// checkZKSession 按 ZooKeeper 客户端协议建立会话后立即关闭
+func checkZKSession(ctx context.Context, addr string, timeout time.Duration) error {
+	conn, err := zkDial(ctx, addr, timeout)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	// ConnectRequest: protocolVersion, lastZxidSeen, timeOut, sessionId, passwd, readOnly
+	sessionTimeout := int32(timeout.Milliseconds())
+	if sessionTimeout < 4000 {
+		sessionTimeout = 4000
+	}
+	req := binary.BigEndian.AppendUint32(nil, 0)
+	req = binary.BigEndian.AppendUint64(req, 0)
+	req = binary.BigEndian.AppendUint32(req, uint32(sessionTimeout))
+	req = binary.BigEndian.AppendUint64(req, 0)
+	req = binary.BigEndian.AppendUint32(req, 16)
+	req = append(req, make([]byte, 16)...)
+	req = append(req, 0)
+	if err := zkWritePacket(conn, req); err != nil {
+		return err
+	}
+
+	// ConnectResponse: protocolVersion, timeOut, sessionId, passwd
+	resp, err := zkReadPacket(conn)
+	if err != nil {
+		return fmt.Errorf("读取会话响应失败: %v", err)
+	}
+	if len(resp) < 16 {
+		return fmt.Errorf("会话响应格式错误")
+	}
+	if negotiated := int32(binary.BigEndian.Uint32(resp[4:8])); negotiated <= 0 {
+		return fmt.Errorf("服务器拒绝了会话")
+	}
+
+	// closeSession: xid, type=-11
+	closeReq := binary.BigEndian.AppendUint32(nil, 1)
+	closeReq = binary.BigEndian.AppendUint32(closeReq, 0xfffffff5) // -11 的补码
+	if err := zkWritePacket(conn, closeReq); err != nil {
+		return err
+	}
+	// ReplyHeader: xid, zxid, err
+	if resp, err = zkReadPacket(conn); err != nil {
+		return fmt.Errorf("关闭会话失败: %v", err)
+	}
+	if len(resp) >= 16 {
+		if code := int32(binary.BigEndian.Uint32(resp[12:16])); code != 0 {
+			return fmt.Errorf("关闭会话失败，错误码 %d", code)
+		}
+	}
+	return nil
+}
+
+// zkWritePacket 写入带 4 字节长度前缀的数据包
+func zkWritePacket(w io.Writer, payload []byte) error {
+	packet := binary.BigEndian.AppendUint32(nil, uint32(len(payload)))
+	if _, err := w.Write(append(packet, payload...)); err != nil {
+		return fmt.Errorf("发送请求失败: %v", err)
+	}
+	return nil
+}
+
+// zkReadPacket 读取带 4 字节长度前缀的数据包
+func zkReadPacket(r io.Reader) ([]byte, error) {
+	var header [4]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return nil, err
+	}
+	n := binary.BigEndian.Uint32(header[:])
+	if n > 1<<20 {
+		return nil, fmt.Errorf("数据包过大: %d 字节", n)
+	}
+	payload := make([]byte, n)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return nil, err
+	}
+	return payload, nil
+}
@@ -0,0 +1,310 @@
+package prober
+
+import (
+	"encoding/binary"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+const zkSrvrFollower = `Zookeeper version: 3.8.1-74db005175a4ec545697012f9069cb9dcc8cdda7, built on 2023-01-25 16:31 UTC
+Latency min/avg/max: 0/0.4321/12
+Received: 1024
+Sent: 1023
+Connections: 4
+Outstanding: 3
+Zxid: 0x100000a2c
+Mode: follower
+Node count: 187
+`
+
+const zkSrvrLeader = `Zookeeper version: 3.8.1-74db005175a4ec545697012f9069cb9dcc8cdda7, built on 2023-01-25 16:31 UTC
+Latency min/avg/max: 0/1.5/40
+Received: 2048
+Sent: 2047
+Connections: 6
+Outstanding: 0
+Zxid: 0x100000a2c
+Mode: leader
+Node count: 187
+Proposal sizes last/min/max: 48/36/512
+`
+
+const zkMntrLeader = "zk_version\t3.8.1-74db005175a4ec545697012f9069cb9dcc8cdda7, built on 2023-01-25 16:31 UTC\n" +
+	"zk_server_state\tleader\n" +
+	"zk_avg_latency\t1.5\n" +
+	"zk_max_latency\t40\n" +
+	"zk_outstanding_requests\t0\n" +
+	"zk_znode_count\t187\n" +
+	"zk_followers\t2\n" +
+	"zk_synced_followers\t2\n"
+
+const zkMntrObserver = "zk_version\t3.8.1\n" +
+	"zk_server_state\tobserver\n" +
+	"zk_avg_latency\t0.25\n" +
+	"zk_max_latency\t3\n" +
+	"zk_outstanding_requests\t7\n" +
+	"zk_znode_count\t187\n"
+
+const zkNotServingResponse = "This ZooKeeper instance is not currently serving requests\n"
+
+func TestParseZKSrvr(t *testing.T) {
+	m := &zkMember{}
+	parseZKSrvr(m, zkSrvrFollower)
+	if m.Mode != "follower" || m.Zxid != "0x100000a2c" || m.Outstanding != 3 || m.NodeCount != 187 {
+		t.Fatalf("follower 解析结果 %+v", m)
+	}
+	if m.AvgLatency != 0.4321 || m.MaxLatency != 12 {
+		t.Fatalf("延迟解析结果 avg=%v max=%v", m.AvgLatency, m.MaxLatency)
+	}
+
+	m = &zkMember{}
+	parseZKSrvr(m, zkSrvrLeader)
+	if m.Mode != "leader" || m.AvgLatency != 1.5 || m.MaxLatency != 40 {
+		t.Fatalf("leader 解析结果 %+v", m)
+	}
+
+	// 未在提供服务的成员没有运行模式
+	m = &zkMember{}
+	parseZKSrvr(m, zkNotServingResponse)
+	if m.Mode != "" {
+		t.Fatalf("未提供服务时 Mode = %q", m.Mode)
+	}
+}
+
+func TestParseZKMntr(t *testing.T) {
+	m := &zkMember{}
+	parseZKMntr(m, zkMntrLeader)
+	if m.Mode != "leader" || m.Followers != 2 || m.SyncedFollowers != 2 || !m.hasSynced {
+		t.Fatalf("leader 解析结果 %+v", m)
+	}
+	if m.AvgLatency != 1.5 || m.MaxLatency != 40 || m.NodeCount != 187 {
+		t.Fatalf("leader 指标解析结果 %+v", m)
+	}
+
+	m = &zkMember{}
+	parseZKMntr(m, zkMntrObserver)
+	if m.Mode != "observer" || m.Outstanding != 7 || m.hasSynced {
+		t.Fatalf("observer 解析结果 %+v", m)
+	}
+
+	m = &zkMember{}
+	parseZKMntr(m, zkNotServingResponse)
+	if m.Mode != "" {
+		t.Fatalf("未提供服务时 Mode = %q", m.Mode)
+	}
+}
+
+// newZKTestMember 启动按四字命令返回固定响应的成员，未列出的命令返回白名单错误；
+// 以长度前缀开头的连接按客户端协议建立并关闭会话
+func newZKTestMember(t *testing.T, responses map[string]string) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go serveZKTestConn(conn, responses)
+		}
+	}()
+	return ln.Addr().String()
+}
+
+func serveZKTestConn(conn net.Conn, responses map[string]string) {
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+	cmd := make([]byte, 4)
+	if _, err := io.ReadFull(conn, cmd); err != nil {
+		return
+	}
+	if cmd[0] != 0 {
+		resp, ok := responses[string(cmd)]
+		if !ok {
+			resp = string(cmd) + " is not executed because it is not in the whitelist.\n"
+		}
+		conn.Write([]byte(resp))
+		return
+	}
+
+	// ConnectRequest 固定为 45 字节
+	if binary.BigEndian.Uint32(cmd) != 45 {
+		return
+	}
+	if _, err := io.ReadFull(conn, make([]byte, 45)); err != nil {
+		return
+	}
+	resp := binary.BigEndian.AppendUint32(nil, 0)
+	resp = binary.BigEndian.AppendUint32(resp, 4000)
+	resp = binary.BigEndian.AppendUint64(resp, 0x1234)
+	resp = binary.BigEndian.AppendUint32(resp, 16)
+	resp = append(resp, make([]byte, 16)...)
+	if zkWritePacket(conn, resp) != nil {
+		return
+	}
+	if req, err := zkReadPacket(conn); err != nil || len(req) != 8 {
+		return
+	}
+	reply := binary.BigEndian.AppendUint32(nil, 1)
+	reply = binary.BigEndian.AppendUint64(reply, 0x100000a2d)
+	reply = binary.BigEndian.AppendUint32(reply, 0)
+	zkWritePacket(conn, reply)
+}
+
+// zkDownMember 返回已关闭端口的地址
+func zkDownMember(t *testing.T) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	ln.Close()
+	return addr
+}
+
+func TestZooKeeperQuorum(t *testing.T) {
+	leader := map[string]string{"ruok": "imok", "srvr": zkSrvrLeader, "mntr": zkMntrLeader}
+	leaderSrvr := map[string]string{"ruok": "imok", "srvr": zkSrvrLeader} // 不上报已同步 Follower 数
+	follower := map[string]string{"ruok": "imok", "srvr": zkSrvrFollower}
+	observer := map[string]string{"ruok": "imok", "mntr": zkMntrObserver}
+	notServing := map[string]string{"ruok": "imok", "srvr": zkNotServingResponse, "mntr": zkNotServingResponse}
+	noWhitelist := map[string]string{"ruok": "imok"}
+
+	tests := []struct {
+		name        string
+		members     []map[string]string // nil 表示成员不可达
+		expected    int                 // expected_members，0 表示不配置
+		session     bool
+		wantSuccess bool
+		wantMessage string
+		wantVoting  int
+		wantWarns   int
+	}{
+		{
+			name:        "Leader 与两个 Follower 在线并建立会话",
+			members:     []map[string]string{leader, follower, follower},
+			session:     true,
+			wantSuccess: true,
+			wantMessage: "3/3 个投票成员在线",
+			wantVoting:  3,
+		},
+		{
+			name:        "一个成员不可达时仍满足法定人数",
+			members:     []map[string]string{leaderSrvr, follower, nil},
+			wantSuccess: true,
+			wantMessage: "2/3 个投票成员在线",
+			wantVoting:  2,
+			wantWarns:   1,
+		},
+		{
+			name:        "多数成员未在提供服务",
+			members:     []map[string]string{leaderSrvr, notServing, notServing},
+			wantMessage: "失去法定人数：在线投票成员 1 个，需要 2 个",
+			wantVoting:  1,
+			wantWarns:   2,
+		},
+		{
+			name:        "Observer 不计入投票成员",
+			members:     []map[string]string{leaderSrvr, observer, observer},
+			wantMessage: "失去法定人数：在线投票成员 1 个，需要 2 个",
+			wantVoting:  1,
+		},
+		{
+			name:        "只配置 Leader 时按已同步 Follower 计算投票成员",
+			members:     []map[string]string{leader},
+			expected:    3,
+			wantSuccess: true,
+			wantMessage: "3/3 个投票成员在线",
+			wantVoting:  3,
+		},
+		{
+			name:        "没有 Leader",
+			members:     []map[string]string{follower, follower, notServing},
+			wantMessage: "没有 Leader",
+			wantVoting:  2,
+			wantWarns:   1,
+		},
+		{
+			name:        "多个 Leader",
+			members:     []map[string]string{leader, leader, follower},
+			wantMessage: "检测到多个 Leader",
+			wantVoting:  3,
+		},
+		{
+			name:        "全部成员不可达",
+			members:     []map[string]string{nil, nil, nil},
+			wantMessage: "没有可用成员",
+			wantWarns:   3,
+		},
+		{
+			name:        "四字命令未加入白名单",
+			members:     []map[string]string{noWhitelist},
+			wantMessage: "没有可用成员",
+			wantWarns:   1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var hosts []string
+			for _, responses := range tt.members {
+				if responses == nil {
+					hosts = append(hosts, zkDownMember(t))
+				} else {
+					hosts = append(hosts, newZKTestMember(t, responses))
+				}
+			}
+			config := map[string]any{"hosts": strings.Join(hosts, ","), "session_check": tt.session}
+			if tt.expected > 0 {
+				config["expected_members"] = tt.expected
+			}
+
+			result, err := NewZooKeeperProber().Probe(testContext(t), Target{Timeout: 2 * time.Second, Config: config})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.Success != tt.wantSuccess || !strings.Contains(result.Message, tt.wantMessage) {
+				t.Fatalf("结果 %v %q，期望 %v 并包含 %q", result.Success, result.Message, tt.wantSuccess, tt.wantMessage)
+			}
+			if got := result.Metrics["voting_members"]; got != tt.wantVoting {
+				t.Fatalf("voting_members = %v, want %d", got, tt.wantVoting)
+			}
+			if len(result.Warnings) != tt.wantWarns {
+				t.Fatalf("警告 %q，期望 %d 条", result.Warnings, tt.wantWarns)
+			}
+			if _, ok := result.Metrics["session_ms"]; ok != (tt.session && tt.wantSuccess) {
+				t.Fatalf("session_ms 存在 = %v", ok)
+			}
+		})
+	}
+}
+
+func TestZooKeeperThresholdWarnings(t *testing.T) {
+	hosts := strings.Join([]string{
+		newZKTestMember(t, map[string]string{"ruok": "imok", "srvr": zkSrvrLeader}),
+		newZKTestMember(t, map[string]string{"ruok": "imok", "srvr": zkSrvrFollower}),
+	}, ",")
+	result, err := NewZooKeeperProber().Probe(testContext(t), Target{Timeout: 2 * time.Second, Config: map[string]any{
+		"hosts":                 hosts,
+		"session_check":         false,
+		"outstanding_threshold": 2,
+		"latency_threshold_ms":  1,
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.Success || len(result.Warnings) != 2 {
+		t.Fatalf("结果 %v %q，警告 %q", result.Success, result.Message, result.Warnings)
+	}
+	if result.Metrics["outstanding_requests"] != int64(3) || result.Metrics["avg_latency_ms"] != 1.5 {
+		t.Fatalf("应取各成员的最大值: %v", result.Metrics)
+	}
+}
@@ -2,12 +2,17 @@ package repository
 
 import (
 	"context"
+	"encoding/json"
 	"time"
 
 	"github.com/thingsboard-rxprobe/internal/model"
+	"gorm.io/datatypes"
 	"gorm.io/gorm"
 )
 
+// unresolvedStatuses 未恢复的告警状态
+var unresolvedStatuses = []model.AlertStatus{model.AlertStatusFiring, model.AlertStatusAcknowledged}
+
 // AlertRepository 告警仓库
 type AlertRepository struct {
 	db *gorm.DB
@@ -28,10 +33,110 @@ func (r *AlertRepository) UpdateRecord(ctx context.Context, record *model.AlertR
 	return r.db.WithContext(ctx).Save(record).Error
 }
 
+// UpdateFiringRecord 更新未恢复告警的最新探测信息与通知状态
+// 只更新告警处理流程会修改的字段，避免覆盖并发写入的确认、指派等人工操作
+func (r *AlertRepository) UpdateFiringRecord(ctx context.Context, record *model.AlertRecord) error {
+	columns := []string{"target_name", "target_type", "message", "latency_ms", "in_maintenance", "maintenance_id", "suppressed_by", "severity"}
+	// 通知状态只在本次已通知时写入，避免覆盖后台发送（重试、排队）期间回写的通知状态
+	if record.Notified {
+		columns = append(columns, "notified", "last_notified_at")
+	}
+	return r.db.WithContext(ctx).Model(record).Select(columns).Updates(record).Error
+}
+
+// Acknowledge 确认告警，仅对告警中的记录生效，返回是否确认成功
+func (r *AlertRepository) Acknowledge(ctx context.Context, id uint64, by string, at time.Time, comment string) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&model.AlertRecord{}).
+		Where("id = ? AND status = ?", id, model.AlertStatusFiring).
+		Updates(map[string]any{
+			"status":          model.AlertStatusAcknowledged,
+			"acknowledged_by": by,
+			"acknowledged_at": at,
+			"ack_comment":     comment,
+		})
+	return result.RowsAffected == 1, result.Error
+}
+
+// Assign 指派告警处理人，assignee 为空表示取消指派
+func (r *AlertRepository) Assign(ctx context.Context, id uint64, assignee string) error {
+	return r.db.WithContext(ctx).Model(&model.AlertRecord{}).
+		Where("id = ?", id).
+		Update("assignee", assignee).Error
+}
+
+// CreateActivity 创建告警活动记录
+func (r *AlertRepository) CreateActivity(ctx context.Context, activity *model.AlertActivity) error {
+	return r.db.WithContext(ctx).Create(activity).Error
+}
+
+// ListActivities 获取告警记录的活动时间线
+func (r *AlertRepository) ListActivities(ctx context.Context, alertRecordID uint64) ([]*model.AlertActivity, error) {
+	var activities []*model.AlertActivity
+	err := r.db.WithContext(ctx).
+		Where("alert_record_id = ?", alertRecordID).
+		Order("created_at ASC, id ASC").
+		Find(&activities).Error
+	if err != nil {
+		return nil, err
+	}
+	return activities, nil
+}
+
+// AdvanceEscalation 将告警记录的升级级别从 from 推进到 to，返回是否推进成功
+// 升级检查任务与告警处理可能同时推进同一记录，只有推进成功的一方发送升级通知
+func (r *AlertRepository) AdvanceEscalation(ctx context.Context, id uint64, from, to int) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&model.AlertRecord{}).
+		Where("id = ? AND escalation_level = ?", id, from).
+		Update("escalation_level", to)
+	return result.RowsAffected == 1, result.Error
+}
+
+// UpdateImpactedIDs 更新根因告警已通知的受影响下游告警记录 ID 列表
+func (r *AlertRepository) UpdateImpactedIDs(ctx context.Context, id uint64, impactedIDs []uint64) error {
+	data, err := json.Marshal(impactedIDs)
+	if err != nil {
+		return err
+	}
+	return r.db.WithContext(ctx).Model(&model.AlertRecord{}).
+		Where("id = ?", id).
+		Update("impacted_ids", datatypes.JSON(data)).Error
+}
+
+// ListByStatus 获取指定状态的所有告警记录
+func (r *AlertRepository) ListByStatus(ctx context.Context, status model.AlertStatus) ([]*model.AlertRecord, error) {
+	var records []*model.AlertRecord
+	err := r.db.WithContext(ctx).Where("status = ?", status).Order("fired_at ASC").Find(&records).Error
+	if err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+// ListUnresolved 获取所有未恢复（告警中或已确认）的告警记录
+func (r *AlertRepository) ListUnresolved(ctx context.Context) ([]*model.AlertRecord, error) {
+	var records []*model.AlertRecord
+	err := r.db.WithContext(ctx).Where("status IN ?", unresolvedStatuses).Order("fired_at ASC").Find(&records).Error
+	if err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+// MarkNotified 标记告警记录已成功发送通知
+func (r *AlertRepository) MarkNotified(ctx context.Context, id uint64, notifiedAt time.Time) error {
+	return r.db.WithContext(ctx).Model(&model.AlertRecord{}).
+		Where("id = ?", id).
+		Updates(map[string]any{
+			"notified":         true,
+			"last_notified_at": notifiedAt,
+		}).Error
+}
+
 // AlertRecordQuery 告警记录查询参数
 type AlertRecordQuery struct {
 	TargetID  uint64
 	Status    string
+	Severity  string
 	StartTime *time.Time
 	EndTime   *time.Time
 	Page      int
@@ -51,6 +156,9 @@ func (r *AlertRepository) ListRecords(ctx context.Context, query AlertRecordQuer
 	if query.Status != "" {
 		db = db.Where("status = ?", query.Status)
 	}
+	if query.Severity != "" {
+		db = db.Where("severity = ?", query.Severity)
+	}
 	if query.StartTime != nil {
 		db = db.Where("fired_at >= ?", query.StartTime)
 	}
@@ -84,11 +192,11 @@ func (r *AlertRepository) GetRecordByID(ctx context.Context, id uint64) (*model.
 	return &record, nil
 }
 
-// GetLastFiringRecord 获取目标最后一条未恢复的告警记录
+// GetLastFiringRecord 获取目标最后一条未恢复（告警中或已确认）的告警记录
 func (r *AlertRepository) GetLastFiringRecord(ctx context.Context, targetID uint64) (*model.AlertRecord, error) {
 	var record model.AlertRecord
 	err := r.db.WithContext(ctx).
-		Where("target_id = ? AND status = ?", targetID, model.AlertStatusFiring).
+		Where("target_id = ? AND status IN ?", targetID, unresolvedStatuses).
 		Order("fired_at DESC").
 		First(&record).Error
 	if err != nil {
@@ -135,21 +243,37 @@ func (r *AlertRepository) CountByStatus(ctx context.Context) (map[string]int64,
 	return counts, nil
 }
 
-// DeleteOld 删除旧的告警记录
+// DeleteOld 删除旧的告警记录及其活动记录、通知投递记录
 func (r *AlertRepository) DeleteOld(ctx context.Context, retentionDays int) (int64, error) {
 	cutoff := time.Now().AddDate(0, 0, -retentionDays)
-	result := r.db.WithContext(ctx).
-		Where("fired_at < ?", cutoff).
-		Delete(&model.AlertRecord{})
-	return result.RowsAffected, result.Error
+	return r.deleteRecords(ctx, "fired_at < ?", cutoff)
 }
 
-// DeleteByTargetID 删除指定目标的所有告警记录
+// DeleteByTargetID 删除指定目标的所有告警记录及其活动记录、通知投递记录
 func (r *AlertRepository) DeleteByTargetID(ctx context.Context, targetID uint64) (int64, error) {
-	result := r.db.WithContext(ctx).
-		Where("target_id = ?", targetID).
-		Delete(&model.AlertRecord{})
-	return result.RowsAffected, result.Error
+	return r.deleteRecords(ctx, "target_id = ?", targetID)
+}
+
+// deleteRecords 按条件删除告警记录及其活动记录、通知投递与投递尝试记录
+func (r *AlertRepository) deleteRecords(ctx context.Context, query string, args ...any) (int64, error) {
+	var deleted int64
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		ids := tx.Model(&model.AlertRecord{}).Select("id").Where(query, args...)
+		deliveryIDs := tx.Model(&model.NotificationDelivery{}).Select("id").Where("alert_record_id IN (?)", ids)
+		if err := tx.Where("delivery_id IN (?)", deliveryIDs).Delete(&model.NotificationAttempt{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("alert_record_id IN (?)", ids).Delete(&model.NotificationDelivery{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("alert_record_id IN (?)", ids).Delete(&model.AlertActivity{}).Error; err != nil {
+			return err
+		}
+		result := tx.Where(query, args...).Delete(&model.AlertRecord{})
+		deleted = result.RowsAffected
+		return result.Error
+	})
+	return deleted, err
 }
 
 // HasUnresolvedAlert 检查目标是否有未恢复的告警记录
@@ -157,7 +281,7 @@ func (r *AlertRepository) DeleteByTargetID(ctx context.Context, targetID uint64)
 func (r *AlertRepository) HasUnresolvedAlert(ctx context.Context, targetID uint64) bool {
 	var count int64
 	r.db.WithContext(ctx).Model(&model.AlertRecord{}).
-		Where("target_id = ? AND status = ?", targetID, model.AlertStatusFiring).
+		Where("target_id = ? AND status IN ?", targetID, unresolvedStatuses).
 		Count(&count)
 	return count > 0
 }
@@ -0,0 +1,285 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/thingsboard-rxprobe/internal/model"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// DeliveryRepository 通知投递仓库
+type DeliveryRepository struct {
+	db *gorm.DB
+}
+
+// NewDeliveryRepository 创建通知投递仓库
+func NewDeliveryRepository(db *gorm.DB) *DeliveryRepository {
+	return &DeliveryRepository{db: db}
+}
+
+// Create 创建投递记录
+func (r *DeliveryRepository) Create(ctx context.Context, delivery *model.NotificationDelivery) error {
+	return r.db.WithContext(ctx).Create(delivery).Error
+}
+
+// CreateBatch 创建一批投递记录；多于一条时作为同一条合并通知的批次，BatchID 设为批次中首条投递的 ID
+func (r *DeliveryRepository) CreateBatch(ctx context.Context, deliveries []*model.NotificationDelivery) error {
+	if len(deliveries) == 1 {
+		return r.Create(ctx, deliveries[0])
+	}
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(deliveries).Error; err != nil {
+			return err
+		}
+		batchID := deliveries[0].ID
+		ids := make([]uint64, 0, len(deliveries))
+		for _, delivery := range deliveries {
+			delivery.BatchID = batchID
+			ids = append(ids, delivery.ID)
+		}
+		return tx.Model(&model.NotificationDelivery{}).Where("id IN ?", ids).Update("batch_id", batchID).Error
+	})
+}
+
+// Update 更新投递记录
+func (r *DeliveryRepository) Update(ctx context.Context, delivery *model.NotificationDelivery) error {
+	return r.db.WithContext(ctx).Omit("Attempts").Save(delivery).Error
+}
+
+// GetByID 根据 ID 获取投递记录
+func (r *DeliveryRepository) GetByID(ctx context.Context, id uint64) (*model.NotificationDelivery, error) {
+	var delivery model.NotificationDelivery
+	err := r.db.WithContext(ctx).First(&delivery, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &delivery, nil
+}
+
+// ListByAlertRecord 获取告警记录的所有投递记录（含尝试记录）
+func (r *DeliveryRepository) ListByAlertRecord(ctx context.Context, alertRecordID uint64) ([]*model.NotificationDelivery, error) {
+	var deliveries []*model.NotificationDelivery
+	err := r.db.WithContext(ctx).
+		Preload("Attempts", func(db *gorm.DB) *gorm.DB {
+			return db.Order("attempt ASC")
+		}).
+		Where("alert_record_id = ?", alertRecordID).
+		Order("created_at ASC").
+		Find(&deliveries).Error
+	if err != nil {
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+// ListDue 获取到期待重试的投递记录
+func (r *DeliveryRepository) ListDue(ctx context.Context, now time.Time, limit int) ([]*model.NotificationDelivery, error) {
+	var deliveries []*model.NotificationDelivery
+	err := r.db.WithContext(ctx).
+		Where("status = ? AND next_attempt_at <= ?", model.DeliveryStatusPending, now).
+		Order("next_attempt_at ASC").
+		Limit(limit).
+		Find(&deliveries).Error
+	if err != nil {
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+// Claim 将投递记录从指定状态切换为发送中，返回抢占到的投递记录，未抢占到时为空
+// 属于合并通知批次的投递连同批次中处于同一状态的其他投递一起抢占（已被取代的不再发送）；
+// 用于避免重试任务与手动重发同时发送同一条通知
+func (r *DeliveryRepository) Claim(ctx context.Context, delivery *model.NotificationDelivery, from model.DeliveryStatus) ([]*model.NotificationDelivery, error) {
+	if delivery.BatchID == 0 {
+		result := r.db.WithContext(ctx).Model(&model.NotificationDelivery{}).
+			Where("id = ? AND status = ?", delivery.ID, from).
+			Update("status", model.DeliveryStatusSending)
+		if result.Error != nil || result.RowsAffected == 0 {
+			return nil, result.Error
+		}
+		delivery.Status = model.DeliveryStatusSending
+		return []*model.NotificationDelivery{delivery}, nil
+	}
+
+	var claimed []*model.NotificationDelivery
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var ids []uint64
+		err := tx.Model(&model.NotificationDelivery{}).
+			Where("batch_id = ? AND status = ?", delivery.BatchID, from).
+			Pluck("id", &ids).Error
+		if err != nil || len(ids) == 0 {
+			return err
+		}
+		result := tx.Model(&model.NotificationDelivery{}).
+			Where("id IN ? AND status = ?", ids, from).
+			Update("status", model.DeliveryStatusSending)
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		return tx.Where("id IN ? AND status = ?", ids, model.DeliveryStatusSending).Order("id ASC").Find(&claimed).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return claimed, nil
+}
+
+// Supersede 将同一告警记录在同一渠道上等待重试或排队的投递标记为已取代，返回被取代的投递所属的合并通知批次 ID
+func (r *DeliveryRepository) Supersede(ctx context.Context, alertRecordID, channelID uint64) ([]uint64, error) {
+	var batchIDs []uint64
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var superseded []*model.NotificationDelivery
+		err := tx.Select("id", "batch_id").
+			Where("alert_record_id = ? AND channel_id = ? AND status IN ?", alertRecordID, channelID,
+				[]model.DeliveryStatus{model.DeliveryStatusPending, model.DeliveryStatusQueued}).
+			Find(&superseded).Error
+		if err != nil || len(superseded) == 0 {
+			return err
+		}
+		ids := make([]uint64, 0, len(superseded))
+		for _, delivery := range superseded {
+			ids = append(ids, delivery.ID)
+			if delivery.BatchID > 0 {
+				batchIDs = append(batchIDs, delivery.BatchID)
+			}
+		}
+		return tx.Model(&model.NotificationDelivery{}).
+			Where("id IN ? AND status IN ?", ids,
+				[]model.DeliveryStatus{model.DeliveryStatusPending, model.DeliveryStatusQueued}).
+			Updates(map[string]any{
+				"status":          model.DeliveryStatusSuperseded,
+				"next_attempt_at": nil,
+			}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return batchIDs, nil
+}
+
+// ListUnsentBatch 获取合并通知批次中等待重试或排队的投递
+func (r *DeliveryRepository) ListUnsentBatch(ctx context.Context, batchID uint64) ([]*model.NotificationDelivery, error) {
+	var deliveries []*model.NotificationDelivery
+	err := r.db.WithContext(ctx).
+		Where("batch_id = ? AND status IN ?", batchID,
+			[]model.DeliveryStatus{model.DeliveryStatusPending, model.DeliveryStatusQueued}).
+		Order("id ASC").
+		Find(&deliveries).Error
+	if err != nil {
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+// UpdateUnsentPayload 替换尚未发送（等待重试或排队）的投递的通知内容
+func (r *DeliveryRepository) UpdateUnsentPayload(ctx context.Context, ids []uint64, payload []byte) error {
+	return r.db.WithContext(ctx).Model(&model.NotificationDelivery{}).
+		Where("id IN ? AND status IN ?", ids,
+			[]model.DeliveryStatus{model.DeliveryStatusPending, model.DeliveryStatusQueued}).
+		Update("payload", datatypes.JSON(payload)).Error
+}
+
+// CountQueued 统计渠道排队中的投递数
+func (r *DeliveryRepository) CountQueued(ctx context.Context, channelID uint64) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&model.NotificationDelivery{}).
+		Where("channel_id = ? AND status = ?", channelID, model.DeliveryStatusQueued).
+		Count(&count).Error
+	return count, err
+}
+
+// CountUnsentByAlertRecord 统计告警记录等待重试或排队中的投递数
+func (r *DeliveryRepository) CountUnsentByAlertRecord(ctx context.Context, alertRecordID uint64) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&model.NotificationDelivery{}).
+		Where("alert_record_id = ? AND status IN ?", alertRecordID,
+			[]model.DeliveryStatus{model.DeliveryStatusPending, model.DeliveryStatusQueued}).
+		Count(&count).Error
+	return count, err
+}
+
+// ListQueuedChannels 获取有排队投递的渠道 ID 列表
+func (r *DeliveryRepository) ListQueuedChannels(ctx context.Context) ([]uint64, error) {
+	var ids []uint64
+	err := r.db.WithContext(ctx).Model(&model.NotificationDelivery{}).
+		Where("status = ?", model.DeliveryStatusQueued).
+		Distinct().Pluck("channel_id", &ids).Error
+	return ids, err
+}
+
+// ListQueued 按排队顺序获取渠道排队中的投递
+func (r *DeliveryRepository) ListQueued(ctx context.Context, channelID uint64) ([]*model.NotificationDelivery, error) {
+	var deliveries []*model.NotificationDelivery
+	err := r.db.WithContext(ctx).
+		Where("channel_id = ? AND status = ?", channelID, model.DeliveryStatusQueued).
+		Order("id ASC").
+		Find(&deliveries).Error
+	if err != nil {
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+// Collapse 将排队中的投递合并为同一批次的汇总通知：BatchID 设为其中最小的投递 ID，内容替换为汇总内容
+// 返回合并后仍在排队的投递
+func (r *DeliveryRepository) Collapse(ctx context.Context, ids []uint64, payload []byte) ([]*model.NotificationDelivery, error) {
+	var collapsed []*model.NotificationDelivery
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("id IN ? AND status = ?", ids, model.DeliveryStatusQueued).
+			Order("id ASC").Find(&collapsed).Error; err != nil || len(collapsed) == 0 {
+			return err
+		}
+		batchID := collapsed[0].ID
+		queued := make([]uint64, 0, len(collapsed))
+		for _, delivery := range collapsed {
+			delivery.BatchID = batchID
+			delivery.Payload = payload
+			queued = append(queued, delivery.ID)
+		}
+		return tx.Model(&model.NotificationDelivery{}).
+			Where("id IN ?", queued).
+			Updates(map[string]any{
+				"batch_id": batchID,
+				"payload":  datatypes.JSON(payload),
+			}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return collapsed, nil
+}
+
+// ResetSending 将发送中的投递记录恢复为等待重试
+// 服务启动时调用，用于恢复上次异常退出时未完成的投递
+func (r *DeliveryRepository) ResetSending(ctx context.Context) (int64, error) {
+	result := r.db.WithContext(ctx).Model(&model.NotificationDelivery{}).
+		Where("status = ?", model.DeliveryStatusSending).
+		Updates(map[string]any{
+			"status":          model.DeliveryStatusPending,
+			"next_attempt_at": time.Now(),
+		})
+	return result.RowsAffected, result.Error
+}
+
+// CreateAttempt 创建投递尝试记录
+func (r *DeliveryRepository) CreateAttempt(ctx context.Context, attempt *model.NotificationAttempt) error {
+	return r.db.WithContext(ctx).Create(attempt).Error
+}
+
+// DeleteOld 删除旧的投递记录及其尝试记录
+func (r *DeliveryRepository) DeleteOld(ctx context.Context, retentionDays int) (int64, error) {
+	cutoff := time.Now().AddDate(0, 0, -retentionDays)
+	var deleted int64
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		oldIDs := tx.Model(&model.NotificationDelivery{}).Select("id").Where("created_at < ?", cutoff)
+		if err := tx.Where("delivery_id IN (?)", oldIDs).Delete(&model.NotificationAttempt{}).Error; err != nil {
+			return err
+		}
+		result := tx.Where("created_at < ?", cutoff).Delete(&model.NotificationDelivery{})
+		deleted = result.RowsAffected
+		return result.Error
+	})
+	return deleted, err
+}
@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/thingsboard-rxprobe/internal/model"
+	"gorm.io/gorm"
+)
+
+// EscalationRepository 升级策略仓库
+type EscalationRepository struct {
+	db *gorm.DB
+}
+
+// NewEscalationRepository 创建升级策略仓库
+func NewEscalationRepository(db *gorm.DB) *EscalationRepository {
+	return &EscalationRepository{db: db}
+}
+
+// Create 创建升级策略
+func (r *EscalationRepository) Create(ctx context.Context, policy *model.EscalationPolicy) error {
+	return r.db.WithContext(ctx).Create(policy).Error
+}
+
+// Update 更新升级策略
+func (r *EscalationRepository) Update(ctx context.Context, policy *model.EscalationPolicy) error {
+	return r.db.WithContext(ctx).Save(policy).Error
+}
+
+// Delete 删除升级策略
+func (r *EscalationRepository) Delete(ctx context.Context, id uint64) error {
+	return r.db.WithContext(ctx).Delete(&model.EscalationPolicy{}, id).Error
+}
+
+// GetByID 根据 ID 获取升级策略
+func (r *EscalationRepository) GetByID(ctx context.Context, id uint64) (*model.EscalationPolicy, error) {
+	var policy model.EscalationPolicy
+	err := r.db.WithContext(ctx).First(&policy, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &policy, nil
+}
+
+// List 获取升级策略列表
+func (r *EscalationRepository) List(ctx context.Context) ([]*model.EscalationPolicy, error) {
+	var policies []*model.EscalationPolicy
+	err := r.db.WithContext(ctx).Order("created_at DESC").Find(&policies).Error
+	if err != nil {
+		return nil, err
+	}
+	return policies, nil
+}
@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/thingsboard-rxprobe/internal/model"
+	"gorm.io/gorm"
+)
+
+// MaintenanceRepository 维护窗口仓库
+type MaintenanceRepository struct {
+	db *gorm.DB
+}
+
+// NewMaintenanceRepository 创建维护窗口仓库
+func NewMaintenanceRepository(db *gorm.DB) *MaintenanceRepository {
+	return &MaintenanceRepository{db: db}
+}
+
+// Create 创建维护窗口
+func (r *MaintenanceRepository) Create(ctx context.Context, window *model.MaintenanceWindow) error {
+	return r.db.WithContext(ctx).Create(window).Error
+}
+
+// Update 更新维护窗口
+func (r *MaintenanceRepository) Update(ctx context.Context, window *model.MaintenanceWindow) error {
+	return r.db.WithContext(ctx).Save(window).Error
+}
+
+// Delete 删除维护窗口
+func (r *MaintenanceRepository) Delete(ctx context.Context, id uint64) error {
+	return r.db.WithContext(ctx).Delete(&model.MaintenanceWindow{}, id).Error
+}
+
+// GetByID 根据 ID 获取维护窗口
+func (r *MaintenanceRepository) GetByID(ctx context.Context, id uint64) (*model.MaintenanceWindow, error) {
+	var window model.MaintenanceWindow
+	err := r.db.WithContext(ctx).First(&window, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &window, nil
+}
+
+// List 获取维护窗口列表
+func (r *MaintenanceRepository) List(ctx context.Context) ([]*model.MaintenanceWindow, error) {
+	var windows []*model.MaintenanceWindow
+	err := r.db.WithContext(ctx).Order("created_at DESC").Find(&windows).Error
+	if err != nil {
+		return nil, err
+	}
+	return windows, nil
+}
+
+// ListEnabled 获取已启用的维护窗口列表
+func (r *MaintenanceRepository) ListEnabled(ctx context.Context) ([]*model.MaintenanceWindow, error) {
+	var windows []*model.MaintenanceWindow
+	err := r.db.WithContext(ctx).Where("enabled = ?", true).Find(&windows).Error
+	if err != nil {
+		return nil, err
+	}
+	return windows, nil
+}
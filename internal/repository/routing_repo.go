@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/thingsboard-rxprobe/internal/model"
+	"gorm.io/gorm"
+)
+
+// RoutingRepository 告警路由规则仓库
+type RoutingRepository struct {
+	db *gorm.DB
+}
+
+// NewRoutingRepository 创建告警路由规则仓库
+func NewRoutingRepository(db *gorm.DB) *RoutingRepository {
+	return &RoutingRepository{db: db}
+}
+
+// Create 创建路由规则
+func (r *RoutingRepository) Create(ctx context.Context, rule *model.RoutingRule) error {
+	return r.db.WithContext(ctx).Create(rule).Error
+}
+
+// Update 更新路由规则
+func (r *RoutingRepository) Update(ctx context.Context, rule *model.RoutingRule) error {
+	return r.db.WithContext(ctx).Save(rule).Error
+}
+
+// Delete 删除路由规则
+func (r *RoutingRepository) Delete(ctx context.Context, id uint64) error {
+	return r.db.WithContext(ctx).Delete(&model.RoutingRule{}, id).Error
+}
+
+// GetByID 根据 ID 获取路由规则
+func (r *RoutingRepository) GetByID(ctx context.Context, id uint64) (*model.RoutingRule, error) {
+	var rule model.RoutingRule
+	err := r.db.WithContext(ctx).First(&rule, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
+
+// List 获取路由规则列表（按匹配顺序）
+func (r *RoutingRepository) List(ctx context.Context) ([]*model.RoutingRule, error) {
+	var rules []*model.RoutingRule
+	err := r.db.WithContext(ctx).Order("priority ASC, id ASC").Find(&rules).Error
+	if err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+// ListEnabled 获取已启用的路由规则列表（按匹配顺序）
+func (r *RoutingRepository) ListEnabled(ctx context.Context) ([]*model.RoutingRule, error) {
+	var rules []*model.RoutingRule
+	err := r.db.WithContext(ctx).Where("enabled = ?", true).Order("priority ASC, id ASC").Find(&rules).Error
+	if err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/thingsboard-rxprobe/internal/model"
+	"gorm.io/gorm"
+)
+
+// SilenceRepository 告警静默仓库
+type SilenceRepository struct {
+	db *gorm.DB
+}
+
+// NewSilenceRepository 创建告警静默仓库
+func NewSilenceRepository(db *gorm.DB) *SilenceRepository {
+	return &SilenceRepository{db: db}
+}
+
+// Create 创建静默规则
+func (r *SilenceRepository) Create(ctx context.Context, silence *model.Silence) error {
+	return r.db.WithContext(ctx).Create(silence).Error
+}
+
+// Update 更新静默规则
+func (r *SilenceRepository) Update(ctx context.Context, silence *model.Silence) error {
+	return r.db.WithContext(ctx).Save(silence).Error
+}
+
+// GetByID 根据 ID 获取静默规则
+func (r *SilenceRepository) GetByID(ctx context.Context, id uint64) (*model.Silence, error) {
+	var silence model.Silence
+	err := r.db.WithContext(ctx).First(&silence, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &silence, nil
+}
+
+// SilenceQuery 静默规则查询参数
+type SilenceQuery struct {
+	State    string // pending, active, expired；为空时不限
+	TargetID uint64
+	Page     int
+	Size     int
+}
+
+// List 获取静默规则列表
+func (r *SilenceRepository) List(ctx context.Context, query SilenceQuery) ([]*model.Silence, int64, error) {
+	var silences []*model.Silence
+	var total int64
+
+	now := time.Now()
+	db := r.db.WithContext(ctx).Model(&model.Silence{})
+	switch query.State {
+	case model.SilenceStatePending:
+		db = db.Where("starts_at > ?", now)
+	case model.SilenceStateActive:
+		db = db.Where("starts_at <= ? AND ends_at > ?", now, now)
+	case model.SilenceStateExpired:
+		db = db.Where("ends_at <= ?", now)
+	}
+	if query.TargetID > 0 {
+		db = db.Where("target_id = ?", query.TargetID)
+	}
+
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if query.Page > 0 && query.Size > 0 {
+		offset := (query.Page - 1) * query.Size
+		db = db.Offset(offset).Limit(query.Size)
+	}
+
+	if err := db.Order("ends_at DESC").Find(&silences).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return silences, total, nil
+}
+
+// ListUnexpired 获取指定时间尚未结束的静默规则（含未开始的）
+func (r *SilenceRepository) ListUnexpired(ctx context.Context, now time.Time) ([]*model.Silence, error) {
+	var silences []*model.Silence
+	err := r.db.WithContext(ctx).
+		Where("ends_at > ?", now).
+		Find(&silences).Error
+	if err != nil {
+		return nil, err
+	}
+	return silences, nil
+}
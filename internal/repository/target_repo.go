@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/thingsboard-rxprobe/internal/model"
+	"gorm.io/datatypes"
 	"gorm.io/gorm"
 )
 
@@ -43,6 +44,42 @@ func (r *TargetRepository) GetByID(ctx context.Context, id uint64) (*model.Probe
 	return &target, nil
 }
 
+// GetByIDs 根据 ID 列表获取探测目标
+func (r *TargetRepository) GetByIDs(ctx context.Context, ids []uint64) ([]*model.ProbeTarget, error) {
+	var targets []*model.ProbeTarget
+	if len(ids) == 0 {
+		return targets, nil
+	}
+	err := r.db.WithContext(ctx).Where("id IN ?", ids).Find(&targets).Error
+	return targets, err
+}
+
+// ListByParentID 获取依赖指定上游目标的探测目标
+// 依赖关系以 JSON 存储，为兼容不同数据库在内存中过滤
+func (r *TargetRepository) ListByParentID(ctx context.Context, parentID uint64) ([]*model.ProbeTarget, error) {
+	var targets []*model.ProbeTarget
+	if err := r.db.WithContext(ctx).Where("parent_ids IS NOT NULL").Find(&targets).Error; err != nil {
+		return nil, err
+	}
+	children := make([]*model.ProbeTarget, 0)
+	for _, target := range targets {
+		for _, id := range target.GetParentIDs() {
+			if id == parentID {
+				children = append(children, target)
+				break
+			}
+		}
+	}
+	return children, nil
+}
+
+// UpdateParentIDs 更新依赖的上游目标ID列表
+func (r *TargetRepository) UpdateParentIDs(ctx context.Context, id uint64, parentIDs datatypes.JSON) error {
+	return r.db.WithContext(ctx).Model(&model.ProbeTarget{}).
+		Where("id = ?", id).
+		Update("parent_ids", parentIDs).Error
+}
+
 // ListQuery 列表查询参数
 type ListQuery struct {
 	Keyword string
@@ -111,3 +148,12 @@ func (r *TargetRepository) UpdateStatus(ctx context.Context, id uint64, status s
 			"last_check_at":   time.Now(),
 		}).Error
 }
+
+// CountByEscalationPolicy 统计使用指定升级策略的目标数量
+func (r *TargetRepository) CountByEscalationPolicy(ctx context.Context, policyID uint64) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&model.ProbeTarget{}).
+		Where("escalation_policy_id = ?", policyID).
+		Count(&count).Error
+	return count, err
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
@@ -19,12 +21,27 @@ type AlertChecker interface {
 	HasUnresolvedAlert(ctx context.Context, targetID uint64) bool
 }
 
+// FlapConfig 抖动检测配置
+// 在最近 Window 次探测结果中统计状态变化率（变化次数 / (Window-1)），
+// 变化率达到 HighThreshold 时进入抖动状态，低于 LowThreshold 时恢复稳定
+type FlapConfig struct {
+	Window        int     // 统计窗口（最近探测次数），小于 2 表示关闭抖动检测
+	HighThreshold float64 // 进入抖动的变化率阈值（百分比）
+	LowThreshold  float64 // 退出抖动的变化率阈值（百分比）
+}
+
+// Enabled 是否启用抖动检测
+func (c FlapConfig) Enabled() bool {
+	return c.Window >= 2 && c.HighThreshold > 0
+}
+
 // Scheduler 调度器
 type Scheduler struct {
 	cron          *cron.Cron
 	tasks         sync.Map // map[uint64]*ProbeTask
 	proberFactory *prober.Factory
 	alertChecker  AlertChecker
+	flap          FlapConfig
 	resultChan    chan *ProbeResultEvent
 	alertChan     chan *AlertEvent
 	stopChan      chan struct{}
@@ -34,11 +51,24 @@ type Scheduler struct {
 
 // ProbeTask 探测任务
 type ProbeTask struct {
-	Target     *model.ProbeTarget
-	EntryID    cron.EntryID
-	FailCount  int
-	LastResult *prober.ProbeResult
-	mu         sync.Mutex
+	Target       *model.ProbeTarget
+	EntryID      cron.EntryID
+	FailCount    int  // 连续失败次数
+	SuccessCount int  // 连续成功次数
+	Alerting     bool // 是否处于告警中（已触发 firing，尚未恢复）
+	Flapping     bool // 是否处于抖动中（抖动期间暂停 firing/resolved 事件）
+	FlapRate     float64
+	LastResult   *prober.ProbeResult
+	history      []bool        // 最近的探测结果（是否计为成功），用于抖动检测
+	rules        []*metricRule // 指标阈值规则
+	mu           sync.Mutex
+}
+
+// metricRule 目标的指标阈值规则及其连续满足次数
+type metricRule struct {
+	cond     *model.MetricCondition
+	severity model.Severity
+	hits     int
 }
 
 // ProbeResultEvent 探测结果事件
@@ -46,6 +76,7 @@ type ProbeResultEvent struct {
 	TargetID  uint64
 	Target    *model.ProbeTarget
 	Result    *prober.ProbeResult
+	Flapping  bool // 目标是否处于抖动中
 	Timestamp time.Time
 }
 
@@ -55,14 +86,20 @@ type AlertEvent struct {
 	Result    *prober.ProbeResult
 	Status    model.AlertStatus
 	FailCount int
+	Flapping  bool    // 抖动开始事件：只发送一次抖动通知，不影响告警记录
+	FlapRate  float64 // 状态变化率（百分比）
 }
 
 // NewScheduler 创建调度器
-func NewScheduler(factory *prober.Factory, alertChecker AlertChecker) *Scheduler {
+func NewScheduler(factory *prober.Factory, alertChecker AlertChecker, flap FlapConfig) *Scheduler {
+	if flap.LowThreshold <= 0 || flap.LowThreshold > flap.HighThreshold {
+		flap.LowThreshold = flap.HighThreshold
+	}
 	return &Scheduler{
 		cron:          cron.New(cron.WithSeconds()),
 		proberFactory: factory,
 		alertChecker:  alertChecker,
+		flap:          flap,
 		resultChan:    make(chan *ProbeResultEvent, 1000),
 		alertChan:     make(chan *AlertEvent, 100),
 		stopChan:      make(chan struct{}),
@@ -134,18 +171,22 @@ func (s *Scheduler) AddTask(target *model.ProbeTarget) error {
 	}
 
 	task := &ProbeTask{Target: target}
-
-	// 检查是否需要初始化 FailCount（用于正确触发恢复通知）
-	// 条件：目标状态为 unhealthy，或者数据库中有未恢复的告警记录
-	shouldInitFailCount := target.Status == model.TargetStatusUnhealthy
-	if !shouldInitFailCount && s.alertChecker != nil {
-		shouldInitFailCount = s.alertChecker.HasUnresolvedAlert(context.Background(), target.ID)
+	for _, rule := range target.GetMetricRules() {
+		cond, err := rule.Parse()
+		if err != nil {
+			logger.Warn("忽略无效的指标阈值规则", zap.Uint64("target_id", target.ID), zap.Error(err))
+			continue
+		}
+		task.rules = append(task.rules, &metricRule{cond: cond, severity: rule.GetSeverity()})
 	}
 
-	if shouldInitFailCount {
-		// 固定策略：只要存在“未恢复告警/异常状态”，FailCount>0 便能在下一次成功时触发恢复逻辑
-		task.FailCount = 1
-		logger.Info("初始化失败计数（存在未恢复告警或状态异常）",
+	// 检查是否需要恢复告警状态（用于正确触发恢复通知）
+	// 条件：数据库中有未恢复的告警记录
+	// 注意：unhealthy 状态可能只是尚未达到失败阈值，不能据此认为已经告警
+	if s.alertChecker != nil && s.alertChecker.HasUnresolvedAlert(context.Background(), target.ID) {
+		task.Alerting = true
+		task.FailCount = failureThreshold(target)
+		logger.Info("恢复告警状态（存在未恢复告警）",
 			zap.Uint64("target_id", target.ID),
 			zap.String("status", target.Status),
 			zap.Int("fail_count", task.FailCount),
@@ -242,7 +283,15 @@ func (s *Scheduler) executeProbe(task *ProbeTask, target prober.Target) {
 	}
 }
 
-// handleResult 处理单个结果（更新失败计数、触发告警）
+// handleResult 处理单个结果（更新连续失败/成功计数、触发告警）
+//
+// 告警策略由目标配置决定：
+//   - 连续失败次数达到 FailureThreshold 后触发 firing，此后每次失败都会继续产生 firing 事件，
+//     是否重复发送通知由 AlertService 按 RepeatIntervalSeconds 控制
+//   - 告警中连续成功次数达到 RecoveryThreshold 后触发 resolved
+//   - 探针报告的降级默认计为成功，目标开启 AlertOnDegraded 时计为失败（按警告级别告警）
+//   - 严重级别的指标规则触发时结果判定为故障，其余级别的规则触发时结果判定为降级；
+//     规则触发始终计为失败并按规则的级别告警，直接达到 FailureThreshold，连续次数由规则的 “for N checks” 决定
 func (s *Scheduler) handleResult(event *ProbeResultEvent) {
 	v, ok := s.tasks.Load(event.TargetID)
 	if !ok {
@@ -254,37 +303,253 @@ func (s *Scheduler) handleResult(event *ProbeResultEvent) {
 	defer task.mu.Unlock()
 
 	task.LastResult = event.Result
+	ruleTriggered := applyMetricRules(task, event.Result)
+	failed := isFailure(task.Target, event.Result)
+	s.detectFlapping(task, event, failed)
+	event.Flapping = task.Flapping
 
-	if !event.Result.Success {
+	if failed {
 		task.FailCount++
-		// 固定策略：每次失败都触发 firing
+		task.SuccessCount = 0
+
+		threshold := failureThreshold(task.Target)
+		if ruleTriggered && task.FailCount < threshold {
+			// 指标规则已按自身的连续次数确认，不再叠加连续失败阈值
+			task.FailCount = threshold
+		}
+		if task.FailCount < threshold {
+			logger.Debug("连续失败次数未达到告警阈值",
+				zap.Uint64("target_id", event.TargetID),
+				zap.Int("fail_count", task.FailCount),
+				zap.Int("threshold", threshold),
+			)
+			return
+		}
+		if task.Flapping {
+			logger.Debug("目标处于抖动中，暂停告警事件", zap.Uint64("target_id", event.TargetID))
+			return
+		}
+
+		task.Alerting = true
+		select {
+		case s.alertChan <- &AlertEvent{
+			Target:    task.Target,
+			Result:    event.Result,
+			Status:    model.AlertStatusFiring,
+			FailCount: task.FailCount,
+		}:
+		default:
+			logger.Warn("告警通道已满", zap.Uint64("target_id", event.TargetID))
+		}
+		return
+	}
+
+	task.SuccessCount++
+	if task.Alerting {
+		threshold := recoveryThreshold(task.Target)
+		if task.SuccessCount < threshold {
+			logger.Debug("连续成功次数未达到恢复阈值",
+				zap.Uint64("target_id", event.TargetID),
+				zap.Int("success_count", task.SuccessCount),
+				zap.Int("threshold", threshold),
+			)
+			return
+		}
+		if task.Flapping {
+			logger.Debug("目标处于抖动中，暂停恢复事件", zap.Uint64("target_id", event.TargetID))
+			return
+		}
+
+		// 恢复通知
+		select {
+		case s.alertChan <- &AlertEvent{
+			Target:    task.Target,
+			Result:    event.Result,
+			Status:    model.AlertStatusResolved,
+			FailCount: task.FailCount,
+		}:
+		default:
+			logger.Warn("告警通道已满", zap.Uint64("target_id", event.TargetID))
+		}
+		task.Alerting = false
+	}
+	task.FailCount = 0
+}
+
+// detectFlapping 记录探测结果并更新目标的抖动状态，进入抖动时发送一次抖动事件
+// 调用方需持有 task.mu
+func (s *Scheduler) detectFlapping(task *ProbeTask, event *ProbeResultEvent, failed bool) {
+	if !s.flap.Enabled() {
+		return
+	}
+
+	task.history = append(task.history, !failed)
+	if len(task.history) > s.flap.Window {
+		task.history = task.history[len(task.history)-s.flap.Window:]
+	}
+
+	changes := 0
+	for i := 1; i < len(task.history); i++ {
+		if task.history[i] != task.history[i-1] {
+			changes++
+		}
+	}
+	task.FlapRate = float64(changes) * 100 / float64(s.flap.Window-1)
+
+	switch {
+	case !task.Flapping && task.FlapRate >= s.flap.HighThreshold:
+		task.Flapping = true
+		logger.Warn("目标进入抖动状态",
+			zap.Uint64("target_id", event.TargetID),
+			zap.Float64("flap_rate", task.FlapRate),
+		)
 		select {
 		case s.alertChan <- &AlertEvent{
 			Target:    task.Target,
 			Result:    event.Result,
 			Status:    model.AlertStatusFiring,
 			FailCount: task.FailCount,
+			Flapping:  true,
+			FlapRate:  task.FlapRate,
 		}:
 		default:
 			logger.Warn("告警通道已满", zap.Uint64("target_id", event.TargetID))
 		}
-	} else {
-		// 固定策略：只要出现过失败（FailCount>0），成功就触发一次恢复，关闭未恢复告警记录
-		if task.FailCount > 0 {
-			// 恢复通知
-			select {
-			case s.alertChan <- &AlertEvent{
-				Target:    task.Target,
-				Result:    event.Result,
-				Status:    model.AlertStatusResolved,
-				FailCount: task.FailCount,
-			}:
-			default:
-				logger.Warn("告警通道已满", zap.Uint64("target_id", event.TargetID))
-			}
+	case task.Flapping && task.FlapRate < s.flap.LowThreshold:
+		// 恢复稳定后按正常的连续失败/成功计数继续产生告警或恢复事件
+		task.Flapping = false
+		logger.Info("目标恢复稳定",
+			zap.Uint64("target_id", event.TargetID),
+			zap.Float64("flap_rate", task.FlapRate),
+		)
+	}
+}
+
+// applyMetricRules 评估目标的指标阈值规则，规则连续满足达到指定次数后：
+//   - 严重级别的规则将本次结果判定为失败，并在结果消息中附加触发规则的指标当前值
+//   - 其余级别的规则作为警告附加到结果中，结果判定为降级（服务仍视为可用，但不受 AlertOnDegraded 影响按规则的级别告警）
+//
+// 返回值表示本次结果是否因规则由正常变为失败或降级。规则自身的 “for N checks” 已经表达了持续时间，
+// 调用方据此跳过 FailureThreshold 的连续失败计数，避免重复计数。调用方需持有 task.mu
+func applyMetricRules(task *ProbeTask, result *prober.ProbeResult) bool {
+	var critical, warnings []string
+	var severity model.Severity
+	for _, rule := range task.rules {
+		value, ok := metricValue(result, rule.cond.Metric)
+		if !ok || !rule.cond.Eval(value) {
+			rule.hits = 0
+			continue
+		}
+		rule.hits++
+		if rule.hits < rule.cond.For {
+			continue
+		}
+		if rule.severity == model.SeverityCritical {
+			critical = append(critical, rule.cond.Describe(value))
+		} else {
+			warnings = append(warnings, rule.cond.Describe(value))
+		}
+		if severity == "" || rule.severity.Rank() > severity.Rank() {
+			severity = rule.severity
 		}
-		task.FailCount = 0
 	}
+	if severity == "" {
+		return false
+	}
+
+	result.RuleSeverity = string(severity)
+	message := "指标超过阈值：" + strings.Join(append(critical, warnings...), "；")
+	if !result.Success {
+		// 探测本身已失败时保留原有失败原因与失败计数，告警级别由 RuleSeverity 提升
+		result.Message = result.Message + "；" + message
+		return false
+	}
+	if len(critical) == 0 {
+		result.Warnings = append(result.Warnings, message)
+		return true
+	}
+	result.Success = false
+	result.Message = message + "；" + result.Message
+	return true
+}
+
+// metricValue 获取探测结果中的指标数值，latency_ms 为探测耗时，其余指标从 Metrics 中读取（支持以 . 访问嵌套字段）
+func metricValue(result *prober.ProbeResult, name string) (float64, bool) {
+	if name == "latency_ms" {
+		if v, ok := result.Metrics[name]; ok {
+			return toFloat(v)
+		}
+		return float64(result.Latency.Milliseconds()), true
+	}
+
+	var current any = result.Metrics
+	for _, key := range strings.Split(name, ".") {
+		m, ok := current.(map[string]any)
+		if !ok {
+			return 0, false
+		}
+		if current, ok = m[key]; !ok {
+			return 0, false
+		}
+	}
+	return toFloat(current)
+}
+
+// toFloat 将指标值转换为数值，支持数字类型与数字字符串
+func toFloat(v any) (float64, bool) {
+	switch val := v.(type) {
+	case float64:
+		return val, true
+	case float32:
+		return float64(val), true
+	case int:
+		return float64(val), true
+	case int32:
+		return float64(val), true
+	case int64:
+		return float64(val), true
+	case uint64:
+		return float64(val), true
+	case bool:
+		if val {
+			return 1, true
+		}
+		return 0, true
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(strings.TrimSuffix(val, "%")), 64)
+		return f, err == nil
+	default:
+		return 0, false
+	}
+}
+
+// isFailure 探测结果是否计为失败：故障与指标规则触发的降级始终计为失败，
+// 探针报告的降级仅在目标开启降级告警时计为失败
+func isFailure(target *model.ProbeTarget, result *prober.ProbeResult) bool {
+	switch result.Outcome() {
+	case prober.OutcomeDown:
+		return true
+	case prober.OutcomeDegraded:
+		return result.RuleSeverity != "" || target.AlertOnDegraded
+	default:
+		return false
+	}
+}
+
+// failureThreshold 获取目标的连续失败告警阈值（最小为 1）
+func failureThreshold(target *model.ProbeTarget) int {
+	if target.FailureThreshold < 1 {
+		return 1
+	}
+	return target.FailureThreshold
+}
+
+// recoveryThreshold 获取目标的连续成功恢复阈值（最小为 1）
+func recoveryThreshold(target *model.ProbeTarget) int {
+	if target.RecoveryThreshold < 1 {
+		return 1
+	}
+	return target.RecoveryThreshold
 }
 
 // GetTaskStatus 获取任务状态
@@ -0,0 +1,393 @@
+package scheduler
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/thingsboard-rxprobe/internal/model"
+	"github.com/thingsboard-rxprobe/internal/prober"
+)
+
+// newRuleTask 创建带指标阈值规则的探测任务
+func newRuleTask(t *testing.T, target *model.ProbeTarget, rules ...model.MetricRule) *ProbeTask {
+	t.Helper()
+	task := &ProbeTask{Target: target}
+	for _, rule := range rules {
+		cond, err := rule.Parse()
+		if err != nil {
+			t.Fatal(err)
+		}
+		task.rules = append(task.rules, &metricRule{cond: cond, severity: rule.GetSeverity()})
+	}
+	return task
+}
+
+func okResult(metrics map[string]any) *prober.ProbeResult {
+	return &prober.ProbeResult{Success: true, Message: "ok", Latency: 50 * time.Millisecond, Metrics: metrics}
+}
+
+func TestApplyMetricRules(t *testing.T) {
+	tests := []struct {
+		name        string
+		rules       []model.MetricRule
+		results     []*prober.ProbeResult
+		wantOutcome []prober.Outcome // 每次评估后的探测结论
+		wantFlipped []bool           // 每次评估的返回值
+		wantRule    string           // 最后一次结果命中规则的级别
+	}{
+		{
+			name:        "警告规则判定为降级",
+			rules:       []model.MetricRule{{Expr: "avg_rtt_ms > 200"}},
+			results:     []*prober.ProbeResult{okResult(map[string]any{"avg_rtt_ms": 250.0})},
+			wantOutcome: []prober.Outcome{prober.OutcomeDegraded},
+			wantFlipped: []bool{true},
+			wantRule:    string(model.SeverityWarning),
+		},
+		{
+			name:  "多条规则命中时取最高级别",
+			rules: []model.MetricRule{{Expr: "packet_loss >= 25"}, {Expr: "avg_rtt_ms > 200", Severity: model.SeverityCritical}},
+			results: []*prober.ProbeResult{
+				okResult(map[string]any{"packet_loss": 25, "avg_rtt_ms": "300"}),
+			},
+			wantOutcome: []prober.Outcome{prober.OutcomeDown},
+			wantFlipped: []bool{true},
+			wantRule:    string(model.SeverityCritical),
+		},
+		{
+			name:  "for N checks 连续满足后才触发，中断后重新计数",
+			rules: []model.MetricRule{{Expr: "latency_ms > 100 for 3 checks", Severity: model.SeverityCritical}},
+			results: []*prober.ProbeResult{
+				okResult(map[string]any{"latency_ms": 150}),
+				okResult(map[string]any{"latency_ms": 150}),
+				okResult(map[string]any{"latency_ms": 50}),
+				okResult(map[string]any{"latency_ms": 150}),
+				okResult(map[string]any{"latency_ms": 150}),
+				okResult(map[string]any{"latency_ms": 150}),
+			},
+			wantOutcome: []prober.Outcome{prober.OutcomeOK, prober.OutcomeOK, prober.OutcomeOK, prober.OutcomeOK, prober.OutcomeOK, prober.OutcomeDown},
+			wantFlipped: []bool{false, false, false, false, false, true},
+			wantRule:    string(model.SeverityCritical),
+		},
+		{
+			name:  "指标缺失时重新计数",
+			rules: []model.MetricRule{{Expr: "queue.depth > 10 for 2 checks"}},
+			results: []*prober.ProbeResult{
+				okResult(map[string]any{"queue": map[string]any{"depth": 20}}),
+				okResult(map[string]any{}),
+				okResult(map[string]any{"queue": map[string]any{"depth": 20}}),
+				okResult(map[string]any{"queue": map[string]any{"depth": 20}}),
+			},
+			wantOutcome: []prober.Outcome{prober.OutcomeOK, prober.OutcomeOK, prober.OutcomeOK, prober.OutcomeDegraded},
+			wantFlipped: []bool{false, false, false, true},
+			wantRule:    string(model.SeverityWarning),
+		},
+		{
+			name:        "未配置的 latency_ms 取探测耗时",
+			rules:       []model.MetricRule{{Expr: "latency_ms >= 50"}},
+			results:     []*prober.ProbeResult{okResult(nil)},
+			wantOutcome: []prober.Outcome{prober.OutcomeDegraded},
+			wantFlipped: []bool{true},
+			wantRule:    string(model.SeverityWarning),
+		},
+		{
+			name:  "探测本身失败时严重规则提升级别但不计为规则触发",
+			rules: []model.MetricRule{{Expr: "latency_ms > 10", Severity: model.SeverityCritical}},
+			results: []*prober.ProbeResult{
+				{Success: false, Message: "connection refused", Latency: 20 * time.Millisecond},
+			},
+			wantOutcome: []prober.Outcome{prober.OutcomeDown},
+			wantFlipped: []bool{false},
+			wantRule:    string(model.SeverityCritical),
+		},
+		{
+			name:  "探测本身失败时警告规则保留原有级别",
+			rules: []model.MetricRule{{Expr: "latency_ms > 10"}},
+			results: []*prober.ProbeResult{
+				{Success: false, Message: "connection refused", Latency: 20 * time.Millisecond},
+			},
+			wantOutcome: []prober.Outcome{prober.OutcomeDown},
+			wantFlipped: []bool{false},
+			wantRule:    string(model.SeverityWarning),
+		},
+		{
+			name:        "未命中规则时结果不变",
+			rules:       []model.MetricRule{{Expr: "avg_rtt_ms > 200"}},
+			results:     []*prober.ProbeResult{okResult(map[string]any{"avg_rtt_ms": 100})},
+			wantOutcome: []prober.Outcome{prober.OutcomeOK},
+			wantFlipped: []bool{false},
+			wantRule:    "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			task := newRuleTask(t, &model.ProbeTarget{}, tt.rules...)
+			var last *prober.ProbeResult
+			for i, result := range tt.results {
+				flipped := applyMetricRules(task, result)
+				if flipped != tt.wantFlipped[i] {
+					t.Fatalf("第 %d 次评估返回 %v, want %v", i+1, flipped, tt.wantFlipped[i])
+				}
+				if outcome := result.Outcome(); outcome != tt.wantOutcome[i] {
+					t.Fatalf("第 %d 次评估后结论 = %v, want %v（%s）", i+1, outcome, tt.wantOutcome[i], result.Summary())
+				}
+				last = result
+			}
+			if last.RuleSeverity != tt.wantRule {
+				t.Fatalf("RuleSeverity = %q, want %q", last.RuleSeverity, tt.wantRule)
+			}
+			if tt.wantRule != "" && !strings.Contains(last.Summary(), "指标超过阈值") {
+				t.Fatalf("消息中缺少触发的规则: %q", last.Summary())
+			}
+		})
+	}
+}
+
+func TestHandleResultMetricRuleBypassesThreshold(t *testing.T) {
+	s := NewScheduler(nil, nil, FlapConfig{})
+	target := &model.ProbeTarget{ID: 1, FailureThreshold: 5, RecoveryThreshold: 1}
+	task := newRuleTask(t, target, model.MetricRule{Expr: "avg_rtt_ms > 200 for 2 checks", Severity: model.SeverityCritical})
+	s.tasks.Store(target.ID, task)
+
+	handle := func(result *prober.ProbeResult) *AlertEvent {
+		s.handleResult(&ProbeResultEvent{TargetID: target.ID, Target: target, Result: result})
+		select {
+		case event := <-s.alertChan:
+			return event
+		default:
+			return nil
+		}
+	}
+
+	if event := handle(okResult(map[string]any{"avg_rtt_ms": 300})); event != nil {
+		t.Fatalf("规则首次满足不应告警: %+v", event)
+	}
+	event := handle(okResult(map[string]any{"avg_rtt_ms": 300}))
+	if event == nil || event.Status != model.AlertStatusFiring {
+		t.Fatalf("规则连续满足 2 次应立即告警，不等待 FailureThreshold: %+v", event)
+	}
+	if event.Result.RuleSeverity != string(model.SeverityCritical) {
+		t.Fatalf("严重规则的告警级别 = %q", event.Result.RuleSeverity)
+	}
+
+	// 探测本身失败不受规则影响，仍按 FailureThreshold 计数
+	task.Alerting = false
+	task.FailCount = 0
+	for i := 0; i < 4; i++ {
+		if event := handle(&prober.ProbeResult{Success: false, Message: "timeout"}); event != nil {
+			t.Fatalf("第 %d 次探测失败不应告警", i+1)
+		}
+	}
+	if event := handle(&prober.ProbeResult{Success: false, Message: "timeout"}); event == nil {
+		t.Fatal("连续失败达到阈值应告警")
+	}
+
+	if event := handle(okResult(map[string]any{"avg_rtt_ms": 100})); event == nil || event.Status != model.AlertStatusResolved {
+		t.Fatalf("规则不再满足时应恢复: %+v", event)
+	}
+}
+
+func TestHandleResultWarningRuleDegrades(t *testing.T) {
+	s := NewScheduler(nil, nil, FlapConfig{})
+	target := &model.ProbeTarget{ID: 1, FailureThreshold: 3, RecoveryThreshold: 1}
+	task := newRuleTask(t, target, model.MetricRule{Expr: "replication_lag_seconds > 30"})
+	s.tasks.Store(target.ID, task)
+
+	handle := func(result *prober.ProbeResult) *AlertEvent {
+		s.handleResult(&ProbeResultEvent{TargetID: target.ID, Target: target, Result: result})
+		select {
+		case event := <-s.alertChan:
+			return event
+		default:
+			return nil
+		}
+	}
+
+	// 探针报告的降级在未开启降级告警时不计为失败
+	if event := handle(&prober.ProbeResult{Success: true, Warnings: []string{"slow"}}); event != nil {
+		t.Fatalf("未开启降级告警时探针报告的降级不应告警: %+v", event)
+	}
+	if task.FailCount != 0 || task.Alerting {
+		t.Fatalf("探针报告的降级不应计为失败: fail_count=%d alerting=%v", task.FailCount, task.Alerting)
+	}
+
+	// 警告规则不受 AlertOnDegraded 影响，按规则的连续次数立即告警，不叠加 FailureThreshold
+	result := okResult(map[string]any{"replication_lag_seconds": 60})
+	event := handle(result)
+	if event == nil || event.Status != model.AlertStatusFiring {
+		t.Fatalf("未开启降级告警时警告规则也应告警: %+v", event)
+	}
+	if !result.Success || result.Outcome() != prober.OutcomeDegraded {
+		t.Fatalf("警告规则应判定为降级而不是故障: success=%v outcome=%v", result.Success, result.Outcome())
+	}
+	if event.Result.RuleSeverity != string(model.SeverityWarning) {
+		t.Fatalf("告警结果应为警告级别: rule=%q", event.Result.RuleSeverity)
+	}
+
+	// 规则不再满足后恢复
+	event = handle(okResult(map[string]any{"replication_lag_seconds": 1}))
+	if event == nil || event.Status != model.AlertStatusResolved {
+		t.Fatalf("规则不再满足后应恢复: %+v", event)
+	}
+}
+
+func TestDetectFlappingHysteresis(t *testing.T) {
+	// 窗口 5 次，变化率 = 变化次数 / 4
+	s := NewScheduler(nil, nil, FlapConfig{Window: 5, HighThreshold: 50, LowThreshold: 25})
+	target := &model.ProbeTarget{ID: 1}
+	task := &ProbeTask{Target: target}
+
+	steps := []struct {
+		failed       bool
+		wantRate     float64
+		wantFlapping bool
+		wantEvent    bool
+	}{
+		{false, 0, false, false},
+		{true, 25, false, false}, // 低于进入阈值
+		{false, 50, true, true},  // 达到进入阈值，发送一次抖动事件
+		{false, 50, true, false}, // 抖动期间不重复发送
+		{false, 50, true, false},
+		{false, 25, true, false}, // 介于两个阈值之间时保持抖动
+		{false, 0, false, false}, // 低于退出阈值时恢复稳定
+		{true, 25, false, false}, // 介于两个阈值之间时保持稳定
+	}
+	for i, step := range steps {
+		event := &ProbeResultEvent{TargetID: target.ID, Target: target, Result: &prober.ProbeResult{Success: !step.failed}}
+		s.detectFlapping(task, event, step.failed)
+		if task.FlapRate != step.wantRate || task.Flapping != step.wantFlapping {
+			t.Fatalf("第 %d 次: rate=%v flapping=%v, want rate=%v flapping=%v",
+				i+1, task.FlapRate, task.Flapping, step.wantRate, step.wantFlapping)
+		}
+		select {
+		case alert := <-s.alertChan:
+			if !step.wantEvent || !alert.Flapping || alert.FlapRate != step.wantRate {
+				t.Fatalf("第 %d 次不应发送抖动事件: %+v", i+1, alert)
+			}
+		default:
+			if step.wantEvent {
+				t.Fatalf("第 %d 次应发送抖动事件", i+1)
+			}
+		}
+	}
+}
+
+func TestHandleResultHoldsAlertsWhileFlapping(t *testing.T) {
+	s := NewScheduler(nil, nil, FlapConfig{Window: 3, HighThreshold: 100, LowThreshold: 50})
+	target := &model.ProbeTarget{ID: 1, FailureThreshold: 1, RecoveryThreshold: 1}
+	s.tasks.Store(target.ID, &ProbeTask{Target: target})
+
+	var events []*AlertEvent
+	handle := func(success bool) {
+		s.handleResult(&ProbeResultEvent{TargetID: target.ID, Target: target, Result: &prober.ProbeResult{Success: success}})
+		for {
+			select {
+			case event := <-s.alertChan:
+				events = append(events, event)
+			default:
+				return
+			}
+		}
+	}
+
+	handle(false) // 达到失败阈值，触发告警
+	handle(true)  // 恢复
+	handle(false) // 变化率 100%，进入抖动，只发送抖动事件
+	handle(true)
+	if len(events) != 3 || events[0].Status != model.AlertStatusFiring || events[1].Status != model.AlertStatusResolved || !events[2].Flapping {
+		t.Fatalf("抖动期间应暂停告警与恢复事件: %+v", events)
+	}
+
+	handle(true) // 变化率 50%，仍处于抖动
+	handle(true) // 变化率 0%，恢复稳定；抖动期间的失败未发送告警，不需要恢复
+	if len(events) != 3 {
+		t.Fatalf("恢复稳定后不应补发事件: %+v", events[3:])
+	}
+	handle(false)
+	if len(events) != 4 || events[3].Status != model.AlertStatusFiring || events[3].Flapping {
+		t.Fatalf("恢复稳定后按正常计数告警: %+v", events)
+	}
+}
+
+func TestHandleResultThresholds(t *testing.T) {
+	tests := []struct {
+		name              string
+		failureThreshold  int
+		recoveryThreshold int
+		wantFailures      int // 触发告警需要的连续失败次数
+		wantSuccesses     int // 触发恢复需要的连续成功次数
+	}{
+		{"未设置阈值时按 1 处理", 0, 0, 1, 1},
+		{"负数阈值按 1 处理", -3, -1, 1, 1},
+		{"连续失败 3 次、连续成功 2 次", 3, 2, 3, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewScheduler(nil, nil, FlapConfig{})
+			target := &model.ProbeTarget{ID: 1, FailureThreshold: tt.failureThreshold, RecoveryThreshold: tt.recoveryThreshold}
+			task := &ProbeTask{Target: target}
+			s.tasks.Store(target.ID, task)
+
+			handle := func(success bool) *AlertEvent {
+				s.handleResult(&ProbeResultEvent{TargetID: target.ID, Target: target, Result: &prober.ProbeResult{Success: success}})
+				select {
+				case event := <-s.alertChan:
+					return event
+				default:
+					return nil
+				}
+			}
+
+			// 连续失败中间出现一次成功时重新计数
+			for i := 0; i < tt.wantFailures-1; i++ {
+				if event := handle(false); event != nil {
+					t.Fatalf("第 %d 次失败不应告警", i+1)
+				}
+			}
+			if tt.wantFailures > 1 {
+				handle(true)
+				if task.FailCount != 0 {
+					t.Fatalf("成功后连续失败次数应清零: %d", task.FailCount)
+				}
+				for i := 0; i < tt.wantFailures-1; i++ {
+					if event := handle(false); event != nil {
+						t.Fatalf("清零后第 %d 次失败不应告警", i+1)
+					}
+				}
+			}
+			if event := handle(false); event == nil || event.Status != model.AlertStatusFiring {
+				t.Fatalf("连续失败 %d 次应告警: %+v", tt.wantFailures, event)
+			}
+
+			// 连续成功中间出现一次失败时重新计数
+			for i := 0; i < tt.wantSuccesses-1; i++ {
+				if event := handle(true); event != nil {
+					t.Fatalf("第 %d 次成功不应恢复", i+1)
+				}
+			}
+			if tt.wantSuccesses > 1 {
+				if event := handle(false); event == nil || event.Status != model.AlertStatusFiring {
+					t.Fatal("告警中再次失败应继续产生告警事件")
+				}
+				if task.SuccessCount != 0 {
+					t.Fatalf("失败后连续成功次数应清零: %d", task.SuccessCount)
+				}
+				for i := 0; i < tt.wantSuccesses-1; i++ {
+					if event := handle(true); event != nil {
+						t.Fatalf("清零后第 %d 次成功不应恢复", i+1)
+					}
+				}
+			}
+			if event := handle(true); event == nil || event.Status != model.AlertStatusResolved {
+				t.Fatalf("连续成功 %d 次应恢复: %+v", tt.wantSuccesses, event)
+			}
+			if task.Alerting || task.FailCount != 0 {
+				t.Fatalf("恢复后应清除告警状态: alerting=%v fail_count=%d", task.Alerting, task.FailCount)
+			}
+			if event := handle(true); event != nil {
+				t.Fatalf("恢复后继续成功不应再产生事件: %+v", event)
+			}
+		})
+	}
+}
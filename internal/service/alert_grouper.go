@@ -0,0 +1,233 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/thingsboard-rxprobe/internal/model"
+)
+
+// groupCheckInterval 告警分组发送检查间隔
+const groupCheckInterval = time.Second
+
+// GroupingConfig 告警分组配置（类似 Alertmanager 的 group_wait/group_interval）
+type GroupingConfig struct {
+	Wait     time.Duration // 新分组首次发送前的等待时间，用于收集同一时间段内的告警，0 表示不分组
+	Interval time.Duration // 同一分组两次发送的最小间隔
+}
+
+// Enabled 是否启用告警分组
+func (c GroupingConfig) Enabled() bool {
+	return c.Wait > 0
+}
+
+// alertGroupKey 告警分组键：同一渠道上分组、探针类型均相同的告警合并为一条通知
+// 告警状态不参与分组，同一告警在发送前恢复时告警通知与恢复通知均不发送
+type alertGroupKey struct {
+	ChannelID  uint64
+	Group      string
+	TargetType string
+}
+
+// alertGroup 告警分组
+type alertGroup struct {
+	channel  *model.NotifyChannel
+	alerts   []*model.Alert // 等待发送的告警
+	flushAt  time.Time      // 计划发送时间，无待发送告警时为零值
+	lastSent time.Time      // 最近一次发送时间
+}
+
+// groupFlush 到期待发送的分组
+type groupFlush struct {
+	channel *model.NotifyChannel
+	alerts  []*model.Alert
+}
+
+// alertGrouper 告警分组缓冲
+type alertGrouper struct {
+	config GroupingConfig
+	mu     sync.Mutex
+	groups map[alertGroupKey]*alertGroup
+}
+
+// newAlertGrouper 创建告警分组缓冲
+func newAlertGrouper(config GroupingConfig) *alertGrouper {
+	if config.Interval < config.Wait {
+		config.Interval = config.Wait
+	}
+	return &alertGrouper{
+		config: config,
+		groups: make(map[alertGroupKey]*alertGroup),
+	}
+}
+
+// add 将告警加入渠道对应的分组，同一目标在分组中只保留最新的告警
+// 恢复时同一告警的告警通知仍在等待发送，说明渠道从未收到过该告警，两者一并丢弃，不发送恢复通知；
+// 已通知过的告警由调用方在恢复前撤回等待发送的重复通知（见 discard）
+func (g *alertGrouper) add(channel *model.NotifyChannel, alert *model.Alert, now time.Time) {
+	key := alertGroupKey{
+		ChannelID:  channel.ID,
+		Group:      alert.Group,
+		TargetType: alert.TargetType,
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	group, ok := g.groups[key]
+	if !ok {
+		group = &alertGroup{}
+		g.groups[key] = group
+	}
+	group.channel = channel
+
+	replaced := false
+	for i, pending := range group.alerts {
+		if pending.TargetID != alert.TargetID {
+			continue
+		}
+		if alert.Status == model.AlertStatusResolved && pending.Status != model.AlertStatusResolved && pending.ID == alert.ID {
+			group.remove(i)
+			return
+		}
+		group.alerts[i] = alert
+		replaced = true
+		break
+	}
+	if !replaced {
+		group.alerts = append(group.alerts, alert)
+	}
+
+	// 新的一批告警：首次等待 Wait，距上次发送不足 Interval 时推迟到满足间隔
+	if group.flushAt.IsZero() {
+		group.flushAt = now.Add(g.config.Wait)
+		if next := group.lastSent.Add(g.config.Interval); next.After(group.flushAt) {
+			group.flushAt = next
+		}
+	}
+}
+
+// discard 撤回告警记录所有等待分组发送的告警
+func (g *alertGrouper) discard(alertRecordID uint64) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	for _, group := range g.groups {
+		for i := len(group.alerts) - 1; i >= 0; i-- {
+			if group.alerts[i].ID == alertRecordID {
+				group.remove(i)
+			}
+		}
+	}
+}
+
+// remove 移除第 i 条等待发送的告警，分组不再有待发送告警时取消计划发送
+func (group *alertGroup) remove(i int) {
+	group.alerts = append(group.alerts[:i:i], group.alerts[i+1:]...)
+	if len(group.alerts) == 0 {
+		group.alerts = nil
+		group.flushAt = time.Time{}
+	}
+}
+
+// pending 告警记录是否有等待分组发送的告警
+func (g *alertGrouper) pending(alertRecordID uint64) bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	for _, group := range g.groups {
+		for _, alert := range group.alerts {
+			if alert.ID == alertRecordID {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// due 取出到期的分组；all 为 true 时取出所有待发送的分组（用于停止服务前发送）
+func (g *alertGrouper) due(now time.Time, all bool) []groupFlush {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	var flushes []groupFlush
+	for key, group := range g.groups {
+		if len(group.alerts) == 0 {
+			// 超过发送间隔且没有新告警的分组不再需要保留
+			if now.Sub(group.lastSent) >= g.config.Interval {
+				delete(g.groups, key)
+			}
+			continue
+		}
+		if !all && now.Before(group.flushAt) {
+			continue
+		}
+		flushes = append(flushes, groupFlush{channel: group.channel, alerts: group.alerts})
+		group.alerts = nil
+		group.flushAt = time.Time{}
+		group.lastSent = now
+	}
+	return flushes
+}
+
+// mergeAlerts 将同一分组的告警合并为一条通知，只有一条告警时原样返回
+// 合并后的通知保留各条告警（含告警记录 ID），投递时为每条告警记录写入投递；
+// 存在未恢复的告警时合并通知为告警状态，全部恢复时为恢复状态
+func mergeAlerts(alerts []*model.Alert) *model.Alert {
+	if len(alerts) == 1 {
+		return alerts[0]
+	}
+
+	first := alerts[0]
+	merged := &model.Alert{
+		TargetName: fmt.Sprintf("%s 等 %d 个目标", first.TargetName, len(alerts)),
+		TargetType: first.TargetType,
+		Group:      first.Group,
+		Status:     model.AlertStatusResolved,
+		Severity:   first.Severity,
+		FiredAt:    first.FiredAt,
+		Alerts:     alerts,
+	}
+
+	var firing, resolved int
+	for _, alert := range alerts {
+		if alert.Status == model.AlertStatusResolved {
+			resolved++
+		} else {
+			firing++
+		}
+	}
+	mixed := firing > 0 && resolved > 0
+	if firing > 0 {
+		merged.Status = model.AlertStatusFiring
+	}
+
+	lines := make([]string, 0, len(alerts)+1)
+	if mixed {
+		lines = append(lines, fmt.Sprintf("共 %d 个目标（%d 个告警、%d 个恢复）：", len(alerts), firing, resolved))
+	} else {
+		lines = append(lines, fmt.Sprintf("共 %d 个目标：", len(alerts)))
+	}
+	for _, alert := range alerts {
+		prefix := "- "
+		if mixed && alert.Status == model.AlertStatusResolved {
+			prefix = "- [恢复] "
+		} else if mixed {
+			prefix = "- [告警] "
+		}
+		lines = append(lines, fmt.Sprintf("%s%s：%s", prefix, alert.TargetName, alert.Message))
+		if alert.Severity.Rank() > merged.Severity.Rank() {
+			merged.Severity = alert.Severity
+		}
+		if alert.FiredAt.Before(merged.FiredAt) {
+			merged.FiredAt = alert.FiredAt
+		}
+		if firing == 0 && alert.ResolvedAt != nil && (merged.ResolvedAt == nil || alert.ResolvedAt.After(*merged.ResolvedAt)) {
+			merged.ResolvedAt = alert.ResolvedAt
+		}
+	}
+	merged.Message = strings.Join(lines, "\n")
+	return merged
+}
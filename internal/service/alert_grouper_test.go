@@ -0,0 +1,161 @@
+package service
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/thingsboard-rxprobe/internal/model"
+)
+
+func TestAlertGrouperKey(t *testing.T) {
+	g := newAlertGrouper(GroupingConfig{Wait: 10 * time.Second, Interval: time.Minute})
+	now := time.Now()
+	wecom := &model.NotifyChannel{ID: 1}
+	slack := &model.NotifyChannel{ID: 2}
+
+	other := testAlert(4, "redis", model.AlertStatusFiring)
+	other.TargetType = "redis"
+
+	g.add(wecom, testAlert(1, "node-a", model.AlertStatusFiring), now)
+	g.add(wecom, testAlert(2, "node-b", model.AlertStatusResolved), now) // 状态不参与分组
+	g.add(wecom, other, now)                                             // 类型不同
+	g.add(slack, testAlert(1, "node-a", model.AlertStatusFiring), now)   // 渠道不同
+
+	if flushes := g.due(now.Add(9*time.Second), false); len(flushes) != 0 {
+		t.Fatalf("group_wait 未到期不应发送: %d", len(flushes))
+	}
+	flushes := g.due(now.Add(10*time.Second), false)
+	if len(flushes) != 3 {
+		t.Fatalf("分组数 = %d, want 3", len(flushes))
+	}
+	sizes := make(map[uint64][]int)
+	for _, flush := range flushes {
+		sizes[flush.channel.ID] = append(sizes[flush.channel.ID], len(flush.alerts))
+	}
+	if len(sizes[1]) != 2 || sizes[1][0]+sizes[1][1] != 3 || len(sizes[2]) != 1 || sizes[2][0] != 1 {
+		t.Fatalf("分组结果不正确: %v", sizes)
+	}
+}
+
+func TestAlertGrouperReplacesPendingAlert(t *testing.T) {
+	g := newAlertGrouper(GroupingConfig{Wait: 10 * time.Second})
+	now := time.Now()
+	channel := &model.NotifyChannel{ID: 1}
+
+	g.add(channel, testAlert(1, "node-a", model.AlertStatusFiring), now)
+	g.add(channel, testAlert(2, "node-b", model.AlertStatusFiring), now)
+	latest := testAlert(2, "node-b", model.AlertStatusFiring)
+	g.add(channel, latest, now.Add(time.Second))
+	if !g.pending(1) || !g.pending(2) || g.pending(3) {
+		t.Fatal("pending 结果不正确")
+	}
+
+	// 告警通知尚未发送即恢复：渠道从未收到过该告警，告警与恢复通知一并丢弃
+	g.add(channel, testAlert(1, "node-a", model.AlertStatusResolved), now.Add(2*time.Second))
+	if g.pending(1) {
+		t.Fatal("发送前恢复的告警不应再等待发送")
+	}
+	flushes := g.due(now.Add(10*time.Second), false)
+	if len(flushes) != 1 || len(flushes[0].alerts) != 1 {
+		t.Fatalf("同一目标应只保留最新的告警，发送前恢复的告警不发送: %+v", flushes)
+	}
+	if flushes[0].alerts[0] != latest {
+		t.Fatal("应发送同一目标最新的告警")
+	}
+	if g.pending(2) {
+		t.Fatal("发送后不应再有等待中的告警")
+	}
+
+	// 分组中唯一的告警被丢弃后取消计划发送，之后的新告警重新等待 group_wait
+	other := &model.NotifyChannel{ID: 2}
+	g.add(other, testAlert(3, "node-c", model.AlertStatusFiring), now)
+	g.add(other, testAlert(3, "node-c", model.AlertStatusResolved), now.Add(time.Second))
+	if flushes := g.due(now.Add(10*time.Second), false); len(flushes) != 0 {
+		t.Fatalf("没有待发送告警的分组不应发送: %+v", flushes)
+	}
+	g.add(other, testAlert(4, "node-d", model.AlertStatusFiring), now.Add(20*time.Second))
+	if flushes := g.due(now.Add(29*time.Second), false); len(flushes) != 0 {
+		t.Fatal("新告警应重新等待 group_wait")
+	}
+
+	// 已通知过的告警撤回等待发送的重复通知后，恢复通知正常发送
+	g.discard(4)
+	resolved := testAlert(4, "node-d", model.AlertStatusResolved)
+	g.add(other, resolved, now.Add(21*time.Second))
+	flushes = g.due(now.Add(31*time.Second), false)
+	if len(flushes) != 1 || len(flushes[0].alerts) != 1 || flushes[0].alerts[0] != resolved {
+		t.Fatalf("撤回重复通知后应只发送恢复通知: %+v", flushes)
+	}
+}
+
+func TestAlertGrouperInterval(t *testing.T) {
+	g := newAlertGrouper(GroupingConfig{Wait: 10 * time.Second, Interval: time.Minute})
+	now := time.Now()
+	channel := &model.NotifyChannel{ID: 1}
+
+	g.add(channel, testAlert(1, "node-a", model.AlertStatusFiring), now)
+	sentAt := now.Add(10 * time.Second)
+	if len(g.due(sentAt, false)) != 1 {
+		t.Fatal("首批告警应在 group_wait 后发送")
+	}
+
+	// 距上次发送不足 group_interval 时推迟到满足间隔
+	g.add(channel, testAlert(2, "node-b", model.AlertStatusFiring), sentAt.Add(5*time.Second))
+	if flushes := g.due(sentAt.Add(59*time.Second), false); len(flushes) != 0 {
+		t.Fatalf("group_interval 未到期不应发送: %d", len(flushes))
+	}
+	if flushes := g.due(sentAt.Add(time.Minute), false); len(flushes) != 1 {
+		t.Fatalf("group_interval 到期后应发送: %d", len(flushes))
+	}
+
+	// 停止服务前发送所有待发送的分组
+	g.add(channel, testAlert(3, "node-c", model.AlertStatusFiring), sentAt.Add(61*time.Second))
+	if flushes := g.due(sentAt.Add(62*time.Second), true); len(flushes) != 1 {
+		t.Fatalf("all 为 true 时应发送所有分组: %d", len(flushes))
+	}
+}
+
+func TestMergeAlerts(t *testing.T) {
+	single := testAlert(1, "node-a", model.AlertStatusFiring)
+	if merged := mergeAlerts([]*model.Alert{single}); merged != single {
+		t.Fatal("只有一条告警时应原样返回")
+	}
+
+	resolvedAt := time.Now()
+	earlier := resolvedAt.Add(-time.Hour)
+	a := testAlert(1, "node-a", model.AlertStatusResolved)
+	a.ResolvedAt = &resolvedAt
+	b := testAlert(2, "node-b", model.AlertStatusFiring)
+	b.Severity = model.SeverityWarning
+	b.FiredAt = earlier
+
+	merged := mergeAlerts([]*model.Alert{a, b})
+	if merged.Status != model.AlertStatusFiring {
+		t.Fatalf("存在未恢复的告警时应为告警状态: %s", merged.Status)
+	}
+	if merged.Severity != model.SeverityCritical || !merged.FiredAt.Equal(earlier) {
+		t.Fatalf("应取最高级别与最早触发时间: %s %v", merged.Severity, merged.FiredAt)
+	}
+	if merged.ResolvedAt != nil {
+		t.Fatal("存在未恢复的告警时不应设置恢复时间")
+	}
+	if len(merged.Alerts) != 2 || merged.TargetName != "node-a 等 2 个目标" {
+		t.Fatalf("合并结果不正确: %s %d", merged.TargetName, len(merged.Alerts))
+	}
+	for _, want := range []string{"1 个告警、1 个恢复", "[恢复] node-a", "[告警] node-b"} {
+		if !strings.Contains(merged.Message, want) {
+			t.Fatalf("合并消息缺少 %q: %s", want, merged.Message)
+		}
+	}
+
+	c := testAlert(3, "node-c", model.AlertStatusResolved)
+	c.ResolvedAt = &earlier
+	allResolved := mergeAlerts([]*model.Alert{a, c})
+	if allResolved.Status != model.AlertStatusResolved || allResolved.ResolvedAt == nil || !allResolved.ResolvedAt.Equal(resolvedAt) {
+		t.Fatalf("全部恢复时应为恢复状态并取最晚的恢复时间: %s %v", allResolved.Status, allResolved.ResolvedAt)
+	}
+	if strings.Contains(allResolved.Message, "[恢复]") {
+		t.Fatalf("状态相同时不标注各条告警的状态: %s", allResolved.Message)
+	}
+}
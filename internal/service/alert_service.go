@@ -1,13 +1,11 @@
 package service
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
-	"net/http"
-	"sync"
+	"strings"
 	"time"
 
 	"github.com/thingsboard-rxprobe/internal/alerter"
@@ -20,16 +18,33 @@ import (
 	"gorm.io/gorm"
 )
 
+// periodicCheckInterval 告警定时检查（升级、根因通知）间隔
+const periodicCheckInterval = 30 * time.Second
+
+// 告警处理相关错误
+var (
+	ErrAlertResolved     = errors.New("告警已恢复")
+	ErrAlertAcknowledged = errors.New("告警已确认")
+	ErrAlertNotFiring    = errors.New("告警当前不处于告警中状态")
+)
+
 // AlertService 告警服务
 type AlertService struct {
-	alertRepo    *repository.AlertRepository
-	targetRepo   *repository.TargetRepository
-	resultRepo   *repository.ResultRepository
-	notifierRepo *repository.NotifierRepository
-	alerter      alerter.Alerter
-	scheduler    *scheduler.Scheduler
-	silenceMap   sync.Map // map[uint64]time.Time 静默记录
-	stopChan     chan struct{}
+	alertRepo      *repository.AlertRepository
+	targetRepo     *repository.TargetRepository
+	resultRepo     *repository.ResultRepository
+	notifierRepo   *repository.NotifierRepository
+	silenceRepo    *repository.SilenceRepository
+	silences       *listCache[*model.Silence] // 尚未结束的静默规则
+	escalationRepo *repository.EscalationRepository
+	maintenance    *MaintenanceService
+	routing        *RoutingService
+	notifications  *NotificationService
+	grouper        *alertGrouper // 告警分组缓冲，未启用分组时为 nil
+	alerter        alerter.Alerter
+	scheduler      *scheduler.Scheduler
+	externalURL    string // 对外访问地址，用于生成告警详情链接
+	stopChan       chan struct{}
 }
 
 // NewAlertService 创建告警服务
@@ -38,17 +53,39 @@ func NewAlertService(
 	targetRepo *repository.TargetRepository,
 	resultRepo *repository.ResultRepository,
 	notifierRepo *repository.NotifierRepository,
+	silenceRepo *repository.SilenceRepository,
+	escalationRepo *repository.EscalationRepository,
+	maintenance *MaintenanceService,
+	routing *RoutingService,
+	notifications *NotificationService,
 	alerter alerter.Alerter,
 	sch *scheduler.Scheduler,
+	externalURL string,
+	grouping GroupingConfig,
 ) *AlertService {
+	var grouper *alertGrouper
+	if grouping.Enabled() {
+		grouper = newAlertGrouper(grouping)
+	}
+	silences := newListCache(ruleCacheTTL, func(ctx context.Context) ([]*model.Silence, error) {
+		return silenceRepo.ListUnexpired(ctx, time.Now())
+	})
 	return &AlertService{
-		alertRepo:    alertRepo,
-		targetRepo:   targetRepo,
-		resultRepo:   resultRepo,
-		notifierRepo: notifierRepo,
-		alerter:      alerter,
-		scheduler:    sch,
-		stopChan:     make(chan struct{}),
+		alertRepo:      alertRepo,
+		targetRepo:     targetRepo,
+		resultRepo:     resultRepo,
+		notifierRepo:   notifierRepo,
+		silenceRepo:    silenceRepo,
+		silences:       silences,
+		escalationRepo: escalationRepo,
+		maintenance:    maintenance,
+		routing:        routing,
+		notifications:  notifications,
+		grouper:        grouper,
+		alerter:        alerter,
+		scheduler:      sch,
+		externalURL:    strings.TrimRight(externalURL, "/"),
+		stopChan:       make(chan struct{}),
 	}
 }
 
@@ -56,12 +93,19 @@ func NewAlertService(
 func (s *AlertService) Start(ctx context.Context) {
 	go s.processAlerts(ctx)
 	go s.processResults(ctx)
+	go s.processPeriodicChecks(ctx)
+	if s.grouper != nil {
+		go s.processGroups(ctx)
+	}
 	logger.Info("告警服务已启动")
 }
 
-// Stop 停止告警服务
+// Stop 停止告警服务，发送仍在分组中等待的通知
 func (s *AlertService) Stop() {
 	close(s.stopChan)
+	if s.grouper != nil {
+		s.flushGroups(context.Background(), true)
+	}
 	logger.Info("告警服务已停止")
 }
 
@@ -99,21 +143,38 @@ func (s *AlertService) processResults(ctx context.Context) {
 
 // handleAlert 处理告警
 func (s *AlertService) handleAlert(ctx context.Context, event *scheduler.AlertEvent) {
+	if event.Flapping {
+		s.handleFlapping(ctx, event)
+		return
+	}
+
 	// 静默期只影响“发送通知”，不影响告警记录/目标状态更新
-	silenced := s.isSilenced(event.Target.ID)
+	silenced := s.isSilenced(ctx, event.Target)
 
 	firedAt := event.Result.CheckedAt
 	if firedAt.IsZero() {
 		firedAt = time.Now()
 	}
 
+	// 维护窗口同样只抑制通知，告警记录会标记为维护中
+	window := s.maintenance.ActiveWindow(ctx, event.Target, firedAt)
+
+	// 上游目标故障时，本目标的故障视为下游影响，由上游的根因通知统一告知
+	var parent *model.ProbeTarget
+	if event.Status == model.AlertStatusFiring {
+		parent = s.unhealthyParent(ctx, event.Target)
+	}
+
 	var configMap map[string]any
 	if err := json.Unmarshal(event.Target.Config, &configMap); err != nil {
 		logger.Error("解析配置失败", zap.Error(err))
 	}
 
 	if event.Status == model.AlertStatusFiring {
-		// “每次失败都告警”会频繁触发 firing 事件：这里做记录层去重
+		severity := alertSeverity(event.Target, event.Result)
+		message := event.Result.Summary()
+
+		// 达到失败阈值后每次失败都会产生 firing 事件：这里做记录层去重
 		// - 如果已有未恢复告警记录：更新其 message/latency（保持 fired_at 作为故障开始时间）
 		// - 如果没有：创建新的未恢复告警记录
 		record, err := s.alertRepo.GetLastFiringRecord(ctx, event.Target.ID)
@@ -125,6 +186,7 @@ func (s *AlertService) handleAlert(ctx context.Context, event *scheduler.AlertEv
 		}
 
 		isNewRecord := false
+		severityRaised := false
 		if record == nil {
 			isNewRecord = true
 			record = &model.AlertRecord{
@@ -132,19 +194,46 @@ func (s *AlertService) handleAlert(ctx context.Context, event *scheduler.AlertEv
 				TargetName: event.Target.Name,
 				TargetType: event.Target.Type,
 				Status:     model.AlertStatusFiring,
-				Message:    event.Result.Message,
+				Severity:   severity,
+				Message:    message,
 				LatencyMs:  event.Result.Latency.Milliseconds(),
 				FiredAt:    firedAt,
 			}
+			if window != nil {
+				record.InMaintenance = true
+				record.MaintenanceID = window.ID
+			}
+			if parent != nil {
+				record.SuppressedBy = parent.ID
+			}
 			if err := s.alertRepo.CreateRecord(ctx, record); err != nil {
 				logger.Error("创建告警记录失败", zap.Error(err))
+			} else {
+				s.addActivity(ctx, record.ID, model.AlertActivityFired, "", message)
+				if parent != nil {
+					s.addActivity(ctx, record.ID, model.AlertActivitySuppressed, "", "上游目标 "+parent.Name+" 故障")
+				}
 			}
 		} else {
 			// 更新为最新失败原因（但不改变 FiredAt）
 			record.TargetName = event.Target.Name
 			record.TargetType = event.Target.Type
-			record.Message = event.Result.Message
+			record.Message = message
 			record.LatencyMs = event.Result.Latency.Milliseconds()
+			// 级别升高（如警告变为严重）时立即通知，以便按新级别路由
+			severityRaised = severity.Rank() > record.Severity.Rank()
+			record.Severity = severity
+			if window != nil && !record.InMaintenance {
+				record.InMaintenance = true
+				record.MaintenanceID = window.ID
+			}
+			if parent != nil && record.SuppressedBy != parent.ID {
+				record.SuppressedBy = parent.ID
+				s.addActivity(ctx, record.ID, model.AlertActivitySuppressed, "", "上游目标 "+parent.Name+" 故障")
+			} else if parent == nil && record.SuppressedBy != 0 {
+				// 上游已恢复而本目标仍故障，此后按独立故障通知
+				record.SuppressedBy = 0
+			}
 		}
 
 		// 发送告警通知
@@ -153,23 +242,68 @@ func (s *AlertService) handleAlert(ctx context.Context, event *scheduler.AlertEv
 			TargetID:   event.Target.ID,
 			TargetName: event.Target.Name,
 			TargetType: event.Target.Type,
+			Group:      event.Target.Group,
+			Endpoint:   targetEndpoint(configMap),
 			Status:     model.AlertStatusFiring,
-			Message:    event.Result.Message,
+			Severity:   severity,
+			Message:    message,
 			Latency:    event.Result.Latency,
 			// 通知里的时间使用本次失败发生时间，避免每次重复告警都显示“首次失败时间”
-			FiredAt: firedAt,
+			FiredAt:   firedAt,
+			DetailURL: s.detailURL(event.Target.ID),
+			Metrics:   event.Result.Metrics,
 		}
 
-		if silenced {
+		if window != nil {
+			logger.Debug("目标处于维护窗口，跳过发送通知",
+				zap.Uint64("target_id", event.Target.ID),
+				zap.Uint64("maintenance_id", window.ID),
+			)
+		} else if parent != nil {
+			logger.Debug("上游目标故障，告警被依赖抑制",
+				zap.Uint64("target_id", event.Target.ID),
+				zap.Uint64("parent_id", parent.ID),
+			)
+		} else if silenced {
 			logger.Debug("告警处于静默期，跳过发送通知",
 				zap.Uint64("target_id", event.Target.ID),
 			)
+		} else if record.Status == model.AlertStatusAcknowledged {
+			logger.Debug("告警已确认，跳过发送通知",
+				zap.Uint64("target_id", event.Target.ID),
+				zap.String("acknowledged_by", record.AcknowledgedBy),
+			)
+		} else if s.escalate(ctx, event.Target, record, alert, firedAt) {
+			// 本次到达新的升级步骤，已发送升级通知
+		} else if record.EscalationLevel == 0 && s.hasEscalationPolicy(ctx, event.Target) {
+			// 升级策略无法加载时不在此跳过，按目标的通知渠道发送，避免告警无人接收
+			logger.Debug("告警尚未到达第一个升级步骤，跳过发送通知",
+				zap.Uint64("target_id", event.Target.ID),
+			)
+		} else if !severityRaised && !record.Notified && s.notificationQueued(ctx, record) {
+			logger.Debug("告警通知等待重试或排队发送，跳过发送通知",
+				zap.Uint64("target_id", event.Target.ID),
+			)
+		} else if !severityRaised && !s.shouldRepeatNotify(event.Target, record, firedAt) {
+			logger.Debug("未达到重复通知间隔，跳过发送通知",
+				zap.Uint64("target_id", event.Target.ID),
+				zap.Int("repeat_interval_seconds", event.Target.RepeatIntervalSeconds),
+			)
 		} else {
 			// 从数据库获取启用的通知渠道并发送
-			if err := s.sendToAllChannels(ctx, alert); err != nil {
+			if queued, err := s.sendToAllChannels(ctx, alert, record.EscalationLevel); err != nil {
 				logger.Error("发送告警失败", zap.Error(err))
+			} else if queued {
+				// 尚未实际发送，发送成功后由通知投递服务回写通知状态
+				s.addActivity(ctx, record.ID, model.AlertActivityNotified, "", "告警通知已排队等待发送")
+				logger.Info("告警通知已排队",
+					zap.Uint64("target_id", event.Target.ID),
+					zap.String("target_name", event.Target.Name),
+				)
 			} else {
 				record.Notified = true
+				record.LastNotifiedAt = &firedAt
+				s.addActivity(ctx, record.ID, model.AlertActivityNotified, "", "已发送告警通知")
 				logger.Info("告警发送成功",
 					zap.Uint64("target_id", event.Target.ID),
 					zap.String("target_name", event.Target.Name),
@@ -177,23 +311,23 @@ func (s *AlertService) handleAlert(ctx context.Context, event *scheduler.AlertEv
 			}
 		}
 
-		// 持久化记录更新（避免“每次失败都告警”模式下无限新增 firing 记录）
+		// 持久化记录更新（避免持续失败时无限新增 firing 记录）
 		if isNewRecord {
 			// 新记录已 Create；仅在通知成功且 Notified 变更时需要 Update
 			if record.Notified {
-				if err := s.alertRepo.UpdateRecord(ctx, record); err != nil {
+				if err := s.alertRepo.UpdateFiringRecord(ctx, record); err != nil {
 					logger.Error("更新告警记录失败", zap.Error(err))
 				}
 			}
 		} else {
 			// 老记录 message/latency 可能变化；Notified 也可能变化
-			if err := s.alertRepo.UpdateRecord(ctx, record); err != nil {
+			if err := s.alertRepo.UpdateFiringRecord(ctx, record); err != nil {
 				logger.Error("更新告警记录失败", zap.Error(err))
 			}
 		}
 
 		// 更新目标状态
-		s.targetRepo.UpdateStatus(ctx, event.Target.ID, model.TargetStatusUnhealthy, event.Result.Latency.Milliseconds(), event.Result.Message)
+		s.targetRepo.UpdateStatus(ctx, event.Target.ID, targetStatus(event.Result), event.Result.Latency.Milliseconds(), message)
 
 	} else if event.Status == model.AlertStatusResolved {
 		// 查找并恢复告警记录（同时发送恢复通知）
@@ -211,6 +345,8 @@ func (s *AlertService) handleAlert(ctx context.Context, event *scheduler.AlertEv
 			// 先落库更新状态/恢复时间
 			if err := s.alertRepo.ResolveRecordAt(ctx, record.ID, resolvedAt); err != nil {
 				logger.Error("恢复告警记录失败", zap.Error(err))
+			} else {
+				s.addActivity(ctx, record.ID, model.AlertActivityResolved, "", event.Result.Message)
 			}
 
 			// 发送恢复通知（时间跨度使用“故障开始时间 record.FiredAt”）
@@ -219,18 +355,41 @@ func (s *AlertService) handleAlert(ctx context.Context, event *scheduler.AlertEv
 				TargetID:   event.Target.ID,
 				TargetName: event.Target.Name,
 				TargetType: event.Target.Type,
+				Group:      event.Target.Group,
+				Endpoint:   targetEndpoint(configMap),
 				Status:     model.AlertStatusResolved,
+				Severity:   record.Severity,
 				Message:    event.Result.Message,
+				Latency:    event.Result.Latency,
 				FiredAt:    record.FiredAt,
 				ResolvedAt: &resolvedAt,
+				DetailURL:  s.detailURL(event.Target.ID),
+				Metrics:    event.Result.Metrics,
 			}
 
-			if silenced {
+			if window != nil || (record.InMaintenance && !record.Notified) {
+				// 维护中恢复，或故障只发生在维护期间（从未通知过），均不发送恢复通知
+				logger.Debug("告警在维护窗口内恢复，跳过发送恢复通知",
+					zap.Uint64("target_id", event.Target.ID),
+				)
+			} else if record.SuppressedBy > 0 && !record.Notified {
+				logger.Debug("告警被依赖抑制且未通知过，跳过发送恢复通知",
+					zap.Uint64("target_id", event.Target.ID),
+				)
+			} else if silenced {
 				logger.Debug("告警处于静默期，跳过发送恢复通知",
 					zap.Uint64("target_id", event.Target.ID),
 				)
+			} else if s.awaitingEscalation(ctx, event.Target, record) {
+				logger.Debug("告警恢复前未到达第一个升级步骤，跳过发送恢复通知",
+					zap.Uint64("target_id", event.Target.ID),
+				)
 			} else {
-				if err := s.sendToAllChannels(ctx, alert); err != nil {
+				if record.Notified && s.grouper != nil {
+					// 已通知过的告警撤回等待发送的重复通知，恢复通知随后单独进入分组，不会与其一同被丢弃
+					s.grouper.discard(record.ID)
+				}
+				if _, err := s.sendToAllChannels(ctx, alert, record.EscalationLevel); err != nil {
 					logger.Error("发送恢复通知失败", zap.Error(err))
 				} else {
 					logger.Info("恢复通知发送成功",
@@ -242,8 +401,89 @@ func (s *AlertService) handleAlert(ctx context.Context, event *scheduler.AlertEv
 		}
 
 		// 更新目标状态
-		s.targetRepo.UpdateStatus(ctx, event.Target.ID, model.TargetStatusHealthy, event.Result.Latency.Milliseconds(), event.Result.Message)
+		s.targetRepo.UpdateStatus(ctx, event.Target.ID, targetStatus(event.Result), event.Result.Latency.Milliseconds(), event.Result.Summary())
+	}
+}
+
+// handleFlapping 处理抖动事件：目标进入抖动时发送一次抖动通知
+// 抖动期间调度器不再产生告警/恢复事件，恢复稳定后按正常流程继续
+func (s *AlertService) handleFlapping(ctx context.Context, event *scheduler.AlertEvent) {
+	target := event.Target
+	message := fmt.Sprintf("目标状态频繁变化（变化率 %.0f%%），暂停发送告警与恢复通知直到状态稳定", event.FlapRate)
+	s.targetRepo.UpdateStatus(ctx, target.ID, model.TargetStatusFlapping, event.Result.Latency.Milliseconds(), event.Result.Message)
+
+	// 抖动前已有未恢复告警时，抖动通知关联到该告警，并按告警已到达的升级级别通知
+	var record *model.AlertRecord
+	if r, err := s.alertRepo.GetLastFiringRecord(ctx, target.ID); err == nil {
+		record = r
+		s.addActivity(ctx, record.ID, model.AlertActivityFlapping, "", message)
+	}
+
+	checkedAt := event.Result.CheckedAt
+	if checkedAt.IsZero() {
+		checkedAt = time.Now()
 	}
+	if s.maintenance.ActiveWindow(ctx, target, checkedAt) != nil || s.isSilenced(ctx, target) || s.unhealthyParent(ctx, target) != nil {
+		logger.Debug("目标处于维护、静默或依赖抑制中，跳过发送抖动通知", zap.Uint64("target_id", target.ID))
+		return
+	}
+
+	escalationLevel, ok := s.flappingLevel(ctx, target, record)
+	if !ok {
+		logger.Debug("抖动通知尚未到达第一个升级步骤，跳过发送", zap.Uint64("target_id", target.ID))
+		return
+	}
+	var recordID uint64
+	if record != nil {
+		recordID = record.ID
+	}
+
+	var configMap map[string]any
+	_ = json.Unmarshal(target.Config, &configMap)
+	alert := &model.Alert{
+		ID:         recordID,
+		TargetID:   target.ID,
+		TargetName: target.Name,
+		TargetType: target.Type,
+		Group:      target.Group,
+		Endpoint:   targetEndpoint(configMap),
+		Status:     model.AlertStatusFiring,
+		Severity:   target.GetSeverity().Lower(model.SeverityWarning),
+		Message:    message + "\n最近一次探测：" + event.Result.Message,
+		Latency:    event.Result.Latency,
+		FiredAt:    checkedAt,
+		DetailURL:  s.detailURL(target.ID),
+		Metrics:    event.Result.Metrics,
+	}
+	if _, err := s.sendToAllChannels(ctx, alert, escalationLevel); err != nil {
+		logger.Error("发送抖动通知失败", zap.Error(err))
+		return
+	}
+	logger.Info("抖动通知发送成功",
+		zap.Uint64("target_id", target.ID),
+		zap.Float64("flap_rate", event.FlapRate),
+	)
+}
+
+// flappingLevel 计算抖动通知使用的升级级别，与告警通知的规则一致：
+// 已有未恢复告警时使用其已到达的级别，否则为升级策略中无需等待的步骤数；
+// 目标使用可用的升级策略但尚未到达任何步骤时返回 false，不发送通知（升级策略无法加载时回退到目标的通知渠道）
+func (s *AlertService) flappingLevel(ctx context.Context, target *model.ProbeTarget, record *model.AlertRecord) (int, bool) {
+	level := 0
+	if record != nil {
+		level = record.EscalationLevel
+	}
+	if target.EscalationPolicyID == 0 {
+		return level, true
+	}
+	steps, err := s.escalationSteps(ctx, target.EscalationPolicyID)
+	if err != nil {
+		return level, true
+	}
+	if record == nil {
+		level = model.ReachedLevel(steps, 0)
+	}
+	return level, level > 0
 }
 
 // saveResult 保存探测结果
@@ -255,6 +495,7 @@ func (s *AlertService) saveResult(ctx context.Context, event *scheduler.ProbeRes
 	result := &model.ProbeResult{
 		TargetID:  event.TargetID,
 		Success:   event.Result.Success,
+		Outcome:   string(event.Result.Outcome()),
 		LatencyMs: event.Result.Latency.Milliseconds(),
 		Message:   event.Result.Message,
 		Metrics:   metricsJSON,
@@ -270,33 +511,507 @@ func (s *AlertService) saveResult(ctx context.Context, event *scheduler.ProbeRes
 	}
 
 	// 更新目标状态
-	status := model.TargetStatusHealthy
-	if !event.Result.Success {
-		status = model.TargetStatusUnhealthy
+	status := targetStatus(event.Result)
+	if event.Flapping {
+		status = model.TargetStatusFlapping
+	}
+	s.targetRepo.UpdateStatus(ctx, event.TargetID, status, event.Result.Latency.Milliseconds(), event.Result.Summary())
+}
+
+// processPeriodicChecks 定时检查告警升级与根因通知
+func (s *AlertService) processPeriodicChecks(ctx context.Context) {
+	ticker := time.NewTicker(periodicCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.stopChan:
+			return
+		case <-ticker.C:
+			s.checkEscalations(ctx)
+			s.checkRootCauses(ctx)
+		}
+	}
+}
+
+// checkEscalations 检查所有告警中（未确认）的记录，到达新的升级步骤时发送升级通知
+// 告警处理只在探测失败时触发，探测间隔较长时需要由该任务按时升级
+func (s *AlertService) checkEscalations(ctx context.Context) {
+	records, err := s.alertRepo.ListByStatus(ctx, model.AlertStatusFiring)
+	if err != nil {
+		logger.Error("查询告警中的记录失败", zap.Error(err))
+		return
+	}
+
+	now := time.Now()
+	for _, record := range records {
+		target, err := s.targetRepo.GetByID(ctx, record.TargetID)
+		if err != nil || !target.Enabled || target.EscalationPolicyID == 0 {
+			continue
+		}
+		if s.isSilenced(ctx, target) || s.maintenance.ActiveWindow(ctx, target, now) != nil {
+			continue
+		}
+
+		if record.SuppressedBy > 0 {
+			continue
+		}
+		s.escalate(ctx, target, record, s.recordAlert(record, target), now)
+	}
+}
+
+// checkRootCauses 检查未恢复的上游目标告警，出现尚未通知的受影响下游告警时发送一条根因通知，列出所有受影响的下游目标
+// 同一检查周期内被抑制的下游目标会合并到同一条通知中
+func (s *AlertService) checkRootCauses(ctx context.Context) {
+	records, err := s.alertRepo.ListUnresolved(ctx)
+	if err != nil {
+		logger.Error("查询未恢复的告警记录失败", zap.Error(err))
+		return
+	}
+
+	// 被抑制的下游告警同样未恢复，直接按上游目标分组，避免逐条查询
+	suppressed := make(map[uint64][]*model.AlertRecord)
+	for _, record := range records {
+		if record.SuppressedBy > 0 {
+			suppressed[record.SuppressedBy] = append(suppressed[record.SuppressedBy], record)
+		}
+	}
+
+	now := time.Now()
+	for _, record := range records {
+		children := suppressed[record.TargetID]
+		if len(children) == 0 {
+			continue
+		}
+		notified := make(map[uint64]bool)
+		for _, id := range record.GetImpactedIDs() {
+			notified[id] = true
+		}
+		childIDs := make([]uint64, 0, len(children))
+		hasNew := false
+		for _, child := range children {
+			childIDs = append(childIDs, child.ID)
+			hasNew = hasNew || !notified[child.ID]
+		}
+		if !hasNew {
+			continue
+		}
+
+		target, err := s.targetRepo.GetByID(ctx, record.TargetID)
+		if err != nil {
+			continue
+		}
+		if s.isSilenced(ctx, target) || s.maintenance.ActiveWindow(ctx, target, now) != nil {
+			continue
+		}
+		if s.awaitingEscalation(ctx, target, record) {
+			// 上游告警到达第一个升级步骤后再发送根因通知
+			continue
+		}
+
+		names := make([]string, 0, len(children))
+		for _, child := range children {
+			names = append(names, child.TargetName)
+		}
+		impacted := fmt.Sprintf("受影响的下游目标（%d）：%s", len(names), strings.Join(names, "、"))
+
+		alert := s.recordAlert(record, target)
+		alert.Message = record.Message + "\n" + impacted
+		if queued, err := s.sendToAllChannels(ctx, alert, record.EscalationLevel); err != nil {
+			logger.Error("发送根因通知失败", zap.Uint64("alert_id", record.ID), zap.Error(err))
+		} else if queued {
+			s.addActivity(ctx, record.ID, model.AlertActivityNotified, "", "根因通知已排队等待发送，"+impacted)
+		} else {
+			s.addActivity(ctx, record.ID, model.AlertActivityNotified, "", "已发送根因通知，"+impacted)
+		}
+
+		// 无论发送是否成功都记录已处理的下游告警，失败的投递由发件箱重试，避免每个周期重复发送
+		if err := s.alertRepo.UpdateImpactedIDs(ctx, record.ID, childIDs); err != nil {
+			logger.Error("更新受影响的下游告警失败", zap.Error(err))
+		}
+		logger.Info("根因通知已发送",
+			zap.Uint64("alert_id", record.ID),
+			zap.Uint64("target_id", target.ID),
+			zap.Int("impacted", len(children)),
+		)
+	}
+}
+
+// unhealthyParent 获取目标处于故障中的上游目标，不存在时返回 nil
+func (s *AlertService) unhealthyParent(ctx context.Context, target *model.ProbeTarget) *model.ProbeTarget {
+	parentIDs := target.GetParentIDs()
+	if len(parentIDs) == 0 {
+		return nil
+	}
+	parents, err := s.targetRepo.GetByIDs(ctx, parentIDs)
+	if err != nil {
+		logger.Error("查询上游目标失败", zap.Uint64("target_id", target.ID), zap.Error(err))
+		return nil
+	}
+	for _, parent := range parents {
+		if !parent.Enabled {
+			continue
+		}
+		switch parent.Status {
+		case model.TargetStatusUnhealthy, model.TargetStatusFlapping:
+			return parent
+		case model.TargetStatusDegraded:
+			// 降级的上游目标仍然可用，其告警（如警告级别的指标规则）不是下游故障的根因
+			continue
+		}
+		if s.alertRepo.HasUnresolvedAlert(ctx, parent.ID) {
+			return parent
+		}
+	}
+	return nil
+}
+
+// recordAlert 根据告警记录与目标构造告警通知内容
+func (s *AlertService) recordAlert(record *model.AlertRecord, target *model.ProbeTarget) *model.Alert {
+	var configMap map[string]any
+	_ = json.Unmarshal(target.Config, &configMap)
+	return &model.Alert{
+		ID:         record.ID,
+		TargetID:   target.ID,
+		TargetName: target.Name,
+		TargetType: target.Type,
+		Group:      target.Group,
+		Endpoint:   targetEndpoint(configMap),
+		Status:     model.AlertStatusFiring,
+		Severity:   record.Severity,
+		Message:    record.Message,
+		Latency:    time.Duration(record.LatencyMs) * time.Millisecond,
+		FiredAt:    record.FiredAt,
+		DetailURL:  s.detailURL(target.ID),
+	}
+}
+
+// escalate 告警到达新的升级步骤时，向新到达步骤的渠道发送通知
+// 返回本次是否发生升级；发生升级时同步更新 record 的升级级别与通知状态
+func (s *AlertService) escalate(ctx context.Context, target *model.ProbeTarget, record *model.AlertRecord, alert *model.Alert, now time.Time) bool {
+	if target.EscalationPolicyID == 0 || record.ID == 0 {
+		return false
+	}
+	steps, err := s.escalationSteps(ctx, target.EscalationPolicyID)
+	if err != nil {
+		logger.Warn("获取升级策略失败",
+			zap.Uint64("target_id", target.ID),
+			zap.Uint64("escalation_policy_id", target.EscalationPolicyID),
+			zap.Error(err),
+		)
+		return false
+	}
+
+	from := record.EscalationLevel
+	to := model.ReachedLevel(steps, now.Sub(record.FiredAt))
+	if to <= from {
+		return false
+	}
+	advanced, err := s.alertRepo.AdvanceEscalation(ctx, record.ID, from, to)
+	if err != nil {
+		logger.Error("更新告警升级级别失败", zap.Error(err))
+		return false
+	}
+	if !advanced {
+		// 已被其他任务升级
+		return false
+	}
+	record.EscalationLevel = to
+
+	channelIDs := model.StepChannelIDs(steps, from, to)
+	s.addActivity(ctx, record.ID, model.AlertActivityEscalated, "",
+		fmt.Sprintf("升级到第 %d 步，通知 %d 个渠道", to, len(channelIDs)))
+
+	channels, err := s.enabledChannels(ctx, channelIDs)
+	if err != nil {
+		logger.Error("发送升级通知失败", zap.Error(err))
+		return true
+	}
+	if len(channels) == 0 {
+		logger.Warn("升级步骤没有可用的通知渠道",
+			zap.Uint64("alert_id", record.ID),
+			zap.Int("level", to),
+		)
+		return true
+	}
+	sent, queued, err := s.deliver(ctx, channels, alert)
+	if sent > 0 {
+		record.Notified = true
+		record.LastNotifiedAt = &now
+		if err := s.alertRepo.MarkNotified(ctx, record.ID, now); err != nil {
+			logger.Error("更新告警通知状态失败", zap.Error(err))
+		}
+	}
+	if err != nil {
+		logger.Error("发送升级通知失败", zap.Uint64("alert_id", record.ID), zap.Error(err))
+	}
+	logger.Info("告警已升级",
+		zap.Uint64("alert_id", record.ID),
+		zap.Uint64("target_id", target.ID),
+		zap.Int("level", to),
+		zap.Int("sent", sent),
+		zap.Int("queued", queued),
+	)
+	return true
+}
+
+// escalationSteps 获取升级策略的步骤
+func (s *AlertService) escalationSteps(ctx context.Context, policyID uint64) ([]model.EscalationStep, error) {
+	policy, err := s.escalationRepo.GetByID(ctx, policyID)
+	if err != nil {
+		return nil, err
+	}
+	return policy.ParseSteps()
+}
+
+// hasEscalationPolicy 目标是否配置了可用的升级策略，策略已删除或无法解析时返回 false
+func (s *AlertService) hasEscalationPolicy(ctx context.Context, target *model.ProbeTarget) bool {
+	if target.EscalationPolicyID == 0 {
+		return false
+	}
+	_, err := s.escalationSteps(ctx, target.EscalationPolicyID)
+	return err == nil
+}
+
+// awaitingEscalation 告警是否在等待到达第一个升级步骤：目标使用可用的升级策略，告警尚未升级且从未通知过
+// 此时没有任何渠道收到过告警，恢复与根因通知也不发送
+func (s *AlertService) awaitingEscalation(ctx context.Context, target *model.ProbeTarget, record *model.AlertRecord) bool {
+	return record.EscalationLevel == 0 && !record.Notified && s.hasEscalationPolicy(ctx, target)
+}
+
+// shouldRepeatNotify 检查未恢复告警是否需要再次发送通知
+// 目标未配置重复通知间隔，或距离上次通知已超过该间隔时返回 true
+func (s *AlertService) shouldRepeatNotify(target *model.ProbeTarget, record *model.AlertRecord, now time.Time) bool {
+	if target.RepeatIntervalSeconds <= 0 || record.LastNotifiedAt == nil {
+		return true
+	}
+	interval := time.Duration(target.RepeatIntervalSeconds) * time.Second
+	return now.Sub(*record.LastNotifiedAt) >= interval
+}
+
+// detailURL 生成目标详情页链接，未配置对外访问地址时返回空
+func (s *AlertService) detailURL(targetID uint64) string {
+	if s.externalURL == "" {
+		return ""
 	}
-	s.targetRepo.UpdateStatus(ctx, event.TargetID, status, event.Result.Latency.Milliseconds(), event.Result.Message)
+	return fmt.Sprintf("%s/services/%d", s.externalURL, targetID)
 }
 
-// isSilenced 检查是否在静默期
-func (s *AlertService) isSilenced(targetID uint64) bool {
-	if v, ok := s.silenceMap.Load(targetID); ok {
-		silenceUntil := v.(time.Time)
-		if time.Now().Before(silenceUntil) {
+// targetEndpoint 从探测配置中提取目标地址，用于通知展示
+func targetEndpoint(config map[string]any) string {
+	if url, ok := config["url"].(string); ok && url != "" {
+		return url
+	}
+	if host, ok := config["host"].(string); ok && host != "" {
+		if port, ok := config["port"].(float64); ok && port > 0 {
+			return fmt.Sprintf("%s:%d", host, int(port))
+		}
+		return host
+	}
+	for _, key := range []string{"hosts", "brokers", "sentinel_addrs", "cluster_addrs"} {
+		if addrs, ok := config[key].(string); ok && addrs != "" {
+			return addrs
+		}
+	}
+	return ""
+}
+
+// isSilenced 检查目标是否匹配生效中的静默规则
+func (s *AlertService) isSilenced(ctx context.Context, target *model.ProbeTarget) bool {
+	silences, err := s.silences.get(ctx)
+	if err != nil {
+		logger.Error("查询静默规则失败", zap.Error(err))
+		return false
+	}
+	now := time.Now()
+	for _, silence := range silences {
+		if silence.State(now) == model.SilenceStateActive && silence.Matches(target) {
+			logger.Debug("目标匹配静默规则",
+				zap.Uint64("target_id", target.ID),
+				zap.Uint64("silence_id", silence.ID),
+			)
 			return true
 		}
-		s.silenceMap.Delete(targetID)
 	}
 	return false
 }
 
-// SilenceAlert 静默告警
-func (s *AlertService) SilenceAlert(targetID uint64, duration time.Duration) {
-	silenceUntil := time.Now().Add(duration)
-	s.silenceMap.Store(targetID, silenceUntil)
+// SilenceAlert 静默目标的告警
+func (s *AlertService) SilenceAlert(ctx context.Context, targetID uint64, duration time.Duration, createdBy, comment string) (*model.Silence, error) {
+	now := time.Now()
+	silence := &model.Silence{
+		TargetMatcher: model.TargetMatcher{TargetID: targetID},
+		StartsAt:      now,
+		EndsAt:        now.Add(duration),
+		CreatedBy:     createdBy,
+		Comment:       comment,
+	}
+	if err := s.CreateSilence(ctx, silence); err != nil {
+		return nil, err
+	}
+	return silence, nil
+}
+
+// CreateSilence 创建静默规则
+func (s *AlertService) CreateSilence(ctx context.Context, silence *model.Silence) error {
+	if err := silence.Validate(); err != nil {
+		return err
+	}
+	if err := s.silenceRepo.Create(ctx, silence); err != nil {
+		return fmt.Errorf("创建静默规则失败: %w", err)
+	}
+	s.silences.invalidate()
 	logger.Info("告警已静默",
-		zap.Uint64("target_id", targetID),
-		zap.Duration("duration", duration),
+		zap.Uint64("silence_id", silence.ID),
+		zap.Uint64("target_id", silence.TargetID),
+		zap.String("group", silence.Group),
+		zap.String("target_type", silence.TargetType),
+		zap.String("name_pattern", silence.NamePattern),
+		zap.Time("ends_at", silence.EndsAt),
+		zap.String("created_by", silence.CreatedBy),
+	)
+	return nil
+}
+
+// ListSilences 获取静默规则列表
+func (s *AlertService) ListSilences(ctx context.Context, query repository.SilenceQuery) ([]*model.SilenceView, int64, error) {
+	silences, total, err := s.silenceRepo.List(ctx, query)
+	if err != nil {
+		return nil, 0, err
+	}
+	now := time.Now()
+	views := make([]*model.SilenceView, 0, len(silences))
+	for _, silence := range silences {
+		views = append(views, &model.SilenceView{Silence: silence, State: silence.State(now)})
+	}
+	return views, total, nil
+}
+
+// ExpireSilence 立即结束静默规则
+func (s *AlertService) ExpireSilence(ctx context.Context, id uint64) (*model.Silence, error) {
+	silence, err := s.silenceRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if silence.State(now) == model.SilenceStateExpired {
+		return silence, nil
+	}
+	// 未开始的静默直接结束，开始时间一并调整以保证时间区间有效
+	if silence.StartsAt.After(now) {
+		silence.StartsAt = now
+	}
+	silence.EndsAt = now
+	if err := s.silenceRepo.Update(ctx, silence); err != nil {
+		return nil, fmt.Errorf("结束静默规则失败: %w", err)
+	}
+	s.silences.invalidate()
+	logger.Info("静默规则已结束", zap.Uint64("silence_id", id))
+	return silence, nil
+}
+
+// AcknowledgeAlert 确认告警，确认后不再重复发送通知，恢复通知仍会发送
+func (s *AlertService) AcknowledgeAlert(ctx context.Context, id uint64, by, comment string) (*model.AlertRecord, error) {
+	record, err := s.alertRepo.GetRecordByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	switch record.Status {
+	case model.AlertStatusResolved:
+		return nil, ErrAlertResolved
+	case model.AlertStatusAcknowledged:
+		return nil, ErrAlertAcknowledged
+	}
+
+	acked, err := s.alertRepo.Acknowledge(ctx, id, by, time.Now(), comment)
+	if err != nil {
+		return nil, fmt.Errorf("确认告警失败: %w", err)
+	}
+	if !acked {
+		// 查询之后状态已被其他请求或告警恢复修改
+		return nil, ErrAlertNotFiring
+	}
+	s.addActivity(ctx, id, model.AlertActivityAcknowledged, by, comment)
+	logger.Info("告警已确认",
+		zap.Uint64("alert_id", id),
+		zap.Uint64("target_id", record.TargetID),
+		zap.String("acknowledged_by", by),
 	)
+
+	return s.alertRepo.GetRecordByID(ctx, id)
+}
+
+// AssignAlert 指派告警处理人，assignee 为空表示取消指派
+func (s *AlertService) AssignAlert(ctx context.Context, id uint64, by, assignee string) (*model.AlertRecord, error) {
+	record, err := s.alertRepo.GetRecordByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if record.Assignee == assignee {
+		return record, nil
+	}
+
+	if err := s.alertRepo.Assign(ctx, id, assignee); err != nil {
+		return nil, fmt.Errorf("指派告警失败: %w", err)
+	}
+	content := "指派给 " + assignee
+	if assignee == "" {
+		content = "取消指派"
+	}
+	s.addActivity(ctx, id, model.AlertActivityAssigned, by, content)
+	logger.Info("告警已指派",
+		zap.Uint64("alert_id", id),
+		zap.String("assignee", assignee),
+		zap.String("assigned_by", by),
+	)
+
+	record.Assignee = assignee
+	return record, nil
+}
+
+// AddComment 添加告警评论
+func (s *AlertService) AddComment(ctx context.Context, id uint64, by, content string) (*model.AlertActivity, error) {
+	if _, err := s.alertRepo.GetRecordByID(ctx, id); err != nil {
+		return nil, err
+	}
+	activity := &model.AlertActivity{
+		AlertRecordID: id,
+		Type:          model.AlertActivityComment,
+		Actor:         by,
+		Content:       content,
+	}
+	if err := s.alertRepo.CreateActivity(ctx, activity); err != nil {
+		return nil, fmt.Errorf("添加评论失败: %w", err)
+	}
+	return activity, nil
+}
+
+// ListActivities 获取告警的活动时间线
+func (s *AlertService) ListActivities(ctx context.Context, id uint64) ([]*model.AlertActivity, error) {
+	if _, err := s.alertRepo.GetRecordByID(ctx, id); err != nil {
+		return nil, err
+	}
+	return s.alertRepo.ListActivities(ctx, id)
+}
+
+// addActivity 记录告警活动，失败时只记录日志
+func (s *AlertService) addActivity(ctx context.Context, alertRecordID uint64, activityType model.AlertActivityType, actor, content string) {
+	activity := &model.AlertActivity{
+		AlertRecordID: alertRecordID,
+		Type:          activityType,
+		Actor:         actor,
+		Content:       truncate(content, 1024),
+	}
+	if err := s.alertRepo.CreateActivity(ctx, activity); err != nil {
+		logger.Error("记录告警活动失败",
+			zap.Uint64("alert_id", alertRecordID),
+			zap.String("type", string(activityType)),
+			zap.Error(err),
+		)
+	}
 }
 
 // TriggerAlert 手动触发告警（用于测试）
@@ -321,7 +1036,9 @@ func (s *AlertService) GetRecordByID(ctx context.Context, id uint64) (*model.Ale
 }
 
 // sendToAllChannels 发送告警到目标配置的通知渠道
-func (s *AlertService) sendToAllChannels(ctx context.Context, alert *model.Alert) error {
+// 目标使用升级策略时，发送到前 escalationLevel 个升级步骤的渠道。
+// 返回 true 表示没有渠道已实际发送、通知已排队等待发送（此时不应视为已通知）
+func (s *AlertService) sendToAllChannels(ctx context.Context, alert *model.Alert, escalationLevel int) (bool, error) {
 	var alerterErr error
 	var hasAnyChannel bool // 标记是否有任何可用的通知渠道
 	var successCount int   // 成功发送的数量
@@ -342,24 +1059,19 @@ func (s *AlertService) sendToAllChannels(ctx context.Context, alert *model.Alert
 	if s.notifierRepo == nil {
 		// 如果没有通知渠道仓库，只依赖 alerter 的结果
 		if hasAnyChannel {
-			return alerterErr
+			return false, alerterErr
 		}
-		return nil
+		return false, nil
 	}
 
 	// 获取目标信息，检查其配置的通知渠道
 	target, err := s.targetRepo.GetByID(ctx, alert.TargetID)
 	if err != nil {
-		return fmt.Errorf("获取目标信息失败: %w", err)
+		return false, fmt.Errorf("获取目标信息失败: %w", err)
 	}
 
-	// 解析目标配置的通知渠道ID列表
-	var notifyChannelIDs []uint64
-	if target.NotifyChannelIDs != nil && len(target.NotifyChannelIDs) > 0 {
-		if err := json.Unmarshal(target.NotifyChannelIDs, &notifyChannelIDs); err != nil {
-			logger.Error("解析通知渠道ID失败", zap.Error(err))
-		}
-	}
+	// 解析目标配置的通知渠道ID列表（使用升级策略时为已到达步骤的渠道）
+	notifyChannelIDs := s.targetChannelIDs(ctx, target, alert.Severity, escalationLevel)
 
 	// 如果目标没有配置通知渠道
 	if len(notifyChannelIDs) == 0 {
@@ -368,27 +1080,16 @@ func (s *AlertService) sendToAllChannels(ctx context.Context, alert *model.Alert
 		)
 		// 如果之前配置文件告警器发送失败，返回该错误
 		if hasAnyChannel {
-			return alerterErr
+			return false, alerterErr
 		}
 		// 如果没有任何通知方式，返回 nil（避免误报）
-		return nil
+		return false, nil
 	}
 
-	// 获取所有启用的通知渠道
-	allChannels, err := s.notifierRepo.ListEnabled(ctx)
+	// 筛选出目标配置的已启用渠道
+	targetChannels, err := s.enabledChannels(ctx, notifyChannelIDs)
 	if err != nil {
-		return fmt.Errorf("获取通知渠道失败: %w", err)
-	}
-
-	// 筛选出目标配置的渠道
-	var targetChannels []*model.NotifyChannel
-	for _, channel := range allChannels {
-		for _, id := range notifyChannelIDs {
-			if channel.ID == id {
-				targetChannels = append(targetChannels, channel)
-				break
-			}
-		}
+		return false, err
 	}
 
 	if len(targetChannels) == 0 {
@@ -398,29 +1099,16 @@ func (s *AlertService) sendToAllChannels(ctx context.Context, alert *model.Alert
 		)
 		// 如果配置了通知渠道但都不可用，且 alerter 也失败了
 		if alerterErr != nil {
-			return alerterErr
+			return false, alerterErr
 		}
 		// 如果配置了通知渠道但都不可用，返回错误
-		return fmt.Errorf("目标配置了 %d 个通知渠道，但都不可用", len(notifyChannelIDs))
+		return false, fmt.Errorf("目标配置了 %d 个通知渠道，但都不可用", len(notifyChannelIDs))
 	}
 
 	hasAnyChannel = true
-	var lastErr error
-	for _, channel := range targetChannels {
-		if err := s.sendToChannel(ctx, channel, alert); err != nil {
-			logger.Error("发送通知失败",
-				zap.String("channel", channel.Name),
-				zap.Error(err),
-			)
-			lastErr = err
-		} else {
-			successCount++
-			logger.Debug("通知发送成功",
-				zap.String("channel", channel.Name),
-				zap.Uint64("target_id", alert.TargetID),
-			)
-		}
-	}
+	// 通过发件箱投递，首次发送失败的渠道会在后台重试
+	sent, queued, lastErr := s.deliver(ctx, targetChannels, alert)
+	successCount += sent
 
 	// 如果至少有一个渠道发送成功，返回成功
 	if successCount > 0 {
@@ -428,140 +1116,182 @@ func (s *AlertService) sendToAllChannels(ctx context.Context, alert *model.Alert
 			zap.Int("success_count", successCount),
 			zap.Uint64("target_id", alert.TargetID),
 		)
-		return nil
+		return false, nil
+	}
+
+	// 没有渠道已发送，但有渠道已受理、稍后发送
+	if queued > 0 {
+		return true, nil
 	}
 
 	// 所有渠道都失败了，返回最后一个错误
 	if lastErr != nil {
-		return lastErr
+		return false, lastErr
 	}
 	if alerterErr != nil {
-		return alerterErr
+		return false, alerterErr
 	}
-	return fmt.Errorf("所有通知渠道发送失败")
+	return false, fmt.Errorf("所有通知渠道发送失败")
 }
 
-// sendToChannel 发送告警到指定通知渠道
-func (s *AlertService) sendToChannel(ctx context.Context, channel *model.NotifyChannel, alert *model.Alert) error {
-	switch channel.Type {
-	case model.NotifyChannelTypeWeCom:
-		return s.sendToWeCom(ctx, channel, alert)
-	default:
-		return fmt.Errorf("不支持的通知渠道类型: %s", channel.Type)
+// deliver 向渠道投递告警通知，返回已发送与已排队的渠道数
+// 启用告警分组时告警先进入各渠道的分组缓冲，由 processGroups 合并后发送，此时全部渠道视为已排队，
+// 分组通知发送成功后再回写各告警记录的通知状态
+func (s *AlertService) deliver(ctx context.Context, channels []*model.NotifyChannel, alert *model.Alert) (int, int, error) {
+	if s.grouper == nil {
+		return s.notifications.Deliver(ctx, channels, alert)
+	}
+	now := time.Now()
+	for _, channel := range channels {
+		s.grouper.add(channel, alert, now)
 	}
+	return 0, len(channels), nil
 }
 
-// sendToWeCom 发送到企业微信
-func (s *AlertService) sendToWeCom(ctx context.Context, channel *model.NotifyChannel, alert *model.Alert) error {
-	content := s.formatAlertMessage(channel, alert)
-
-	msg := struct {
-		MsgType string `json:"msgtype"`
-		Text    struct {
-			Content       string   `json:"content"`
-			MentionedList []string `json:"mentioned_list,omitempty"`
-		} `json:"text"`
-	}{
-		MsgType: "text",
-	}
-	msg.Text.Content = content
-	// 根据配置决定是否@所有人
-	if channel.MentionAll {
-		msg.Text.MentionedList = []string{"@all"}
+// notificationQueued 告警是否有已受理但尚未送达的通知（等待分组发送、等待重试或因限流排队）
+func (s *AlertService) notificationQueued(ctx context.Context, record *model.AlertRecord) bool {
+	if s.grouper != nil && s.grouper.pending(record.ID) {
+		return true
 	}
+	return s.notifications.Queued(ctx, record.ID)
+}
 
-	data, err := json.Marshal(msg)
-	if err != nil {
-		return fmt.Errorf("序列化消息失败: %w", err)
-	}
+// processGroups 定时发送到期的告警分组
+func (s *AlertService) processGroups(ctx context.Context) {
+	ticker := time.NewTicker(groupCheckInterval)
+	defer ticker.Stop()
 
-	client := &http.Client{Timeout: 10 * time.Second}
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, channel.WebhookURL, bytes.NewReader(data))
-	if err != nil {
-		return fmt.Errorf("创建请求失败: %w", err)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.stopChan:
+			return
+		case <-ticker.C:
+			s.flushGroups(ctx, false)
+		}
 	}
-	req.Header.Set("Content-Type", "application/json")
+}
 
-	resp, err := client.Do(req)
-	if err != nil {
-		return fmt.Errorf("发送请求失败: %w", err)
+// flushGroups 合并并发送到期的告警分组，all 为 true 时发送所有等待中的分组
+// 发送成功时回写其中告警的通知状态；首次发送失败或排队的通知由通知投递服务在发送成功后回写
+func (s *AlertService) flushGroups(ctx context.Context, all bool) {
+	for _, flush := range s.grouper.due(time.Now(), all) {
+		alert := mergeAlerts(flush.alerts)
+		sent, _, err := s.notifications.Deliver(ctx, []*model.NotifyChannel{flush.channel}, alert)
+		if sent > 0 {
+			s.markGroupNotified(ctx, flush)
+		}
+		if err != nil {
+			logger.Error("发送分组告警通知失败",
+				zap.String("channel", flush.channel.Name),
+				zap.Int("alerts", len(flush.alerts)),
+				zap.Error(err),
+			)
+			continue
+		}
+		logger.Info("分组告警通知发送成功",
+			zap.String("channel", flush.channel.Name),
+			zap.Int("alerts", len(flush.alerts)),
+		)
 	}
-	defer resp.Body.Close()
+}
 
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("API 返回错误: HTTP %d", resp.StatusCode)
+// markGroupNotified 分组通知发送成功后回写其中告警记录的通知状态
+func (s *AlertService) markGroupNotified(ctx context.Context, flush groupFlush) {
+	now := time.Now()
+	for _, alert := range flush.alerts {
+		if alert.Status != model.AlertStatusFiring || alert.ID == 0 {
+			continue
+		}
+		if err := s.alertRepo.MarkNotified(ctx, alert.ID, now); err != nil {
+			logger.Error("更新告警通知状态失败", zap.Uint64("alert_id", alert.ID), zap.Error(err))
+			continue
+		}
+		s.addActivity(ctx, alert.ID, model.AlertActivityNotified, "", "已通过 "+flush.channel.Name+" 发送分组告警通知")
 	}
+}
 
-	var result struct {
-		ErrCode int    `json:"errcode"`
-		ErrMsg  string `json:"errmsg"`
-	}
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return fmt.Errorf("解析响应失败: %w", err)
+// targetChannelIDs 获取目标的通知渠道ID列表，优先级从高到低：
+//  1. 目标使用可用的升级策略且 escalationLevel 大于 0 时返回前 escalationLevel 个步骤的渠道，不参与路由
+//  2. 按告警级别命中路由规则时使用规则的渠道，替换目标自身配置的通知渠道
+//  3. 未命中任何规则（或升级策略不可用）时使用目标的通知渠道ID列表
+//
+// 升级级别为 0 的告警若已通知过，说明告警是在升级策略不可用或目标关联策略之前按 2、3 发送的，
+// 其恢复与根因通知同样发送给这些渠道；尚未通知过的告警由调用方跳过（见 awaitingEscalation）
+func (s *AlertService) targetChannelIDs(ctx context.Context, target *model.ProbeTarget, severity model.Severity, escalationLevel int) []uint64 {
+	if target.EscalationPolicyID > 0 && escalationLevel > 0 {
+		steps, err := s.escalationSteps(ctx, target.EscalationPolicyID)
+		if err == nil {
+			return model.StepChannelIDs(steps, 0, escalationLevel)
+		}
+		logger.Warn("获取升级策略失败，使用目标的通知渠道",
+			zap.Uint64("target_id", target.ID),
+			zap.Uint64("escalation_policy_id", target.EscalationPolicyID),
+			zap.Error(err),
+		)
 	}
 
-	if result.ErrCode != 0 {
-		return fmt.Errorf("企业微信 API 错误: %d - %s", result.ErrCode, result.ErrMsg)
+	if routed := s.routing.Route(ctx, target, severity); len(routed) > 0 {
+		return routed
 	}
 
-	return nil
+	var notifyChannelIDs []uint64
+	if len(target.NotifyChannelIDs) > 0 {
+		if err := json.Unmarshal(target.NotifyChannelIDs, &notifyChannelIDs); err != nil {
+			logger.Error("解析通知渠道ID失败", zap.Error(err))
+		}
+	}
+	return notifyChannelIDs
 }
 
-// formatAlertMessage 格式化告警消息（使用默认模板）
-func (s *AlertService) formatAlertMessage(channel *model.NotifyChannel, alert *model.Alert) string {
-	// 使用默认模板
-	if alert.Status == model.AlertStatusFiring {
-		return fmt.Sprintf(`🚨 告警通知 [%s]
-
-目标：%s
-类型：%s
-原因：%s
-时间：%s`,
-			channel.Name,
-			alert.TargetName,
-			alert.TargetType,
-			alert.Message,
-			alert.FiredAt.Format("2006-01-02 15:04:05"),
-		)
+// alertSeverity 计算告警级别：默认使用目标的告警级别，结果为降级时取其与警告级别中较低者；
+// 命中指标阈值规则时规则的级别优先于目标的级别，取其与探测失败级别中较高者。
+// 目标未开启降级告警时，降级结果只会因指标规则告警，直接使用规则的级别
+func alertSeverity(target *model.ProbeTarget, result *prober.ProbeResult) model.Severity {
+	severity := target.GetSeverity()
+	if result == nil {
+		return severity
 	}
-
-	content := fmt.Sprintf(`✅ 恢复通知 [%s]
-
-目标：%s
-类型：%s
-时间：%s`,
-		channel.Name,
-		alert.TargetName,
-		alert.TargetType,
-		alert.FiredAt.Format("2006-01-02 15:04:05"),
-	)
-
-	if alert.ResolvedAt != nil {
-		duration := alert.ResolvedAt.Sub(alert.FiredAt)
-		content += fmt.Sprintf("\n恢复时间：%s", alert.ResolvedAt.Format("2006-01-02 15:04:05"))
-		content += fmt.Sprintf("\n故障时长：%s", formatDuration(duration))
+	if result.Outcome() == prober.OutcomeDegraded {
+		if result.RuleSeverity != "" && !target.AlertOnDegraded {
+			return model.Severity(result.RuleSeverity)
+		}
+		severity = severity.Lower(model.SeverityWarning)
+	}
+	if result.RuleSeverity != "" {
+		severity = severity.Higher(model.Severity(result.RuleSeverity))
 	}
+	return severity
+}
 
-	return content
+// targetStatus 根据探测结论获取目标状态
+func targetStatus(result *prober.ProbeResult) string {
+	switch result.Outcome() {
+	case prober.OutcomeDown:
+		return model.TargetStatusUnhealthy
+	case prober.OutcomeDegraded:
+		return model.TargetStatusDegraded
+	default:
+		return model.TargetStatusHealthy
+	}
 }
 
-// formatDuration 格式化时长
-func formatDuration(d time.Duration) string {
-	if d < time.Minute {
-		return fmt.Sprintf("%d秒", int(d.Seconds()))
+// enabledChannels 获取指定 ID 中已启用的通知渠道
+func (s *AlertService) enabledChannels(ctx context.Context, ids []uint64) ([]*model.NotifyChannel, error) {
+	allChannels, err := s.notifierRepo.ListEnabled(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("获取通知渠道失败: %w", err)
 	}
-	if d < time.Hour {
-		minutes := int(d.Minutes())
-		seconds := int(d.Seconds()) % 60
-		if seconds > 0 {
-			return fmt.Sprintf("%d分%d秒", minutes, seconds)
+
+	var channels []*model.NotifyChannel
+	for _, channel := range allChannels {
+		for _, id := range ids {
+			if channel.ID == id {
+				channels = append(channels, channel)
+				break
+			}
 		}
-		return fmt.Sprintf("%d分钟", minutes)
-	}
-	hours := int(d.Hours())
-	minutes := int(d.Minutes()) % 60
-	if minutes > 0 {
-		return fmt.Sprintf("%d小时%d分", hours, minutes)
 	}
-	return fmt.Sprintf("%d小时", hours)
+	return channels, nil
 }
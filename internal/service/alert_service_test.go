@@ -0,0 +1,544 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/thingsboard-rxprobe/internal/model"
+	"github.com/thingsboard-rxprobe/internal/notifier"
+	"github.com/thingsboard-rxprobe/internal/prober"
+	"github.com/thingsboard-rxprobe/internal/repository"
+	"github.com/thingsboard-rxprobe/internal/scheduler"
+	"gorm.io/datatypes"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+// newTestDB 创建迁移好全部数据表的内存数据库
+func newTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{
+		Logger: gormlogger.Default.LogMode(gormlogger.Silent),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = db.AutoMigrate(
+		&model.ProbeTarget{},
+		&model.AlertRecord{},
+		&model.NotifyChannel{},
+		&model.NotificationDelivery{},
+		&model.NotificationAttempt{},
+		&model.AlertActivity{},
+		&model.EscalationPolicy{},
+		&model.RoutingRule{},
+		&model.Silence{},
+		&model.MaintenanceWindow{},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB, _ := db.DB()
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+	return db
+}
+
+func TestDegradedParentDoesNotSuppressChildren(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+	s := &AlertService{
+		alertRepo:  repository.NewAlertRepository(db),
+		targetRepo: repository.NewTargetRepository(db),
+	}
+
+	// 警告级别的指标规则命中后结果为降级，目标状态为降级而不是故障
+	degraded := &prober.ProbeResult{Success: true, Warnings: []string{"指标超过阈值：replication_lag_seconds > 30（当前 60）"}}
+	if status := targetStatus(degraded); status != model.TargetStatusDegraded {
+		t.Fatalf("targetStatus = %q, want %q", status, model.TargetStatusDegraded)
+	}
+
+	parent := &model.ProbeTarget{Name: "postgres", Type: "postgresql", Enabled: true, Status: targetStatus(degraded)}
+	if err := s.targetRepo.Create(ctx, parent); err != nil {
+		t.Fatal(err)
+	}
+	child := &model.ProbeTarget{Name: "tb-node", Type: "http", Enabled: true, ParentIDs: datatypes.JSON(fmt.Sprintf("[%d]", parent.ID))}
+	if err := s.targetRepo.Create(ctx, child); err != nil {
+		t.Fatal(err)
+	}
+	if got := s.unhealthyParent(ctx, child); got != nil {
+		t.Fatalf("降级的上游目标不应抑制下游告警: %+v", got)
+	}
+
+	// 警告规则始终告警，降级的上游目标存在未恢复的告警时同样不抑制下游告警
+	record := &model.AlertRecord{TargetID: parent.ID, TargetName: parent.Name, Status: model.AlertStatusFiring, Severity: model.SeverityWarning, FiredAt: time.Now()}
+	if err := s.alertRepo.CreateRecord(ctx, record); err != nil {
+		t.Fatal(err)
+	}
+	if got := s.unhealthyParent(ctx, child); got != nil {
+		t.Fatalf("存在警告告警的降级上游目标不应抑制下游告警: %+v", got)
+	}
+
+	// 上游目标已恢复为健康但告警尚未达到恢复阈值时仍然抑制
+	if err := s.targetRepo.UpdateStatus(ctx, parent.ID, model.TargetStatusHealthy, 0, "ok"); err != nil {
+		t.Fatal(err)
+	}
+	if got := s.unhealthyParent(ctx, child); got == nil || got.ID != parent.ID {
+		t.Fatalf("告警未恢复的上游目标应抑制下游告警: %+v", got)
+	}
+
+	if err := s.targetRepo.UpdateStatus(ctx, parent.ID, model.TargetStatusUnhealthy, 0, "down"); err != nil {
+		t.Fatal(err)
+	}
+	if got := s.unhealthyParent(ctx, child); got == nil || got.ID != parent.ID {
+		t.Fatalf("故障的上游目标应抑制下游告警: %+v", got)
+	}
+}
+
+func TestAlertSeverity(t *testing.T) {
+	degraded := func(ruleSeverity string) *prober.ProbeResult {
+		return &prober.ProbeResult{Success: true, Warnings: []string{"slow"}, RuleSeverity: ruleSeverity}
+	}
+	tests := []struct {
+		name            string
+		target          model.Severity
+		alertOnDegraded bool
+		result          *prober.ProbeResult
+		want            model.Severity
+	}{
+		{"无探测结果时使用目标级别", model.SeverityWarning, false, nil, model.SeverityWarning},
+		{"未设置目标级别时为严重", "", false, &prober.ProbeResult{Success: false}, model.SeverityCritical},
+		{"降级结果最高为警告", model.SeverityCritical, true, degraded(""), model.SeverityWarning},
+		{"降级结果不高于目标级别", model.SeverityInfo, true, degraded(""), model.SeverityInfo},
+		{"降级结果的规则级别高于目标级别", model.SeverityInfo, true, degraded(string(model.SeverityWarning)), model.SeverityWarning},
+		{"开启降级告警时提示规则不降低级别", model.SeverityWarning, true, degraded(string(model.SeverityInfo)), model.SeverityWarning},
+		{"未开启降级告警时按警告规则的级别", model.SeverityCritical, false, degraded(string(model.SeverityWarning)), model.SeverityWarning},
+		{"未开启降级告警时按提示规则的级别", model.SeverityWarning, false, degraded(string(model.SeverityInfo)), model.SeverityInfo},
+		{"失败结果的规则级别高于目标级别", model.SeverityWarning, false, &prober.ProbeResult{Success: false, RuleSeverity: string(model.SeverityCritical)}, model.SeverityCritical},
+		{"失败结果的提示规则不降低级别", model.SeverityCritical, false, &prober.ProbeResult{Success: false, RuleSeverity: string(model.SeverityInfo)}, model.SeverityCritical},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target := &model.ProbeTarget{Severity: tt.target, AlertOnDegraded: tt.alertOnDegraded}
+			if got := alertSeverity(target, tt.result); got != tt.want {
+				t.Fatalf("alertSeverity = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// newTestAlertService 创建使用内存数据库、未启用分组的告警服务
+func newTestAlertService(t *testing.T) (*AlertService, *NotificationService) {
+	t.Helper()
+	db := newTestDB(t)
+	alertRepo := repository.NewAlertRepository(db)
+	notifierRepo := repository.NewNotifierRepository(db)
+	notifications := NewNotificationService(
+		repository.NewDeliveryRepository(db),
+		notifierRepo,
+		alertRepo,
+		notifier.NewRegistry(),
+		RetryPolicy{MaxAttempts: 1},
+		RateLimitConfig{},
+	)
+	s := NewAlertService(
+		alertRepo,
+		repository.NewTargetRepository(db),
+		repository.NewResultRepository(db),
+		notifierRepo,
+		repository.NewSilenceRepository(db),
+		repository.NewEscalationRepository(db),
+		NewMaintenanceService(repository.NewMaintenanceRepository(db)),
+		NewRoutingService(repository.NewRoutingRepository(db)),
+		notifications,
+		nil,
+		nil,
+		"",
+		GroupingConfig{},
+	)
+	return s, notifications
+}
+
+// newEscalationTarget 创建关联升级策略的目标，channelIDs 为目标自身配置的通知渠道
+func newEscalationTarget(t *testing.T, s *AlertService, steps string, channelIDs ...uint64) *model.ProbeTarget {
+	t.Helper()
+	ctx := context.Background()
+	policy := &model.EscalationPolicy{Name: "oncall", Steps: datatypes.JSON(steps)}
+	if err := s.escalationRepo.Create(ctx, policy); err != nil {
+		t.Fatal(err)
+	}
+	ids, _ := json.Marshal(channelIDs)
+	target := &model.ProbeTarget{
+		Name:               "tb-node",
+		Type:               "http",
+		Enabled:            true,
+		NotifyChannelIDs:   datatypes.JSON(ids),
+		EscalationPolicyID: policy.ID,
+	}
+	if err := s.targetRepo.Create(ctx, target); err != nil {
+		t.Fatal(err)
+	}
+	return target
+}
+
+// probeEvent 构造指定状态的告警事件
+func probeEvent(target *model.ProbeTarget, status model.AlertStatus, checkedAt time.Time) *scheduler.AlertEvent {
+	return &scheduler.AlertEvent{
+		Target: target,
+		Result: &prober.ProbeResult{Success: status == model.AlertStatusResolved, Message: string(status), CheckedAt: checkedAt},
+		Status: status,
+	}
+}
+
+func TestEscalationLoop(t *testing.T) {
+	s, notifications := newTestAlertService(t)
+	ctx := context.Background()
+	fake := &fakeNotifier{}
+	first := newFakeChannel(t, notifications, fake, "")
+	second := newFakeChannel(t, notifications, fake, "")
+	steps := fmt.Sprintf(`[{"delay_minutes": 0, "channel_ids": [%d]}, {"delay_minutes": 10, "channel_ids": [%d]}]`, first.ID, second.ID)
+	target := newEscalationTarget(t, s, steps, second.ID)
+
+	// 第一步无需等待，告警触发时立即通知第一步的渠道
+	firedAt := time.Now().Add(-11 * time.Minute)
+	s.handleAlert(ctx, probeEvent(target, model.AlertStatusFiring, firedAt))
+	if got := fake.sentChannels(); !reflect.DeepEqual(got, []uint64{first.ID}) {
+		t.Fatalf("告警触发时应只通知第一步的渠道: %v", got)
+	}
+	record, err := s.alertRepo.GetLastFiringRecord(ctx, target.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if record.EscalationLevel != 1 || !record.Notified {
+		t.Fatalf("告警应到达第一步并标记为已通知: level=%d notified=%v", record.EscalationLevel, record.Notified)
+	}
+
+	// 超过第二步的延迟后由定时检查升级，只通知新到达步骤的渠道
+	s.checkEscalations(ctx)
+	if got := fake.sentChannels(); !reflect.DeepEqual(got, []uint64{first.ID, second.ID}) {
+		t.Fatalf("升级后应只通知新到达步骤的渠道: %v", got)
+	}
+	s.checkEscalations(ctx)
+	if got := fake.sentChannels(); len(got) != 2 {
+		t.Fatalf("已到达最后一步后不应重复升级: %v", got)
+	}
+	if record, _ = s.alertRepo.GetRecordByID(ctx, record.ID); record.EscalationLevel != 2 {
+		t.Fatalf("升级级别 = %d, want 2", record.EscalationLevel)
+	}
+
+	// 恢复通知发送到所有已到达步骤的渠道
+	s.handleAlert(ctx, probeEvent(target, model.AlertStatusResolved, time.Now()))
+	sent, channels := fake.sentAlerts(), fake.sentChannels()
+	if len(sent) != 4 || !reflect.DeepEqual(channels[2:], []uint64{first.ID, second.ID}) {
+		t.Fatalf("恢复通知应发送到所有已到达步骤的渠道: %v", channels)
+	}
+	if sent[2].Status != model.AlertStatusResolved || sent[3].Status != model.AlertStatusResolved {
+		t.Fatal("最后两条通知应为恢复通知")
+	}
+}
+
+func TestEscalationResolvedAtLevelZero(t *testing.T) {
+	s, notifications := newTestAlertService(t)
+	ctx := context.Background()
+	fake := &fakeNotifier{}
+	stepChannel := newFakeChannel(t, notifications, fake, "")
+	targetChannel := newFakeChannel(t, notifications, fake, "")
+	steps := fmt.Sprintf(`[{"delay_minutes": 5, "channel_ids": [%d]}]`, stepChannel.ID)
+
+	// 告警在到达第一个升级步骤前恢复：没有渠道收到过告警，也不发送恢复通知
+	target := newEscalationTarget(t, s, steps, targetChannel.ID)
+	s.handleAlert(ctx, probeEvent(target, model.AlertStatusFiring, time.Now()))
+	s.checkEscalations(ctx)
+	if got := fake.sentChannels(); len(got) != 0 {
+		t.Fatalf("未到达第一个升级步骤时不应发送通知: %v", got)
+	}
+	s.handleAlert(ctx, probeEvent(target, model.AlertStatusResolved, time.Now()))
+	if got := fake.sentChannels(); len(got) != 0 {
+		t.Fatalf("未通知过的告警不应发送恢复通知: %v", got)
+	}
+
+	// 告警在目标关联升级策略之前已按目标的渠道通知过：恢复通知发送到同样的渠道，而不是被丢弃
+	other := newEscalationTarget(t, s, steps, targetChannel.ID)
+	policyID := other.EscalationPolicyID
+	other.EscalationPolicyID = 0
+	if err := s.targetRepo.Update(ctx, other); err != nil {
+		t.Fatal(err)
+	}
+	s.handleAlert(ctx, probeEvent(other, model.AlertStatusFiring, time.Now()))
+	if got := fake.sentChannels(); !reflect.DeepEqual(got, []uint64{targetChannel.ID}) {
+		t.Fatalf("未关联升级策略时应通知目标的渠道: %v", got)
+	}
+	other.EscalationPolicyID = policyID
+	if err := s.targetRepo.Update(ctx, other); err != nil {
+		t.Fatal(err)
+	}
+	s.handleAlert(ctx, probeEvent(other, model.AlertStatusResolved, time.Now()))
+	sent, channels := fake.sentAlerts(), fake.sentChannels()
+	if len(sent) != 2 || channels[1] != targetChannel.ID || sent[1].Status != model.AlertStatusResolved {
+		t.Fatalf("恢复通知应发送到收到告警的渠道: %v", channels)
+	}
+}
+
+func TestCheckRootCauses(t *testing.T) {
+	s, notifications := newTestAlertService(t)
+	ctx := context.Background()
+	fake := &fakeNotifier{}
+	channel := newFakeChannel(t, notifications, fake, "")
+
+	parent := &model.ProbeTarget{Name: "postgres", Type: "postgresql", Enabled: true, NotifyChannelIDs: datatypes.JSON(fmt.Sprintf("[%d]", channel.ID))}
+	if err := s.targetRepo.Create(ctx, parent); err != nil {
+		t.Fatal(err)
+	}
+	newRecord := func(targetID uint64, name string, suppressedBy uint64) *model.AlertRecord {
+		record := &model.AlertRecord{TargetID: targetID, TargetName: name, Status: model.AlertStatusFiring, Severity: model.SeverityCritical, SuppressedBy: suppressedBy, FiredAt: time.Now()}
+		if err := s.alertRepo.CreateRecord(ctx, record); err != nil {
+			t.Fatal(err)
+		}
+		return record
+	}
+	root := newRecord(parent.ID, "postgres", 0)
+	newRecord(100, "tb-node-1", parent.ID)
+	newRecord(101, "tb-node-2", parent.ID)
+	newRecord(102, "redis", 999) // 其他上游目标抑制的告警
+
+	s.checkRootCauses(ctx)
+	sent := fake.sentAlerts()
+	if len(sent) != 1 || sent[0].ID != root.ID {
+		t.Fatalf("应只为上游告警发送一条根因通知: %d", len(sent))
+	}
+	if !strings.Contains(sent[0].Message, "受影响的下游目标（2）：tb-node-1、tb-node-2") {
+		t.Fatalf("根因通知应列出所有受影响的下游目标: %s", sent[0].Message)
+	}
+
+	s.checkRootCauses(ctx)
+	if len(fake.sentAlerts()) != 1 {
+		t.Fatal("没有新的受影响下游告警时不应重复发送")
+	}
+
+	newRecord(103, "tb-node-3", parent.ID)
+	s.checkRootCauses(ctx)
+	sent = fake.sentAlerts()
+	if len(sent) != 2 || !strings.Contains(sent[1].Message, "受影响的下游目标（3）") {
+		t.Fatalf("出现新的受影响下游告警时应再次发送: %d", len(sent))
+	}
+}
+
+func TestTargetStatus(t *testing.T) {
+	tests := []struct {
+		name   string
+		result *prober.ProbeResult
+		want   string
+	}{
+		{"成功为健康", &prober.ProbeResult{Success: true}, model.TargetStatusHealthy},
+		{"成功但带有警告为降级", &prober.ProbeResult{Success: true, Warnings: []string{"响应耗时 800ms 超过慢响应阈值 500ms"}}, model.TargetStatusDegraded},
+		{"失败为故障", &prober.ProbeResult{Success: false}, model.TargetStatusUnhealthy},
+		{"失败时忽略警告", &prober.ProbeResult{Success: false, Warnings: []string{"slow"}}, model.TargetStatusUnhealthy},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := targetStatus(tt.result); got != tt.want {
+				t.Fatalf("targetStatus = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAcknowledgeAlertStopsEscalation(t *testing.T) {
+	s, notifications := newTestAlertService(t)
+	ctx := context.Background()
+	fake := &fakeNotifier{}
+	first := newFakeChannel(t, notifications, fake, "")
+	second := newFakeChannel(t, notifications, fake, "")
+	steps := fmt.Sprintf(`[{"delay_minutes": 0, "channel_ids": [%d]}, {"delay_minutes": 10, "channel_ids": [%d]}]`, first.ID, second.ID)
+	target := newEscalationTarget(t, s, steps)
+
+	s.handleAlert(ctx, probeEvent(target, model.AlertStatusFiring, time.Now().Add(-11*time.Minute)))
+	record, err := s.alertRepo.GetLastFiringRecord(ctx, target.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	acked, err := s.AcknowledgeAlert(ctx, record.ID, "alice", "处理中")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if acked.Status != model.AlertStatusAcknowledged || acked.AcknowledgedBy != "alice" {
+		t.Fatalf("确认后的记录 = %+v", acked)
+	}
+	if _, err := s.AcknowledgeAlert(ctx, record.ID, "bob", ""); !errors.Is(err, ErrAlertAcknowledged) {
+		t.Fatalf("重复确认 err = %v, want %v", err, ErrAlertAcknowledged)
+	}
+
+	// 确认后既不升级到后续步骤，也不重复通知
+	s.checkEscalations(ctx)
+	s.handleAlert(ctx, probeEvent(target, model.AlertStatusFiring, time.Now()))
+	if got := fake.sentChannels(); !reflect.DeepEqual(got, []uint64{first.ID}) {
+		t.Fatalf("确认后不应再发送告警通知: %v", got)
+	}
+	if record, _ = s.alertRepo.GetRecordByID(ctx, record.ID); record.EscalationLevel != 1 {
+		t.Fatalf("确认后升级级别 = %d, want 1", record.EscalationLevel)
+	}
+
+	// 恢复通知仍会发送，恢复后不能再确认
+	s.handleAlert(ctx, probeEvent(target, model.AlertStatusResolved, time.Now()))
+	sent := fake.sentAlerts()
+	if len(sent) != 2 || sent[1].Status != model.AlertStatusResolved {
+		t.Fatalf("确认后恢复通知仍应发送: %v", fake.sentChannels())
+	}
+	if _, err := s.AcknowledgeAlert(ctx, record.ID, "alice", ""); !errors.Is(err, ErrAlertResolved) {
+		t.Fatalf("确认已恢复的告警 err = %v, want %v", err, ErrAlertResolved)
+	}
+}
+
+func TestAssignAlert(t *testing.T) {
+	s, notifications := newTestAlertService(t)
+	ctx := context.Background()
+	record := newAlertRecord(t, notifications, 1)
+
+	if _, err := s.AssignAlert(ctx, record.ID+1, "admin", "alice"); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Fatalf("指派不存在的告警 err = %v, want %v", err, gorm.ErrRecordNotFound)
+	}
+
+	for _, assignee := range []string{"alice", "alice", "bob", ""} {
+		got, err := s.AssignAlert(ctx, record.ID, "admin", assignee)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.Assignee != assignee {
+			t.Fatalf("Assignee = %q, want %q", got.Assignee, assignee)
+		}
+	}
+	if record, _ = s.alertRepo.GetRecordByID(ctx, record.ID); record.Assignee != "" {
+		t.Fatalf("取消指派后 Assignee = %q", record.Assignee)
+	}
+
+	// 指派给同一处理人时不重复记录活动
+	activities, err := s.ListActivities(ctx, record.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var contents []string
+	for _, activity := range activities {
+		if activity.Type != model.AlertActivityAssigned || activity.Actor != "admin" {
+			t.Fatalf("活动 = %+v", activity)
+		}
+		contents = append(contents, activity.Content)
+	}
+	if want := []string{"指派给 alice", "指派给 bob", "取消指派"}; !reflect.DeepEqual(contents, want) {
+		t.Fatalf("指派活动 = %v, want %v", contents, want)
+	}
+}
+
+func TestAddComment(t *testing.T) {
+	s, notifications := newTestAlertService(t)
+	ctx := context.Background()
+	record := newAlertRecord(t, notifications, 1)
+
+	if _, err := s.AddComment(ctx, record.ID+1, "alice", "评论"); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Fatalf("评论不存在的告警 err = %v, want %v", err, gorm.ErrRecordNotFound)
+	}
+
+	want := []string{"开始排查", "已定位到网络抖动", "已恢复"}
+	for _, content := range want {
+		if _, err := s.AddComment(ctx, record.ID, "alice", content); err != nil {
+			t.Fatal(err)
+		}
+	}
+	activities, err := s.ListActivities(ctx, record.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var contents []string
+	for _, activity := range activities {
+		if activity.Type != model.AlertActivityComment || activity.Actor != "alice" {
+			t.Fatalf("活动 = %+v", activity)
+		}
+		contents = append(contents, activity.Content)
+	}
+	if !reflect.DeepEqual(contents, want) {
+		t.Fatalf("评论 = %v, want %v（应按添加顺序返回）", contents, want)
+	}
+}
+
+func TestTargetChannelIDsPrecedence(t *testing.T) {
+	s, _ := newTestAlertService(t)
+	ctx := context.Background()
+	target := newEscalationTarget(t, s, `[{"delay_minutes": 0, "channel_ids": [1]}, {"delay_minutes": 10, "channel_ids": [2]}]`, 7)
+	rule := &model.RoutingRule{
+		Name:          "严重告警",
+		TargetMatcher: model.TargetMatcher{TargetID: target.ID},
+		Severities:    datatypes.JSON(`["critical"]`),
+		ChannelIDs:    datatypes.JSON(`[5]`),
+		Enabled:       true,
+	}
+	if err := s.routing.Create(ctx, rule); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name            string
+		severity        model.Severity
+		escalationLevel int
+		want            []uint64
+	}{
+		{"已升级时使用已到达步骤的渠道，不参与路由", model.SeverityCritical, 2, []uint64{1, 2}},
+		{"未升级时命中路由规则", model.SeverityCritical, 0, []uint64{5}},
+		{"未命中路由规则时使用目标的渠道", model.SeverityWarning, 0, []uint64{7}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := s.targetChannelIDs(ctx, target, tt.severity, tt.escalationLevel); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("targetChannelIDs() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	// 升级策略被删除后回退到路由规则与目标的渠道
+	if err := s.escalationRepo.Delete(ctx, target.EscalationPolicyID); err != nil {
+		t.Fatal(err)
+	}
+	if got := s.targetChannelIDs(ctx, target, model.SeverityCritical, 1); !reflect.DeepEqual(got, []uint64{5}) {
+		t.Fatalf("升级策略不可用时 targetChannelIDs() = %v, want [5]", got)
+	}
+	if got := s.targetChannelIDs(ctx, target, model.SeverityWarning, 1); !reflect.DeepEqual(got, []uint64{7}) {
+		t.Fatalf("升级策略不可用时 targetChannelIDs() = %v, want [7]", got)
+	}
+}
+
+func TestGroupedAlertResolvedBeforeSent(t *testing.T) {
+	s, notifications := newTestAlertService(t)
+	s.grouper = newAlertGrouper(GroupingConfig{Wait: time.Minute})
+	ctx := context.Background()
+	fake := &fakeNotifier{}
+	channel := newFakeChannel(t, notifications, fake, "")
+	ids, _ := json.Marshal([]uint64{channel.ID})
+	target := &model.ProbeTarget{Name: "tb-node", Type: "http", Enabled: true, NotifyChannelIDs: datatypes.JSON(ids)}
+	if err := s.targetRepo.Create(ctx, target); err != nil {
+		t.Fatal(err)
+	}
+
+	// 告警通知等待分组发送期间恢复：告警与恢复通知均不发送
+	s.handleAlert(ctx, probeEvent(target, model.AlertStatusFiring, time.Now()))
+	s.handleAlert(ctx, probeEvent(target, model.AlertStatusResolved, time.Now()))
+	s.flushGroups(ctx, true)
+	if sent := fake.sentAlerts(); len(sent) != 0 {
+		t.Fatalf("发送前恢复的告警不应发送任何通知: %d", len(sent))
+	}
+
+	// 已通知过的告警恢复时撤回等待发送的重复通知，只发送恢复通知
+	s.handleAlert(ctx, probeEvent(target, model.AlertStatusFiring, time.Now()))
+	s.flushGroups(ctx, true)
+	s.handleAlert(ctx, probeEvent(target, model.AlertStatusFiring, time.Now()))
+	s.handleAlert(ctx, probeEvent(target, model.AlertStatusResolved, time.Now()))
+	s.flushGroups(ctx, true)
+	sent := fake.sentAlerts()
+	if len(sent) != 2 || sent[0].Status != model.AlertStatusFiring || sent[1].Status != model.AlertStatusResolved {
+		t.Fatalf("已通知过的告警恢复时应只发送恢复通知: %d 条", len(sent))
+	}
+}
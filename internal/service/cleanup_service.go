@@ -14,6 +14,7 @@ import (
 type CleanupService struct {
 	resultRepo          *repository.ResultRepository
 	alertRepo           *repository.AlertRepository
+	deliveryRepo        *repository.DeliveryRepository
 	cron                *cron.Cron
 	resultRetentionDays int
 	alertRetentionDays  int
@@ -23,6 +24,7 @@ type CleanupService struct {
 func NewCleanupService(
 	resultRepo *repository.ResultRepository,
 	alertRepo *repository.AlertRepository,
+	deliveryRepo *repository.DeliveryRepository,
 	resultRetentionDays int,
 ) *CleanupService {
 	// 告警记录默认保留 90 天
@@ -34,6 +36,7 @@ func NewCleanupService(
 	return &CleanupService{
 		resultRepo:          resultRepo,
 		alertRepo:           alertRepo,
+		deliveryRepo:        deliveryRepo,
 		cron:                cron.New(),
 		resultRetentionDays: resultRetentionDays,
 		alertRetentionDays:  alertRetentionDays,
@@ -99,8 +102,19 @@ func (s *CleanupService) cleanup() {
 		)
 	}
 
+	// 3. 清理过期的通知投递记录（与告警记录保留时长一致）
+	deliveryDeleted, err := s.deliveryRepo.DeleteOld(ctx, s.alertRetentionDays)
+	if err != nil {
+		logger.Error("清理通知投递记录失败", zap.Error(err))
+	} else {
+		logger.Info("清理通知投递记录完成",
+			zap.Int64("deleted", deliveryDeleted),
+			zap.Int("retention_days", s.alertRetentionDays),
+		)
+	}
+
 	logger.Info("数据清理完成",
-		zap.Int64("total_deleted", resultDeleted+alertDeleted),
+		zap.Int64("total_deleted", resultDeleted+alertDeleted+deliveryDeleted),
 	)
 }
 
@@ -0,0 +1,79 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/thingsboard-rxprobe/internal/model"
+	"github.com/thingsboard-rxprobe/internal/repository"
+	"github.com/thingsboard-rxprobe/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// ErrEscalationPolicyInUse 升级策略仍被目标使用
+var ErrEscalationPolicyInUse = errors.New("升级策略正在被目标使用")
+
+// EscalationService 升级策略服务
+type EscalationService struct {
+	repo       *repository.EscalationRepository
+	targetRepo *repository.TargetRepository
+}
+
+// NewEscalationService 创建升级策略服务
+func NewEscalationService(repo *repository.EscalationRepository, targetRepo *repository.TargetRepository) *EscalationService {
+	return &EscalationService{repo: repo, targetRepo: targetRepo}
+}
+
+// List 获取升级策略列表
+func (s *EscalationService) List(ctx context.Context) ([]*model.EscalationPolicy, error) {
+	return s.repo.List(ctx)
+}
+
+// Get 获取升级策略详情
+func (s *EscalationService) Get(ctx context.Context, id uint64) (*model.EscalationPolicy, error) {
+	return s.repo.GetByID(ctx, id)
+}
+
+// Create 创建升级策略
+func (s *EscalationService) Create(ctx context.Context, policy *model.EscalationPolicy) error {
+	if err := policy.Validate(); err != nil {
+		return err
+	}
+	if err := s.repo.Create(ctx, policy); err != nil {
+		return fmt.Errorf("创建升级策略失败: %w", err)
+	}
+	logger.Info("升级策略已创建", zap.Uint64("id", policy.ID), zap.String("name", policy.Name))
+	return nil
+}
+
+// Update 更新升级策略
+func (s *EscalationService) Update(ctx context.Context, policy *model.EscalationPolicy) error {
+	if err := policy.Validate(); err != nil {
+		return err
+	}
+	if err := s.repo.Update(ctx, policy); err != nil {
+		return fmt.Errorf("更新升级策略失败: %w", err)
+	}
+	logger.Info("升级策略已更新", zap.Uint64("id", policy.ID), zap.String("name", policy.Name))
+	return nil
+}
+
+// Delete 删除升级策略，仍被目标使用时拒绝删除
+func (s *EscalationService) Delete(ctx context.Context, id uint64) error {
+	if _, err := s.repo.GetByID(ctx, id); err != nil {
+		return err
+	}
+	count, err := s.targetRepo.CountByEscalationPolicy(ctx, id)
+	if err != nil {
+		return fmt.Errorf("查询升级策略使用情况失败: %w", err)
+	}
+	if count > 0 {
+		return fmt.Errorf("%w: %d 个目标", ErrEscalationPolicyInUse, count)
+	}
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return fmt.Errorf("删除升级策略失败: %w", err)
+	}
+	logger.Info("升级策略已删除", zap.Uint64("id", id))
+	return nil
+}
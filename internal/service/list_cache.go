@@ -0,0 +1,50 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// ruleCacheTTL 静默规则、维护窗口与路由规则缓存的有效期
+// 本实例内的修改会立即使缓存失效，有效期只影响其他实例写入数据库的修改
+const ruleCacheTTL = 10 * time.Second
+
+// listCache 带有效期的列表缓存，避免每次告警事件都查询数据库
+type listCache[T any] struct {
+	ttl  time.Duration
+	load func(ctx context.Context) ([]T, error)
+
+	mu       sync.Mutex
+	items    []T
+	loadedAt time.Time
+}
+
+// newListCache 创建列表缓存
+func newListCache[T any](ttl time.Duration, load func(ctx context.Context) ([]T, error)) *listCache[T] {
+	return &listCache[T]{ttl: ttl, load: load}
+}
+
+// get 获取缓存的列表，缓存过期时重新加载；返回的列表为只读
+func (c *listCache[T]) get(ctx context.Context) ([]T, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.loadedAt.IsZero() && time.Since(c.loadedAt) < c.ttl {
+		return c.items, nil
+	}
+	items, err := c.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	c.items = items
+	c.loadedAt = time.Now()
+	return items, nil
+}
+
+// invalidate 使缓存失效，下次获取时重新加载
+func (c *listCache[T]) invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.items = nil
+	c.loadedAt = time.Time{}
+}
@@ -0,0 +1,117 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/thingsboard-rxprobe/internal/model"
+	"github.com/thingsboard-rxprobe/internal/repository"
+	"github.com/thingsboard-rxprobe/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// MaintenanceService 维护窗口服务
+type MaintenanceService struct {
+	repo    *repository.MaintenanceRepository
+	enabled *listCache[*model.MaintenanceWindow] // 已启用的维护窗口
+}
+
+// NewMaintenanceService 创建维护窗口服务
+func NewMaintenanceService(repo *repository.MaintenanceRepository) *MaintenanceService {
+	return &MaintenanceService{
+		repo:    repo,
+		enabled: newListCache(ruleCacheTTL, repo.ListEnabled),
+	}
+}
+
+// ActiveWindow 获取目标当前所处的维护窗口，不在维护中时返回 nil
+func (s *MaintenanceService) ActiveWindow(ctx context.Context, target *model.ProbeTarget, now time.Time) *model.MaintenanceWindow {
+	windows, err := s.enabled.get(ctx)
+	if err != nil {
+		logger.Error("查询维护窗口失败", zap.Error(err))
+		return nil
+	}
+	for _, window := range windows {
+		if window.Matches(target) && window.ActiveAt(now) {
+			return window
+		}
+	}
+	return nil
+}
+
+// List 获取维护窗口列表（含当前状态）
+func (s *MaintenanceService) List(ctx context.Context) ([]*model.MaintenanceWindowView, error) {
+	windows, err := s.repo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	views := make([]*model.MaintenanceWindowView, 0, len(windows))
+	for _, window := range windows {
+		views = append(views, newMaintenanceWindowView(window))
+	}
+	return views, nil
+}
+
+// Get 获取维护窗口详情
+func (s *MaintenanceService) Get(ctx context.Context, id uint64) (*model.MaintenanceWindowView, error) {
+	window, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return newMaintenanceWindowView(window), nil
+}
+
+// Create 创建维护窗口
+func (s *MaintenanceService) Create(ctx context.Context, window *model.MaintenanceWindow) error {
+	if err := window.Validate(); err != nil {
+		return err
+	}
+	if err := s.repo.Create(ctx, window); err != nil {
+		return fmt.Errorf("创建维护窗口失败: %w", err)
+	}
+	s.enabled.invalidate()
+	logger.Info("维护窗口已创建",
+		zap.Uint64("id", window.ID),
+		zap.String("name", window.Name),
+		zap.String("cron", window.Cron),
+		zap.String("created_by", window.CreatedBy),
+	)
+	return nil
+}
+
+// Update 更新维护窗口
+func (s *MaintenanceService) Update(ctx context.Context, window *model.MaintenanceWindow) error {
+	if err := window.Validate(); err != nil {
+		return err
+	}
+	if err := s.repo.Update(ctx, window); err != nil {
+		return fmt.Errorf("更新维护窗口失败: %w", err)
+	}
+	s.enabled.invalidate()
+	logger.Info("维护窗口已更新", zap.Uint64("id", window.ID), zap.String("name", window.Name))
+	return nil
+}
+
+// Delete 删除维护窗口
+func (s *MaintenanceService) Delete(ctx context.Context, id uint64) error {
+	if _, err := s.repo.GetByID(ctx, id); err != nil {
+		return err
+	}
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return fmt.Errorf("删除维护窗口失败: %w", err)
+	}
+	s.enabled.invalidate()
+	logger.Info("维护窗口已删除", zap.Uint64("id", id))
+	return nil
+}
+
+// newMaintenanceWindowView 生成带当前状态的维护窗口
+func newMaintenanceWindowView(window *model.MaintenanceWindow) *model.MaintenanceWindowView {
+	now := time.Now()
+	return &model.MaintenanceWindowView{
+		MaintenanceWindow: window,
+		Active:            window.ActiveAt(now),
+		NextStartAt:       window.NextStart(now),
+	}
+}
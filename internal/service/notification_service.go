@@ -0,0 +1,672 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/thingsboard-rxprobe/internal/model"
+	"github.com/thingsboard-rxprobe/internal/notifier"
+	"github.com/thingsboard-rxprobe/internal/repository"
+	"github.com/thingsboard-rxprobe/pkg/logger"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const (
+	deliveryPollInterval = 10 * time.Second // 重试任务轮询间隔
+	deliveryBatchSize    = 100              // 每轮最多处理的投递数
+	deliveryConcurrency  = 5                // 重试并发数
+	maxErrorLength       = 1024             // 错误信息最大长度，与表字段一致
+)
+
+// 通知投递相关错误
+var (
+	ErrDeliveryNotFound      = errors.New("投递记录不存在")
+	ErrDeliveryNotResendable = errors.New("只能重发失败的投递")
+)
+
+// RetryPolicy 通知重试策略
+type RetryPolicy struct {
+	MaxAttempts      int           // 最大投递次数（含首次发送）
+	RetryInterval    time.Duration // 首次重试间隔
+	MaxRetryInterval time.Duration // 最大重试间隔
+}
+
+// backoff 计算第 attempt 次失败后的重试间隔（指数退避）
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	interval := p.RetryInterval
+	for i := 1; i < attempt && interval < p.MaxRetryInterval; i++ {
+		interval *= 2
+	}
+	if interval > p.MaxRetryInterval {
+		interval = p.MaxRetryInterval
+	}
+	return interval
+}
+
+// NotificationService 通知投递服务
+// 每条通知对每个渠道写入一条投递记录（发件箱），首次发送失败后由后台任务按指数退避重试。
+// 渠道按令牌桶限流，超出频率的通知排队等待发送，排队过多时合并为一条汇总通知
+type NotificationService struct {
+	deliveryRepo *repository.DeliveryRepository
+	notifierRepo *repository.NotifierRepository
+	alertRepo    *repository.AlertRepository
+	notifiers    *notifier.Registry
+	policy       RetryPolicy
+	rateLimit    RateLimitConfig
+	limiter      *channelLimiter
+	stopChan     chan struct{}
+}
+
+// NewNotificationService 创建通知投递服务
+func NewNotificationService(
+	deliveryRepo *repository.DeliveryRepository,
+	notifierRepo *repository.NotifierRepository,
+	alertRepo *repository.AlertRepository,
+	notifiers *notifier.Registry,
+	policy RetryPolicy,
+	rateLimit RateLimitConfig,
+) *NotificationService {
+	if policy.MaxAttempts <= 0 {
+		policy.MaxAttempts = 1
+	}
+	if policy.RetryInterval <= 0 {
+		policy.RetryInterval = 30 * time.Second
+	}
+	if policy.MaxRetryInterval < policy.RetryInterval {
+		policy.MaxRetryInterval = policy.RetryInterval
+	}
+	if rateLimit.QueueSize <= 0 {
+		rateLimit.QueueSize = defaultRateLimitQueue
+	}
+
+	return &NotificationService{
+		deliveryRepo: deliveryRepo,
+		notifierRepo: notifierRepo,
+		alertRepo:    alertRepo,
+		notifiers:    notifiers,
+		policy:       policy,
+		rateLimit:    rateLimit,
+		limiter:      newChannelLimiter(),
+		stopChan:     make(chan struct{}),
+	}
+}
+
+// Start 启动重试任务
+func (s *NotificationService) Start(ctx context.Context) {
+	// 恢复上次退出时处于发送中的投递
+	if n, err := s.deliveryRepo.ResetSending(ctx); err != nil {
+		logger.Error("恢复未完成的通知投递失败", zap.Error(err))
+	} else if n > 0 {
+		logger.Info("已恢复未完成的通知投递", zap.Int64("count", n))
+	}
+
+	go s.processRetries(ctx)
+	go s.processQueues(ctx)
+	logger.Info("通知投递服务已启动",
+		zap.Int("max_attempts", s.policy.MaxAttempts),
+		zap.Duration("retry_interval", s.policy.RetryInterval),
+		zap.Duration("max_retry_interval", s.policy.MaxRetryInterval),
+		zap.Int("rate_limit_per_minute", s.rateLimit.PerMinute),
+		zap.Int("rate_limit_queue", s.rateLimit.QueueSize),
+	)
+}
+
+// Stop 停止重试任务
+func (s *NotificationService) Stop() {
+	close(s.stopChan)
+	logger.Info("通知投递服务已停止")
+}
+
+// Deliver 向指定渠道投递告警通知
+// 每个渠道立即尝试发送一次，失败的投递留在发件箱中等待重试，受限流的投递排队等待发送；
+// 合并通知为其中每条告警记录各写入一条投递，只发送一次。
+// 返回首次发送成功的渠道数、因限流排队的渠道数与最后一个错误；排队的通知发送成功后由本服务回写告警记录的通知状态
+func (s *NotificationService) Deliver(ctx context.Context, channels []*model.NotifyChannel, alert *model.Alert) (int, int, error) {
+	payload, err := json.Marshal(alert)
+	if err != nil {
+		return 0, 0, fmt.Errorf("序列化告警失败: %w", err)
+	}
+	members := alert.Alerts
+	if len(members) == 0 {
+		members = []*model.Alert{alert}
+	}
+
+	var sentCount, queuedCount int
+	var lastErr error
+	for _, channel := range channels {
+		batch := make([]*model.NotificationDelivery, 0, len(members))
+		for _, member := range members {
+			// 同一告警在该渠道上尚未送达的旧通知已无意义（例如已恢复），不再重试
+			if member.ID > 0 {
+				s.supersede(ctx, member.ID, channel.ID)
+			}
+			batch = append(batch, &model.NotificationDelivery{
+				AlertRecordID: member.ID,
+				TargetID:      member.TargetID,
+				ChannelID:     channel.ID,
+				ChannelName:   channel.Name,
+				ChannelType:   channel.Type,
+				AlertStatus:   member.Status,
+				Payload:       payload,
+				Status:        model.DeliveryStatusSending,
+				MaxAttempts:   s.policy.MaxAttempts,
+			})
+		}
+		// 发件箱写入失败时仍然直接发送，避免因数据库问题丢失告警，但同样受渠道限流约束
+		created := true
+		if err := s.deliveryRepo.CreateBatch(ctx, batch); err != nil {
+			logger.Error("创建通知投递记录失败", zap.Error(err))
+			created = false
+		}
+		if !s.acquire(ctx, channel) {
+			if created {
+				s.enqueue(ctx, batch)
+				queuedCount++
+				continue
+			}
+			lastErr = fmt.Errorf("通知渠道 %s 发送频率受限，且投递记录写入失败，无法排队", channel.Name)
+			logger.Error("通知渠道发送频率受限，通知已丢弃", zap.String("channel", channel.Name), zap.Error(lastErr))
+			continue
+		}
+
+		if err := s.attempt(ctx, batch, channel); err != nil {
+			logger.Error("发送通知失败",
+				zap.String("channel", channel.Name),
+				zap.Int("attempt", batch[0].AttemptCount),
+				zap.Error(err),
+			)
+			lastErr = err
+			continue
+		}
+		sentCount++
+		logger.Debug("通知发送成功",
+			zap.String("channel", channel.Name),
+			zap.Uint64("target_id", alert.TargetID),
+		)
+	}
+	return sentCount, queuedCount, lastErr
+}
+
+// supersede 取代告警记录在渠道上尚未送达的旧通知
+// 旧通知属于合并通知批次时，批次中其余告警的投递按剩余的告警重新生成通知内容，不再携带被取代告警的旧状态
+func (s *NotificationService) supersede(ctx context.Context, alertRecordID, channelID uint64) {
+	batchIDs, err := s.deliveryRepo.Supersede(ctx, alertRecordID, channelID)
+	if err != nil {
+		logger.Warn("取代旧的通知投递失败", zap.Error(err))
+		return
+	}
+	for _, batchID := range batchIDs {
+		if err := s.rebuildBatch(ctx, batchID); err != nil {
+			logger.Warn("重新生成合并通知内容失败", zap.Uint64("batch_id", batchID), zap.Error(err))
+		}
+	}
+}
+
+// rebuildBatch 按批次中尚未发送的投递所属的告警重新生成合并通知的内容
+func (s *NotificationService) rebuildBatch(ctx context.Context, batchID uint64) error {
+	deliveries, err := s.deliveryRepo.ListUnsentBatch(ctx, batchID)
+	if err != nil || len(deliveries) == 0 {
+		return err
+	}
+	var alert model.Alert
+	if err := json.Unmarshal(deliveries[0].Payload, &alert); err != nil {
+		return fmt.Errorf("解析告警内容失败: %w", err)
+	}
+
+	remaining := make(map[uint64]bool, len(deliveries))
+	ids := make([]uint64, 0, len(deliveries))
+	for _, delivery := range deliveries {
+		remaining[delivery.AlertRecordID] = true
+		ids = append(ids, delivery.ID)
+	}
+	var alerts []*model.Alert
+	for _, member := range alert.Alerts {
+		if remaining[member.ID] {
+			alerts = append(alerts, member)
+		}
+	}
+	if len(alerts) == 0 || len(alerts) == len(alert.Alerts) {
+		return nil
+	}
+
+	rebuilt := mergeAlerts(alerts)
+	if alert.Summary {
+		rebuilt = summarizeAlerts(alerts)
+	}
+	payload, err := json.Marshal(rebuilt)
+	if err != nil {
+		return fmt.Errorf("序列化告警失败: %w", err)
+	}
+	return s.deliveryRepo.UpdateUnsentPayload(ctx, ids, payload)
+}
+
+// Queued 告警记录是否有已受理、尚未送达的通知（等待重试或因限流排队）
+// 调用方据此跳过重复发送，避免新通知取代仍在重试的通知
+func (s *NotificationService) Queued(ctx context.Context, alertRecordID uint64) bool {
+	count, err := s.deliveryRepo.CountUnsentByAlertRecord(ctx, alertRecordID)
+	if err != nil {
+		logger.Warn("统计尚未送达的通知投递失败", zap.Error(err))
+		return false
+	}
+	return count > 0
+}
+
+// ListDeliveries 获取告警记录的通知投递记录
+func (s *NotificationService) ListDeliveries(ctx context.Context, alertRecordID uint64) ([]*model.NotificationDelivery, error) {
+	return s.deliveryRepo.ListByAlertRecord(ctx, alertRecordID)
+}
+
+// Resend 手动重发一条失败的通知投递，立即尝试一次
+func (s *NotificationService) Resend(ctx context.Context, alertRecordID, deliveryID uint64) (*model.NotificationDelivery, error) {
+	delivery, err := s.deliveryRepo.GetByID(ctx, deliveryID)
+	if err != nil || delivery.AlertRecordID != alertRecordID {
+		return nil, ErrDeliveryNotFound
+	}
+	if delivery.Status != model.DeliveryStatusFailed {
+		return nil, fmt.Errorf("%w: 当前状态为 %s", ErrDeliveryNotResendable, delivery.Status)
+	}
+
+	// 合并通知的投递连同同一批次中失败的其他投递一起重发
+	batch, err := s.deliveryRepo.Claim(ctx, delivery, model.DeliveryStatusFailed)
+	if err != nil {
+		return nil, err
+	}
+	if len(batch) == 0 {
+		return nil, fmt.Errorf("%w: 正在发送中", ErrDeliveryNotResendable)
+	}
+
+	// 手动重发额外给予一次尝试机会，失败后不再自动重试
+	for _, d := range batch {
+		d.MaxAttempts = d.AttemptCount + 1
+	}
+	channel, _ := s.notifierRepo.GetByID(ctx, delivery.ChannelID)
+	if err := s.attempt(ctx, batch, channel); err == nil {
+		s.markNotified(ctx, batch)
+	}
+
+	return s.deliveryRepo.GetByID(ctx, delivery.ID)
+}
+
+// processRetries 定时处理到期的重试
+func (s *NotificationService) processRetries(ctx context.Context) {
+	ticker := time.NewTicker(deliveryPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.stopChan:
+			return
+		case <-ticker.C:
+			s.retryDue(ctx)
+		}
+	}
+}
+
+// retryDue 重试所有到期的投递
+func (s *NotificationService) retryDue(ctx context.Context) {
+	deliveries, err := s.deliveryRepo.ListDue(ctx, time.Now(), deliveryBatchSize)
+	if err != nil {
+		logger.Error("查询待重试的通知投递失败", zap.Error(err))
+		return
+	}
+	if len(deliveries) == 0 {
+		return
+	}
+
+	sem := make(chan struct{}, deliveryConcurrency)
+	var wg sync.WaitGroup
+	for _, delivery := range deliveries {
+		// 同一批次的投递在抢占第一条时已一并取出
+		batch, err := s.deliveryRepo.Claim(ctx, delivery, model.DeliveryStatusPending)
+		if err != nil || len(batch) == 0 {
+			continue
+		}
+
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(batch []*model.NotificationDelivery) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			d := batch[0]
+			channel, err := s.notifierRepo.GetByID(ctx, d.ChannelID)
+			if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+				// 数据库异常时不消耗重试次数
+				logger.Error("获取通知渠道失败", zap.Uint64("channel_id", d.ChannelID), zap.Error(err))
+				for _, d := range batch {
+					d.Status = model.DeliveryStatusPending
+					if err := s.deliveryRepo.Update(ctx, d); err != nil {
+						logger.Error("更新通知投递记录失败", zap.Error(err))
+					}
+				}
+				return
+			}
+			// 渠道受限流时转入排队，不消耗重试次数
+			if channel != nil && !s.acquire(ctx, channel) {
+				s.enqueue(ctx, batch)
+				return
+			}
+
+			if err := s.attempt(ctx, batch, channel); err != nil {
+				logger.Warn("通知重试失败",
+					zap.Uint64("delivery_id", d.ID),
+					zap.String("channel", d.ChannelName),
+					zap.Int("attempt", d.AttemptCount),
+					zap.String("status", string(d.Status)),
+					zap.Error(err),
+				)
+				return
+			}
+			s.markNotified(ctx, batch)
+			logger.Info("通知重试成功",
+				zap.Uint64("delivery_id", d.ID),
+				zap.String("channel", d.ChannelName),
+				zap.Int("attempt", d.AttemptCount),
+			)
+		}(batch)
+	}
+	wg.Wait()
+}
+
+// channelRateLimit 获取渠道每分钟最大消息数，0 表示不限流
+func (s *NotificationService) channelRateLimit(channel *model.NotifyChannel) int {
+	if limit := s.notifiers.RateLimit(channel); limit > 0 {
+		return limit
+	}
+	return s.rateLimit.PerMinute
+}
+
+// acquire 检查渠道当前能否立即发送；渠道已有排队的通知时新通知也需排队，保证发送顺序
+func (s *NotificationService) acquire(ctx context.Context, channel *model.NotifyChannel) bool {
+	limit := s.channelRateLimit(channel)
+	if limit <= 0 {
+		return true
+	}
+	if queued, err := s.deliveryRepo.CountQueued(ctx, channel.ID); err != nil {
+		logger.Warn("统计排队的通知投递失败", zap.Error(err))
+	} else if queued > 0 {
+		return false
+	}
+	return s.limiter.allow(channel.ID, limit, time.Now())
+}
+
+// enqueue 将同一条通知的投递转入限流排队
+func (s *NotificationService) enqueue(ctx context.Context, batch []*model.NotificationDelivery) {
+	for _, delivery := range batch {
+		delivery.Status = model.DeliveryStatusQueued
+		delivery.NextAttemptAt = nil
+		if err := s.deliveryRepo.Update(ctx, delivery); err != nil {
+			logger.Error("更新通知投递记录失败", zap.Error(err))
+			return
+		}
+	}
+	logger.Info("通知渠道发送频率受限，通知已排队",
+		zap.Uint64("delivery_id", batch[0].ID),
+		zap.String("channel", batch[0].ChannelName),
+	)
+}
+
+// processQueues 定时发送限流排队的通知
+func (s *NotificationService) processQueues(ctx context.Context) {
+	ticker := time.NewTicker(rateLimitCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.stopChan:
+			return
+		case <-ticker.C:
+			s.drainQueues(ctx)
+		}
+	}
+}
+
+// drainQueues 按各渠道的限流发送排队的通知
+func (s *NotificationService) drainQueues(ctx context.Context) {
+	channelIDs, err := s.deliveryRepo.ListQueuedChannels(ctx)
+	if err != nil {
+		logger.Error("查询排队的通知投递失败", zap.Error(err))
+		return
+	}
+	for _, channelID := range channelIDs {
+		s.drainChannel(ctx, channelID)
+	}
+}
+
+// drainChannel 发送渠道排队的通知，排队数超过阈值时先合并为一条汇总通知
+func (s *NotificationService) drainChannel(ctx context.Context, channelID uint64) {
+	channel, err := s.notifierRepo.GetByID(ctx, channelID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		logger.Error("获取通知渠道失败", zap.Uint64("channel_id", channelID), zap.Error(err))
+		return
+	}
+	deliveries, err := s.deliveryRepo.ListQueued(ctx, channelID)
+	if err != nil {
+		logger.Error("查询排队的通知投递失败", zap.Uint64("channel_id", channelID), zap.Error(err))
+		return
+	}
+
+	batches := groupBatches(deliveries)
+	if len(batches) > s.rateLimit.QueueSize {
+		collapsed, err := s.collapse(ctx, batches)
+		if err != nil {
+			logger.Error("合并排队的通知失败", zap.Uint64("channel_id", channelID), zap.Error(err))
+		} else {
+			batches = [][]*model.NotificationDelivery{collapsed}
+		}
+	}
+
+	// 渠道已删除时直接交由 attempt 记录失败
+	limit := 0
+	if channel != nil {
+		limit = s.channelRateLimit(channel)
+	}
+	for _, batch := range batches {
+		if !s.limiter.allow(channelID, limit, time.Now()) {
+			return
+		}
+		claimed, err := s.deliveryRepo.Claim(ctx, batch[0], model.DeliveryStatusQueued)
+		if err != nil || len(claimed) == 0 {
+			continue
+		}
+		if err := s.attempt(ctx, claimed, channel); err != nil {
+			logger.Warn("发送排队的通知失败",
+				zap.Uint64("delivery_id", claimed[0].ID),
+				zap.String("channel", claimed[0].ChannelName),
+				zap.String("status", string(claimed[0].Status)),
+				zap.Error(err),
+			)
+			continue
+		}
+		s.markNotified(ctx, claimed)
+	}
+}
+
+// groupBatches 将投递记录按通知归并，同一批次的投递归为一组，保持排队顺序
+func groupBatches(deliveries []*model.NotificationDelivery) [][]*model.NotificationDelivery {
+	var batches [][]*model.NotificationDelivery
+	index := make(map[uint64]int)
+	for _, delivery := range deliveries {
+		if delivery.BatchID > 0 {
+			if i, ok := index[delivery.BatchID]; ok {
+				batches[i] = append(batches[i], delivery)
+				continue
+			}
+			index[delivery.BatchID] = len(batches)
+		}
+		batches = append(batches, []*model.NotificationDelivery{delivery})
+	}
+	return batches
+}
+
+// collapse 将渠道排队的通知合并为一条汇总通知
+// 各投递保留所属的告警记录，合并为同一批次并共享汇总内容，返回合并后的批次
+func (s *NotificationService) collapse(ctx context.Context, batches [][]*model.NotificationDelivery) ([]*model.NotificationDelivery, error) {
+	var ids []uint64
+	alerts := make([]*model.Alert, 0, len(batches))
+	for _, batch := range batches {
+		for _, delivery := range batch {
+			ids = append(ids, delivery.ID)
+		}
+		// 同一批次的投递共享内容快照
+		var alert model.Alert
+		if err := json.Unmarshal(batch[0].Payload, &alert); err != nil {
+			logger.Warn("解析排队的告警内容失败", zap.Uint64("delivery_id", batch[0].ID), zap.Error(err))
+			continue
+		}
+		alerts = append(alerts, &alert)
+	}
+	if len(alerts) == 0 {
+		return nil, fmt.Errorf("没有可合并的告警内容")
+	}
+
+	summary := summarizeAlerts(alerts)
+	payload, err := json.Marshal(summary)
+	if err != nil {
+		return nil, fmt.Errorf("序列化告警失败: %w", err)
+	}
+	collapsed, err := s.deliveryRepo.Collapse(ctx, ids, payload)
+	if err != nil {
+		return nil, err
+	}
+	if len(collapsed) == 0 {
+		return nil, fmt.Errorf("排队的通知已被处理")
+	}
+	logger.Info("渠道排队的通知已合并为汇总通知",
+		zap.String("channel", collapsed[0].ChannelName),
+		zap.Int("count", len(batches)),
+		zap.Uint64("batch_id", collapsed[0].BatchID),
+	)
+	return collapsed, nil
+}
+
+// attempt 执行一次投递并记录结果，batch 为同一条通知的投递记录；channel 为空表示渠道已被删除
+func (s *NotificationService) attempt(ctx context.Context, batch []*model.NotificationDelivery, channel *model.NotifyChannel) error {
+	var alert model.Alert
+	if err := json.Unmarshal(batch[0].Payload, &alert); err != nil {
+		return s.finish(ctx, batch, nil, fmt.Errorf("解析告警内容失败: %w", err), 0, true)
+	}
+	if channel == nil {
+		return s.finish(ctx, batch, nil, fmt.Errorf("通知渠道不存在"), 0, true)
+	}
+	if !channel.Enabled {
+		return s.finish(ctx, batch, nil, fmt.Errorf("通知渠道已禁用"), 0, true)
+	}
+
+	start := time.Now()
+	resp, err := s.notifiers.Send(ctx, channel, &alert)
+	return s.finish(ctx, batch, resp, err, time.Since(start), false)
+}
+
+// finish 为同一条通知的每条投递记录本次尝试并更新投递状态；permanent 表示错误不可恢复，不再重试
+func (s *NotificationService) finish(
+	ctx context.Context,
+	batch []*model.NotificationDelivery,
+	resp *notifier.Response,
+	sendErr error,
+	latency time.Duration,
+	permanent bool,
+) error {
+	now := time.Now()
+	for _, delivery := range batch {
+		s.recordAttempt(ctx, delivery, resp, sendErr, latency, permanent, now)
+	}
+	return sendErr
+}
+
+// recordAttempt 记录一条投递的尝试结果并更新其状态
+func (s *NotificationService) recordAttempt(
+	ctx context.Context,
+	delivery *model.NotificationDelivery,
+	resp *notifier.Response,
+	sendErr error,
+	latency time.Duration,
+	permanent bool,
+	now time.Time,
+) {
+	delivery.AttemptCount++
+
+	attempt := &model.NotificationAttempt{
+		DeliveryID:  delivery.ID,
+		Attempt:     delivery.AttemptCount,
+		Success:     sendErr == nil,
+		LatencyMs:   latency.Milliseconds(),
+		AttemptedAt: now,
+	}
+	if resp != nil {
+		attempt.StatusCode = resp.StatusCode
+		attempt.Response = resp.Body
+	}
+
+	if sendErr == nil {
+		delivery.Status = model.DeliveryStatusSuccess
+		delivery.DeliveredAt = &now
+		delivery.NextAttemptAt = nil
+		delivery.LastError = ""
+	} else {
+		attempt.Error = truncate(sendErr.Error(), maxErrorLength)
+		delivery.LastError = attempt.Error
+		if permanent || delivery.AttemptCount >= delivery.MaxAttempts {
+			delivery.Status = model.DeliveryStatusFailed
+			delivery.NextAttemptAt = nil
+		} else {
+			next := now.Add(s.policy.backoff(delivery.AttemptCount))
+			delivery.Status = model.DeliveryStatusPending
+			delivery.NextAttemptAt = &next
+		}
+	}
+
+	// 投递记录写入失败（ID 为 0）时只发送不记录
+	if delivery.ID > 0 {
+		if err := s.deliveryRepo.CreateAttempt(ctx, attempt); err != nil {
+			logger.Error("记录通知投递尝试失败", zap.Error(err))
+		}
+		if err := s.deliveryRepo.Update(ctx, delivery); err != nil {
+			logger.Error("更新通知投递记录失败", zap.Error(err))
+		}
+	}
+}
+
+// markNotified 后台发送（重试、限流排队、手动重发）成功后回写告警记录的通知状态
+// Deliver 首次发送成功的通知由调用方（AlertService）负责回写
+func (s *NotificationService) markNotified(ctx context.Context, batch []*model.NotificationDelivery) {
+	for _, delivery := range batch {
+		if delivery.Status != model.DeliveryStatusSuccess || delivery.AlertStatus != model.AlertStatusFiring ||
+			delivery.AlertRecordID == 0 || delivery.DeliveredAt == nil {
+			continue
+		}
+		if err := s.alertRepo.MarkNotified(ctx, delivery.AlertRecordID, *delivery.DeliveredAt); err != nil {
+			logger.Error("更新告警通知状态失败", zap.Error(err))
+		}
+		content := fmt.Sprintf("通过 %s 发送排队的告警通知", delivery.ChannelName)
+		if delivery.AttemptCount > 1 {
+			content = fmt.Sprintf("第 %d 次尝试后通过 %s 发送告警通知", delivery.AttemptCount, delivery.ChannelName)
+		}
+		activity := &model.AlertActivity{
+			AlertRecordID: delivery.AlertRecordID,
+			Type:          model.AlertActivityNotified,
+			Content:       content,
+		}
+		if err := s.alertRepo.CreateActivity(ctx, activity); err != nil {
+			logger.Error("记录告警活动失败", zap.Error(err))
+		}
+	}
+}
+
+// truncate 按字符截断字符串
+func truncate(s string, max int) string {
+	runes := []rune(s)
+	if len(runes) <= max {
+		return s
+	}
+	return string(runes[:max])
+}
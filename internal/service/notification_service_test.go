@@ -0,0 +1,381 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/thingsboard-rxprobe/internal/model"
+	"github.com/thingsboard-rxprobe/internal/notifier"
+	"github.com/thingsboard-rxprobe/internal/repository"
+)
+
+// newTestNotificationService 创建使用内存数据库的通知投递服务
+func newTestNotificationService(t *testing.T, policy RetryPolicy, rateLimit RateLimitConfig) *NotificationService {
+	t.Helper()
+	db := newTestDB(t)
+	return NewNotificationService(
+		repository.NewDeliveryRepository(db),
+		repository.NewNotifierRepository(db),
+		repository.NewAlertRepository(db),
+		notifier.NewRegistry(),
+		policy,
+		rateLimit,
+	)
+}
+
+// fakeNotifier 测试用通知渠道，前 failures 次发送失败，之后记录发送的告警
+type fakeNotifier struct {
+	mu       sync.Mutex
+	failures int
+	sent     []*model.Alert
+	sentTo   []uint64 // 与 sent 对应的渠道 ID
+}
+
+const fakeChannelType model.NotifyChannelType = "fake"
+
+func (n *fakeNotifier) Type() model.NotifyChannelType               { return fakeChannelType }
+func (n *fakeNotifier) Name() string                                { return "fake" }
+func (n *fakeNotifier) Description() string                         { return "fake" }
+func (n *fakeNotifier) Validate(channel *model.NotifyChannel) error { return nil }
+
+func (n *fakeNotifier) Send(ctx context.Context, channel *model.NotifyChannel, alert *model.Alert) (*notifier.Response, error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.failures > 0 {
+		n.failures--
+		return &notifier.Response{StatusCode: 500}, errors.New("channel unavailable")
+	}
+	n.sent = append(n.sent, alert)
+	n.sentTo = append(n.sentTo, channel.ID)
+	return &notifier.Response{StatusCode: 200}, nil
+}
+
+// sentAlerts 获取已发送的告警
+func (n *fakeNotifier) sentAlerts() []*model.Alert {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return append([]*model.Alert(nil), n.sent...)
+}
+
+// sentChannels 获取已发送告警的渠道 ID
+func (n *fakeNotifier) sentChannels() []uint64 {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return append([]uint64(nil), n.sentTo...)
+}
+
+// newFakeChannel 注册测试用通知渠道并写入数据库
+func newFakeChannel(t *testing.T, s *NotificationService, n *fakeNotifier, config string) *model.NotifyChannel {
+	t.Helper()
+	s.notifiers.Register(n)
+	channel := &model.NotifyChannel{Name: "fake", Type: fakeChannelType, Enabled: true}
+	if config != "" {
+		channel.Config = []byte(config)
+	}
+	if err := s.notifierRepo.Create(context.Background(), channel); err != nil {
+		t.Fatal(err)
+	}
+	return channel
+}
+
+// newAlertRecord 写入一条告警中的告警记录
+func newAlertRecord(t *testing.T, s *NotificationService, targetID uint64) *model.AlertRecord {
+	t.Helper()
+	record := &model.AlertRecord{TargetID: targetID, TargetName: "node", Status: model.AlertStatusFiring, FiredAt: time.Now()}
+	if err := s.alertRepo.CreateRecord(context.Background(), record); err != nil {
+		t.Fatal(err)
+	}
+	return record
+}
+
+// makeDue 将投递的下次重试时间提前到当前时间之前
+func makeDue(t *testing.T, s *NotificationService, id uint64) {
+	t.Helper()
+	delivery, err := s.deliveryRepo.GetByID(context.Background(), id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	past := time.Now().Add(-time.Second)
+	delivery.NextAttemptAt = &past
+	if err := s.deliveryRepo.Update(context.Background(), delivery); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// testAlert 创建测试用的单条告警
+func testAlert(id uint64, name string, status model.AlertStatus) *model.Alert {
+	return &model.Alert{
+		ID:         id,
+		TargetID:   id,
+		TargetName: name,
+		TargetType: "http",
+		Group:      "tb",
+		Status:     status,
+		Severity:   model.SeverityCritical,
+		Message:    name + " " + string(status),
+		FiredAt:    time.Now(),
+	}
+}
+
+// createBatch 为合并通知的每条告警写入一条指定状态的投递
+func createBatch(t *testing.T, s *NotificationService, channelID uint64, alert *model.Alert, status model.DeliveryStatus) []*model.NotificationDelivery {
+	t.Helper()
+	payload, err := json.Marshal(alert)
+	if err != nil {
+		t.Fatal(err)
+	}
+	members := alert.Alerts
+	if len(members) == 0 {
+		members = []*model.Alert{alert}
+	}
+	var batch []*model.NotificationDelivery
+	for _, member := range members {
+		batch = append(batch, &model.NotificationDelivery{
+			AlertRecordID: member.ID,
+			TargetID:      member.TargetID,
+			ChannelID:     channelID,
+			AlertStatus:   member.Status,
+			Payload:       payload,
+			Status:        status,
+			MaxAttempts:   3,
+		})
+	}
+	if err := s.deliveryRepo.CreateBatch(context.Background(), batch); err != nil {
+		t.Fatal(err)
+	}
+	return batch
+}
+
+// deliveryAlert 读取投递记录及其通知内容
+func deliveryAlert(t *testing.T, s *NotificationService, id uint64) (*model.NotificationDelivery, *model.Alert) {
+	t.Helper()
+	delivery, err := s.deliveryRepo.GetByID(context.Background(), id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var alert model.Alert
+	if err := json.Unmarshal(delivery.Payload, &alert); err != nil {
+		t.Fatal(err)
+	}
+	return delivery, &alert
+}
+
+func TestSupersedeRebuildsBatch(t *testing.T) {
+	tests := []struct {
+		name   string
+		merge  func([]*model.Alert) *model.Alert
+		status model.DeliveryStatus
+	}{
+		{"分组通知等待重试", mergeAlerts, model.DeliveryStatusPending},
+		{"限流汇总通知排队", summarizeAlerts, model.DeliveryStatusQueued},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestNotificationService(t, RetryPolicy{}, RateLimitConfig{})
+			ctx := context.Background()
+			alert := tt.merge([]*model.Alert{
+				testAlert(1, "node-a", model.AlertStatusFiring),
+				testAlert(2, "node-b", model.AlertStatusFiring),
+				testAlert(3, "node-c", model.AlertStatusFiring),
+			})
+			batch := createBatch(t, s, 7, alert, tt.status)
+
+			// 其他渠道上的同一告警不受影响
+			other := createBatch(t, s, 8, testAlert(2, "node-b", model.AlertStatusFiring), tt.status)
+
+			s.supersede(ctx, 2, 7)
+
+			if delivery, _ := deliveryAlert(t, s, batch[1].ID); delivery.Status != model.DeliveryStatusSuperseded {
+				t.Fatalf("被取代的投递状态 = %s", delivery.Status)
+			}
+			if delivery, _ := deliveryAlert(t, s, other[0].ID); delivery.Status != tt.status {
+				t.Fatalf("其他渠道的投递状态 = %s", delivery.Status)
+			}
+			for _, id := range []uint64{batch[0].ID, batch[2].ID} {
+				delivery, rebuilt := deliveryAlert(t, s, id)
+				if delivery.Status != tt.status {
+					t.Fatalf("批次中其余投递的状态 = %s", delivery.Status)
+				}
+				if len(rebuilt.Alerts) != 2 || rebuilt.Alerts[0].ID != 1 || rebuilt.Alerts[1].ID != 3 {
+					t.Fatalf("重新生成的通知应只包含剩余告警: %+v", rebuilt.Alerts)
+				}
+				if strings.Contains(rebuilt.Message, "node-b") {
+					t.Fatalf("重新生成的通知仍包含被取代的告警: %s / %s", rebuilt.TargetName, rebuilt.Message)
+				}
+				if rebuilt.Summary != alert.Summary {
+					t.Fatalf("重新生成的通知类型改变: summary=%v", rebuilt.Summary)
+				}
+			}
+		})
+	}
+}
+
+func TestSupersedeLeavesSentBatch(t *testing.T) {
+	s := newTestNotificationService(t, RetryPolicy{}, RateLimitConfig{})
+	alert := mergeAlerts([]*model.Alert{
+		testAlert(1, "node-a", model.AlertStatusFiring),
+		testAlert(2, "node-b", model.AlertStatusFiring),
+	})
+	batch := createBatch(t, s, 7, alert, model.DeliveryStatusSuccess)
+
+	s.supersede(context.Background(), 2, 7)
+
+	for _, d := range batch {
+		delivery, sent := deliveryAlert(t, s, d.ID)
+		if delivery.Status != model.DeliveryStatusSuccess || len(sent.Alerts) != 2 {
+			t.Fatalf("已发送的投递不应被修改: status=%s alerts=%d", delivery.Status, len(sent.Alerts))
+		}
+	}
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	policy := RetryPolicy{RetryInterval: 30 * time.Second, MaxRetryInterval: 5 * time.Minute}
+	want := []time.Duration{30 * time.Second, time.Minute, 2 * time.Minute, 4 * time.Minute, 5 * time.Minute, 5 * time.Minute}
+	for i, w := range want {
+		if got := policy.backoff(i + 1); got != w {
+			t.Fatalf("第 %d 次失败后的重试间隔 = %v, want %v", i+1, got, w)
+		}
+	}
+}
+
+func TestDeliverRetriesWithBackoff(t *testing.T) {
+	s := newTestNotificationService(t, RetryPolicy{MaxAttempts: 3, RetryInterval: time.Minute, MaxRetryInterval: time.Hour}, RateLimitConfig{})
+	ctx := context.Background()
+	fake := &fakeNotifier{failures: 3}
+	channel := newFakeChannel(t, s, fake, "")
+	record := newAlertRecord(t, s, 1)
+
+	sent, queued, err := s.Deliver(ctx, []*model.NotifyChannel{channel}, testAlert(record.ID, "node", model.AlertStatusFiring))
+	if err == nil || sent != 0 || queued != 0 {
+		t.Fatalf("首次发送应失败: sent=%d queued=%d err=%v", sent, queued, err)
+	}
+	deliveries, _ := s.ListDeliveries(ctx, record.ID)
+	if len(deliveries) != 1 {
+		t.Fatalf("投递记录数 = %d", len(deliveries))
+	}
+	delivery := deliveries[0]
+	if delivery.Status != model.DeliveryStatusPending || delivery.AttemptCount != 1 || len(delivery.Attempts) != 1 {
+		t.Fatalf("失败的投递应等待重试: %+v", delivery)
+	}
+	if wait := time.Until(*delivery.NextAttemptAt); wait < 55*time.Second || wait > time.Minute {
+		t.Fatalf("首次重试间隔 = %v", wait)
+	}
+	if !s.Queued(ctx, record.ID) {
+		t.Fatal("等待重试的通知应视为尚未送达")
+	}
+
+	// 未到重试时间不重试
+	s.retryDue(ctx)
+	if delivery, _ = s.deliveryRepo.GetByID(ctx, delivery.ID); delivery.AttemptCount != 1 {
+		t.Fatalf("未到重试时间不应重试: attempt=%d", delivery.AttemptCount)
+	}
+
+	makeDue(t, s, delivery.ID)
+	s.retryDue(ctx)
+	delivery, _ = s.deliveryRepo.GetByID(ctx, delivery.ID)
+	if delivery.Status != model.DeliveryStatusPending || delivery.AttemptCount != 2 {
+		t.Fatalf("第二次尝试失败后应继续等待重试: %+v", delivery)
+	}
+	if wait := time.Until(*delivery.NextAttemptAt); wait < 115*time.Second || wait > 2*time.Minute {
+		t.Fatalf("第二次重试间隔应按指数退避翻倍: %v", wait)
+	}
+
+	// 重试次数耗尽后不再重试
+	makeDue(t, s, delivery.ID)
+	s.retryDue(ctx)
+	delivery, _ = s.deliveryRepo.GetByID(ctx, delivery.ID)
+	if delivery.Status != model.DeliveryStatusFailed || delivery.AttemptCount != 3 || delivery.NextAttemptAt != nil {
+		t.Fatalf("重试次数耗尽后应为失败: %+v", delivery)
+	}
+	if s.Queued(ctx, record.ID) {
+		t.Fatal("失败的通知不应视为尚未送达")
+	}
+
+	// 手动重发成功后回写告警记录的通知状态
+	if _, err := s.Resend(ctx, record.ID, delivery.ID); err != nil {
+		t.Fatal(err)
+	}
+	delivery, _ = s.deliveryRepo.GetByID(ctx, delivery.ID)
+	if delivery.Status != model.DeliveryStatusSuccess || delivery.AttemptCount != 4 || len(fake.sentAlerts()) != 1 {
+		t.Fatalf("手动重发应成功: %+v", delivery)
+	}
+	if updated, _ := s.alertRepo.GetRecordByID(ctx, record.ID); !updated.Notified {
+		t.Fatal("重发成功后告警记录应标记为已通知")
+	}
+	if _, err := s.Resend(ctx, record.ID, delivery.ID); !errors.Is(err, ErrDeliveryNotResendable) {
+		t.Fatalf("成功的投递不能重发: %v", err)
+	}
+}
+
+func TestDeliverSupersedesPendingRetry(t *testing.T) {
+	s := newTestNotificationService(t, RetryPolicy{MaxAttempts: 3, RetryInterval: time.Minute}, RateLimitConfig{})
+	ctx := context.Background()
+	fake := &fakeNotifier{failures: 1}
+	channel := newFakeChannel(t, s, fake, "")
+	record := newAlertRecord(t, s, 1)
+
+	if _, _, err := s.Deliver(ctx, []*model.NotifyChannel{channel}, testAlert(record.ID, "node", model.AlertStatusFiring)); err == nil {
+		t.Fatal("首次发送应失败")
+	}
+	// 恢复通知取代仍在等待重试的告警通知
+	if sent, _, err := s.Deliver(ctx, []*model.NotifyChannel{channel}, testAlert(record.ID, "node", model.AlertStatusResolved)); err != nil || sent != 1 {
+		t.Fatalf("恢复通知应发送成功: sent=%d err=%v", sent, err)
+	}
+
+	deliveries, _ := s.ListDeliveries(ctx, record.ID)
+	if len(deliveries) != 2 {
+		t.Fatalf("投递记录数 = %d", len(deliveries))
+	}
+	if deliveries[0].Status != model.DeliveryStatusSuperseded || deliveries[0].NextAttemptAt != nil {
+		t.Fatalf("旧的告警通知应被取代: %+v", deliveries[0])
+	}
+	if deliveries[1].Status != model.DeliveryStatusSuccess || deliveries[1].AlertStatus != model.AlertStatusResolved {
+		t.Fatalf("恢复通知应发送成功: %+v", deliveries[1])
+	}
+
+	makeDue(t, s, deliveries[0].ID)
+	s.retryDue(ctx)
+	if sent := fake.sentAlerts(); len(sent) != 1 || sent[0].Status != model.AlertStatusResolved {
+		t.Fatalf("被取代的通知不应再重试: %d", len(sent))
+	}
+}
+
+func TestClaim(t *testing.T) {
+	s := newTestNotificationService(t, RetryPolicy{}, RateLimitConfig{})
+	ctx := context.Background()
+
+	single := createBatch(t, s, 7, testAlert(1, "node-a", model.AlertStatusFiring), model.DeliveryStatusPending)[0]
+	claimed, err := s.deliveryRepo.Claim(ctx, single, model.DeliveryStatusPending)
+	if err != nil || len(claimed) != 1 || claimed[0].Status != model.DeliveryStatusSending {
+		t.Fatalf("应抢占到投递: %v %+v", err, claimed)
+	}
+	if claimed, err := s.deliveryRepo.Claim(ctx, single, model.DeliveryStatusPending); err != nil || len(claimed) != 0 {
+		t.Fatalf("已抢占的投递不应再次被抢占: %v %d", err, len(claimed))
+	}
+
+	batch := createBatch(t, s, 7, mergeAlerts([]*model.Alert{
+		testAlert(2, "node-b", model.AlertStatusFiring),
+		testAlert(3, "node-c", model.AlertStatusFiring),
+		testAlert(4, "node-d", model.AlertStatusFiring),
+	}), model.DeliveryStatusPending)
+	s.supersede(ctx, 3, 7)
+
+	// 从批次中任意一条投递抢占时取出批次中同一状态的全部投递，已被取代的不再发送
+	claimed, err = s.deliveryRepo.Claim(ctx, batch[2], model.DeliveryStatusPending)
+	if err != nil || len(claimed) != 2 || claimed[0].ID != batch[0].ID || claimed[1].ID != batch[2].ID {
+		t.Fatalf("应抢占批次中未被取代的投递: %v %+v", err, claimed)
+	}
+	for _, d := range claimed {
+		if d.Status != model.DeliveryStatusSending || d.BatchID != batch[0].ID {
+			t.Fatalf("抢占的投递状态不正确: %+v", d)
+		}
+	}
+	if claimed, err := s.deliveryRepo.Claim(ctx, batch[0], model.DeliveryStatusPending); err != nil || len(claimed) != 0 {
+		t.Fatalf("已抢占的批次不应再次被抢占: %v %d", err, len(claimed))
+	}
+}
@@ -3,6 +3,7 @@ package service
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"time"
 
@@ -12,15 +13,17 @@ import (
 	"github.com/thingsboard-rxprobe/internal/scheduler"
 	"github.com/thingsboard-rxprobe/pkg/logger"
 	"go.uber.org/zap"
+	"gorm.io/gorm"
 )
 
 // ProbeService 探测服务
 type ProbeService struct {
-	targetRepo *repository.TargetRepository
-	resultRepo *repository.ResultRepository
-	alertRepo  *repository.AlertRepository
-	factory    *prober.Factory
-	scheduler  *scheduler.Scheduler
+	targetRepo     *repository.TargetRepository
+	resultRepo     *repository.ResultRepository
+	alertRepo      *repository.AlertRepository
+	escalationRepo *repository.EscalationRepository
+	factory        *prober.Factory
+	scheduler      *scheduler.Scheduler
 }
 
 // NewProbeService 创建探测服务
@@ -28,15 +31,17 @@ func NewProbeService(
 	targetRepo *repository.TargetRepository,
 	resultRepo *repository.ResultRepository,
 	alertRepo *repository.AlertRepository,
+	escalationRepo *repository.EscalationRepository,
 	factory *prober.Factory,
 	sch *scheduler.Scheduler,
 ) *ProbeService {
 	return &ProbeService{
-		targetRepo: targetRepo,
-		resultRepo: resultRepo,
-		alertRepo:  alertRepo,
-		factory:    factory,
-		scheduler:  sch,
+		targetRepo:     targetRepo,
+		resultRepo:     resultRepo,
+		alertRepo:      alertRepo,
+		escalationRepo: escalationRepo,
+		factory:        factory,
+		scheduler:      sch,
 	}
 }
 
@@ -76,6 +81,39 @@ func (s *ProbeService) CreateTarget(ctx context.Context, req *model.CreateTarget
 		return nil, fmt.Errorf("探测间隔时间必须大于等于30秒，当前值: %d秒", req.IntervalSeconds)
 	}
 
+	// 告警策略默认值：连续失败 1 次告警、连续成功 1 次恢复
+	if req.FailureThreshold <= 0 {
+		req.FailureThreshold = 1
+	}
+	if req.RecoveryThreshold <= 0 {
+		req.RecoveryThreshold = 1
+	}
+	if req.RepeatIntervalSeconds < 0 {
+		return nil, fmt.Errorf("重复通知间隔不能为负数，当前值: %d秒", req.RepeatIntervalSeconds)
+	}
+	if req.Severity == "" {
+		req.Severity = model.SeverityCritical
+	}
+	if !req.Severity.Valid() {
+		return nil, fmt.Errorf("告警级别无效: %s", req.Severity)
+	}
+
+	if err := s.checkEscalationPolicy(ctx, req.EscalationPolicyID); err != nil {
+		return nil, err
+	}
+
+	// 校验依赖的上游目标
+	parentIDsJSON, err := s.marshalParentIDs(ctx, 0, req.ParentIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	// 校验指标阈值规则
+	metricRulesJSON, err := marshalMetricRules(req.MetricRules)
+	if err != nil {
+		return nil, err
+	}
+
 	// 根据 enabled 状态设置初始 status
 	initialStatus := model.TargetStatusUnknown
 	initialMessage := "等待首次探测"
@@ -95,16 +133,24 @@ func (s *ProbeService) CreateTarget(ctx context.Context, req *model.CreateTarget
 	}
 
 	probeTarget := &model.ProbeTarget{
-		Name:             req.Name,
-		Type:             req.Type,
-		Config:           configJSON,
-		TimeoutSeconds:   req.TimeoutSeconds,
-		IntervalSeconds:  req.IntervalSeconds,
-		Enabled:          req.Enabled,
-		Status:           initialStatus,
-		LastMessage:      initialMessage,
-		Group:            req.Group,
-		NotifyChannelIDs: notifyChannelIDsJSON,
+		Name:                  req.Name,
+		Type:                  req.Type,
+		Config:                configJSON,
+		TimeoutSeconds:        req.TimeoutSeconds,
+		IntervalSeconds:       req.IntervalSeconds,
+		Enabled:               req.Enabled,
+		Status:                initialStatus,
+		LastMessage:           initialMessage,
+		Group:                 req.Group,
+		NotifyChannelIDs:      notifyChannelIDsJSON,
+		FailureThreshold:      req.FailureThreshold,
+		RecoveryThreshold:     req.RecoveryThreshold,
+		RepeatIntervalSeconds: req.RepeatIntervalSeconds,
+		EscalationPolicyID:    req.EscalationPolicyID,
+		ParentIDs:             parentIDsJSON,
+		Severity:              req.Severity,
+		AlertOnDegraded:       req.AlertOnDegraded,
+		MetricRules:           metricRulesJSON,
 	}
 
 	if err := s.targetRepo.Create(ctx, probeTarget); err != nil {
@@ -188,6 +234,53 @@ func (s *ProbeService) UpdateTarget(ctx context.Context, id uint64, req *model.U
 		}
 		target.NotifyChannelIDs = notifyChannelIDsJSON
 	}
+	if req.FailureThreshold != nil {
+		if *req.FailureThreshold < 1 {
+			return nil, fmt.Errorf("连续失败阈值必须大于 0，当前值: %d", *req.FailureThreshold)
+		}
+		target.FailureThreshold = *req.FailureThreshold
+	}
+	if req.RecoveryThreshold != nil {
+		if *req.RecoveryThreshold < 1 {
+			return nil, fmt.Errorf("连续成功阈值必须大于 0，当前值: %d", *req.RecoveryThreshold)
+		}
+		target.RecoveryThreshold = *req.RecoveryThreshold
+	}
+	if req.RepeatIntervalSeconds != nil {
+		if *req.RepeatIntervalSeconds < 0 {
+			return nil, fmt.Errorf("重复通知间隔不能为负数，当前值: %d秒", *req.RepeatIntervalSeconds)
+		}
+		target.RepeatIntervalSeconds = *req.RepeatIntervalSeconds
+	}
+	if req.EscalationPolicyID != nil {
+		if err := s.checkEscalationPolicy(ctx, *req.EscalationPolicyID); err != nil {
+			return nil, err
+		}
+		target.EscalationPolicyID = *req.EscalationPolicyID
+	}
+	if req.ParentIDs != nil {
+		parentIDsJSON, err := s.marshalParentIDs(ctx, target.ID, *req.ParentIDs)
+		if err != nil {
+			return nil, err
+		}
+		target.ParentIDs = parentIDsJSON
+	}
+	if req.Severity != "" {
+		if !req.Severity.Valid() {
+			return nil, fmt.Errorf("告警级别无效: %s", req.Severity)
+		}
+		target.Severity = req.Severity
+	}
+	if req.AlertOnDegraded != nil {
+		target.AlertOnDegraded = *req.AlertOnDegraded
+	}
+	if req.MetricRules != nil {
+		metricRulesJSON, err := marshalMetricRules(*req.MetricRules)
+		if err != nil {
+			return nil, err
+		}
+		target.MetricRules = metricRulesJSON
+	}
 
 	if err := s.targetRepo.Update(ctx, target); err != nil {
 		return nil, fmt.Errorf("更新目标失败: %w", err)
@@ -201,11 +294,133 @@ func (s *ProbeService) UpdateTarget(ctx context.Context, id uint64, req *model.U
 	return target, nil
 }
 
+// checkEscalationPolicy 校验升级策略存在，policyID 为 0 表示不使用升级策略
+func (s *ProbeService) checkEscalationPolicy(ctx context.Context, policyID uint64) error {
+	if policyID == 0 {
+		return nil
+	}
+	if _, err := s.escalationRepo.GetByID(ctx, policyID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("升级策略不存在: %d", policyID)
+		}
+		return fmt.Errorf("查询升级策略失败: %w", err)
+	}
+	return nil
+}
+
+// marshalMetricRules 校验并序列化指标阈值规则列表
+func marshalMetricRules(rules []model.MetricRule) ([]byte, error) {
+	for _, rule := range rules {
+		if err := rule.Validate(); err != nil {
+			return nil, err
+		}
+	}
+	if rules == nil {
+		rules = []model.MetricRule{}
+	}
+	data, err := json.Marshal(rules)
+	if err != nil {
+		return nil, fmt.Errorf("序列化指标阈值规则失败: %w", err)
+	}
+	return data, nil
+}
+
+// marshalParentIDs 校验并序列化依赖的上游目标ID列表
+// 上游目标必须存在，且不能依赖自身或形成循环依赖；targetID 为 0 表示新建目标
+func (s *ProbeService) marshalParentIDs(ctx context.Context, targetID uint64, parentIDs []uint64) ([]byte, error) {
+	if len(parentIDs) == 0 {
+		return []byte("[]"), nil
+	}
+
+	parents, err := s.targetRepo.GetByIDs(ctx, parentIDs)
+	if err != nil {
+		return nil, fmt.Errorf("查询上游目标失败: %w", err)
+	}
+	found := make(map[uint64]bool, len(parents))
+	for _, parent := range parents {
+		found[parent.ID] = true
+	}
+	for _, id := range parentIDs {
+		if id == targetID {
+			return nil, fmt.Errorf("目标不能依赖自身")
+		}
+		if !found[id] {
+			return nil, fmt.Errorf("上游目标不存在: %d", id)
+		}
+	}
+
+	// 沿上游链路查找，检查是否会回到当前目标
+	if targetID > 0 {
+		visited := make(map[uint64]bool)
+		queue := parents
+		for len(queue) > 0 {
+			var next []uint64
+			for _, t := range queue {
+				if visited[t.ID] {
+					continue
+				}
+				visited[t.ID] = true
+				for _, id := range t.GetParentIDs() {
+					if id == targetID {
+						return nil, fmt.Errorf("存在循环依赖: 目标 %s 已直接或间接依赖当前目标", t.Name)
+					}
+					if !visited[id] {
+						next = append(next, id)
+					}
+				}
+			}
+			if queue, err = s.targetRepo.GetByIDs(ctx, next); err != nil {
+				return nil, fmt.Errorf("查询上游目标失败: %w", err)
+			}
+		}
+	}
+
+	data, err := json.Marshal(parentIDs)
+	if err != nil {
+		return nil, fmt.Errorf("序列化上游目标ID失败: %w", err)
+	}
+	return data, nil
+}
+
+// removeParentReferences 从依赖指定目标的下游目标中移除该上游目标
+// 只更新数据库：调度器中的目标副本查询上游时会忽略已删除的目标，无需重建任务而重置其失败计数
+func (s *ProbeService) removeParentReferences(ctx context.Context, parentID uint64) error {
+	children, err := s.targetRepo.ListByParentID(ctx, parentID)
+	if err != nil {
+		return fmt.Errorf("查询下游目标失败: %w", err)
+	}
+	for _, child := range children {
+		parentIDs := make([]uint64, 0)
+		for _, id := range child.GetParentIDs() {
+			if id != parentID {
+				parentIDs = append(parentIDs, id)
+			}
+		}
+		data, err := json.Marshal(parentIDs)
+		if err != nil {
+			return fmt.Errorf("序列化上游目标ID失败: %w", err)
+		}
+		if err := s.targetRepo.UpdateParentIDs(ctx, child.ID, data); err != nil {
+			return fmt.Errorf("更新下游目标 %s 失败: %w", child.Name, err)
+		}
+		logger.Info("已移除下游目标对被删除目标的依赖",
+			zap.Uint64("target_id", child.ID),
+			zap.Uint64("parent_id", parentID),
+		)
+	}
+	return nil
+}
+
 // DeleteTarget 删除探测目标
 func (s *ProbeService) DeleteTarget(ctx context.Context, id uint64) error {
 	// 从调度器移除
 	s.scheduler.RemoveTask(id)
 
+	// 从下游目标的依赖列表中移除
+	if err := s.removeParentReferences(ctx, id); err != nil {
+		return err
+	}
+
 	// 删除关联的探测结果
 	if deletedResults, err := s.resultRepo.DeleteByTargetID(ctx, id); err != nil {
 		logger.Error("删除探测结果失败",
@@ -306,6 +521,10 @@ func (s *ProbeService) GetProbeTypes() []map[string]string {
 		{"value": "tcp", "label": "TCP", "icon": "network"},
 		{"value": "ping", "label": "Ping", "icon": "network"},
 		{"value": "cpu", "label": "CPU", "icon": "cpu"},
+		{"value": "mqtt", "label": "MQTT", "icon": "message"},
+		{"value": "coap", "label": "CoAP", "icon": "network"},
+		{"value": "lwm2m", "label": "LwM2M", "icon": "network"},
+		{"value": "zookeeper", "label": "ZooKeeper", "icon": "server"},
 	}
 	return types
 }
@@ -0,0 +1,44 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/thingsboard-rxprobe/internal/model"
+	"github.com/thingsboard-rxprobe/internal/repository"
+)
+
+func TestUpdateTargetRejectsInvalidThresholds(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+	s := NewProbeService(repository.NewTargetRepository(db), nil, nil, nil, nil, nil)
+	target := &model.ProbeTarget{Name: "tb-node", Type: "http", Enabled: true, FailureThreshold: 3, RecoveryThreshold: 2}
+	if err := s.targetRepo.Create(ctx, target); err != nil {
+		t.Fatal(err)
+	}
+
+	zero, negative := 0, -1
+	tests := []struct {
+		name string
+		req  *model.UpdateTargetRequest
+	}{
+		{"连续失败阈值为 0", &model.UpdateTargetRequest{FailureThreshold: &zero}},
+		{"连续失败阈值为负数", &model.UpdateTargetRequest{FailureThreshold: &negative}},
+		{"连续成功阈值为 0", &model.UpdateTargetRequest{RecoveryThreshold: &zero}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := s.UpdateTarget(ctx, target.ID, tt.req); err == nil {
+				t.Fatal("阈值小于 1 时应返回错误")
+			}
+		})
+	}
+
+	saved, err := s.targetRepo.GetByID(ctx, target.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if saved.FailureThreshold != 3 || saved.RecoveryThreshold != 2 {
+		t.Fatalf("验证失败时不应修改阈值: %d %d", saved.FailureThreshold, saved.RecoveryThreshold)
+	}
+}
@@ -0,0 +1,137 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/thingsboard-rxprobe/internal/model"
+)
+
+const (
+	rateLimitCheckInterval = time.Second // 限流排队检查间隔
+	defaultRateLimitQueue  = 10          // 默认的排队合并阈值
+)
+
+// RateLimitConfig 通知渠道限流配置
+// 渠道每分钟最大消息数优先取渠道配置的 rate_limit_per_minute，其次为渠道类型的默认限制（如企业微信 20 条），最后为 PerMinute
+type RateLimitConfig struct {
+	PerMinute int // 默认的每分钟最大消息数，0 表示不限流
+	QueueSize int // 渠道排队的通知超过该数量时合并为一条汇总通知
+}
+
+// tokenBucket 令牌桶，容量为每分钟最大消息数，按速率匀速补充
+type tokenBucket struct {
+	perMinute int
+	tokens    float64
+	updated   time.Time
+}
+
+// take 尝试取出一个令牌
+func (b *tokenBucket) take(now time.Time) bool {
+	capacity := float64(b.perMinute)
+	b.tokens += now.Sub(b.updated).Minutes() * capacity
+	if b.tokens > capacity {
+		b.tokens = capacity
+	}
+	b.updated = now
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// channelLimiter 按渠道限流
+type channelLimiter struct {
+	mu      sync.Mutex
+	buckets map[uint64]*tokenBucket
+}
+
+// newChannelLimiter 创建渠道限流器
+func newChannelLimiter() *channelLimiter {
+	return &channelLimiter{buckets: make(map[uint64]*tokenBucket)}
+}
+
+// allow 检查渠道当前是否可以发送一条消息，perMinute 不大于 0 时不限流
+func (l *channelLimiter) allow(channelID uint64, perMinute int, now time.Time) bool {
+	if perMinute <= 0 {
+		return true
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	bucket, ok := l.buckets[channelID]
+	if !ok {
+		bucket = &tokenBucket{perMinute: perMinute, tokens: float64(perMinute), updated: now}
+		l.buckets[channelID] = bucket
+	}
+	// 渠道限制调整后按新的速率补充，已有令牌不超过新容量
+	if bucket.perMinute != perMinute {
+		bucket.perMinute = perMinute
+		if bucket.tokens > float64(perMinute) {
+			bucket.tokens = float64(perMinute)
+		}
+	}
+	return bucket.take(now)
+}
+
+// summarizeAlerts 将渠道因限流排队的通知合并为一条汇总通知，只有一条通知时原样返回
+func summarizeAlerts(alerts []*model.Alert) *model.Alert {
+	if len(alerts) == 1 {
+		return alerts[0]
+	}
+
+	// 分组通知展开为其中的各条告警
+	var items []*model.Alert
+	for _, alert := range alerts {
+		if len(alert.Alerts) > 0 {
+			items = append(items, alert.Alerts...)
+		} else {
+			items = append(items, alert)
+		}
+	}
+
+	first := items[0]
+	summary := &model.Alert{
+		TargetName: fmt.Sprintf("限流期间的 %d 条通知", len(items)),
+		TargetType: first.TargetType,
+		Status:     model.AlertStatusResolved,
+		Severity:   first.Severity,
+		FiredAt:    first.FiredAt,
+		Alerts:     items,
+		Summary:    true,
+	}
+
+	var firing, resolved int
+	lines := make([]string, 0, len(items)+1)
+	for _, alert := range items {
+		label := "恢复"
+		if alert.Status == model.AlertStatusFiring {
+			label = "告警"
+			firing++
+			summary.Status = model.AlertStatusFiring
+		} else {
+			resolved++
+		}
+		lines = append(lines, fmt.Sprintf("- [%s] %s：%s", label, alert.TargetName, alert.Message))
+
+		if alert.TargetType != summary.TargetType {
+			summary.TargetType = "多种类型"
+		}
+		if alert.Severity.Rank() > summary.Severity.Rank() {
+			summary.Severity = alert.Severity
+		}
+		if alert.FiredAt.Before(summary.FiredAt) {
+			summary.FiredAt = alert.FiredAt
+		}
+		if alert.ResolvedAt != nil && (summary.ResolvedAt == nil || alert.ResolvedAt.After(*summary.ResolvedAt)) {
+			summary.ResolvedAt = alert.ResolvedAt
+		}
+	}
+	header := fmt.Sprintf("渠道发送频率受限，合并发送 %d 条告警、%d 条恢复：", firing, resolved)
+	summary.Message = header + "\n" + strings.Join(lines, "\n")
+	return summary
+}
@@ -0,0 +1,127 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/thingsboard-rxprobe/internal/model"
+)
+
+func TestChannelLimiterRefill(t *testing.T) {
+	l := newChannelLimiter()
+	now := time.Now()
+
+	for i := 0; i < 2; i++ {
+		if !l.allow(1, 2, now) {
+			t.Fatalf("容量内的第 %d 条消息应放行", i+1)
+		}
+	}
+	if l.allow(1, 2, now) {
+		t.Fatal("超出容量的消息应被限流")
+	}
+	if !l.allow(2, 2, now) {
+		t.Fatal("各渠道独立限流")
+	}
+	if !l.allow(1, 0, now) {
+		t.Fatal("不限流时应放行")
+	}
+
+	// 每分钟 2 条，30 秒补充 1 个令牌
+	if l.allow(1, 2, now.Add(20*time.Second)) {
+		t.Fatal("令牌补充不足 1 个时应被限流")
+	}
+	if !l.allow(1, 2, now.Add(30*time.Second)) {
+		t.Fatal("令牌补充后应放行")
+	}
+	if l.allow(1, 2, now.Add(30*time.Second)) {
+		t.Fatal("补充的令牌已用完")
+	}
+
+	// 长时间空闲后令牌不超过容量
+	later := now.Add(time.Hour)
+	for i := 0; i < 2; i++ {
+		if !l.allow(1, 2, later) {
+			t.Fatalf("空闲后第 %d 条消息应放行", i+1)
+		}
+	}
+	if l.allow(1, 2, later) {
+		t.Fatal("空闲后的令牌不应超过容量")
+	}
+
+	// 渠道限制调小时已有令牌不超过新容量
+	if !l.allow(3, 10, now) || !l.allow(3, 1, now) || l.allow(3, 1, now) {
+		t.Fatal("渠道限制调小后应按新容量限流")
+	}
+}
+
+func TestDeliverRateLimitQueuesAndCollapses(t *testing.T) {
+	s := newTestNotificationService(t, RetryPolicy{MaxAttempts: 3}, RateLimitConfig{QueueSize: 2})
+	ctx := context.Background()
+	fake := &fakeNotifier{}
+	channel := newFakeChannel(t, s, fake, `{"rate_limit_per_minute": 2}`)
+
+	var records []*model.AlertRecord
+	for i := 0; i < 5; i++ {
+		record := newAlertRecord(t, s, uint64(i+1))
+		records = append(records, record)
+		sent, queued, err := s.Deliver(ctx, []*model.NotifyChannel{channel}, testAlert(record.ID, fmt.Sprintf("node-%d", i+1), model.AlertStatusFiring))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if wantSent := i < 2; (sent == 1) != wantSent || (queued == 1) == wantSent {
+			t.Fatalf("第 %d 条通知: sent=%d queued=%d", i+1, sent, queued)
+		}
+	}
+	if len(fake.sentAlerts()) != 2 {
+		t.Fatalf("容量内的通知应立即发送: %d", len(fake.sentAlerts()))
+	}
+	for _, record := range records[2:] {
+		if !s.Queued(ctx, record.ID) {
+			t.Fatalf("超出容量的通知应排队: record=%d", record.ID)
+		}
+	}
+
+	// 排队数超过阈值时合并为一条汇总通知；令牌尚未补充时不发送
+	s.drainQueues(ctx)
+	if len(fake.sentAlerts()) != 2 {
+		t.Fatal("令牌未补充时不应发送排队的通知")
+	}
+	queued, err := s.deliveryRepo.ListQueued(ctx, channel.ID)
+	if err != nil || len(queued) != 3 {
+		t.Fatalf("排队的投递数 = %d (%v)", len(queued), err)
+	}
+	for _, d := range queued {
+		if d.BatchID != queued[0].ID {
+			t.Fatalf("排队的通知应合并为同一批次: %+v", d)
+		}
+	}
+
+	// 模拟令牌补充后只发送一条汇总通知
+	s.limiter.mu.Lock()
+	s.limiter.buckets[channel.ID].updated = time.Now().Add(-time.Minute)
+	s.limiter.mu.Unlock()
+	s.drainQueues(ctx)
+
+	sent := fake.sentAlerts()
+	if len(sent) != 3 {
+		t.Fatalf("令牌补充后应只发送一条汇总通知: %d", len(sent))
+	}
+	summary := sent[2]
+	if !summary.Summary || len(summary.Alerts) != 3 || summary.Status != model.AlertStatusFiring {
+		t.Fatalf("汇总通知内容不正确: %+v", summary)
+	}
+	if !strings.Contains(summary.Message, "合并发送 3 条告警、0 条恢复") {
+		t.Fatalf("汇总通知消息不正确: %s", summary.Message)
+	}
+	for _, record := range records[2:] {
+		if s.Queued(ctx, record.ID) {
+			t.Fatalf("汇总通知发送后不应再有排队的通知: record=%d", record.ID)
+		}
+		if updated, _ := s.alertRepo.GetRecordByID(ctx, record.ID); !updated.Notified {
+			t.Fatalf("汇总通知发送后告警记录应标记为已通知: record=%d", record.ID)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/thingsboard-rxprobe/internal/model"
+	"github.com/thingsboard-rxprobe/internal/repository"
+	"github.com/thingsboard-rxprobe/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// RoutingService 告警路由服务
+type RoutingService struct {
+	repo    *repository.RoutingRepository
+	enabled *listCache[*model.RoutingRule] // 已启用的路由规则（按匹配顺序）
+}
+
+// NewRoutingService 创建告警路由服务
+func NewRoutingService(repo *repository.RoutingRepository) *RoutingService {
+	return &RoutingService{
+		repo:    repo,
+		enabled: newListCache(ruleCacheTTL, repo.ListEnabled),
+	}
+}
+
+// Route 按路由规则获取告警的通知渠道 ID 列表（去重），未命中任何规则时返回空
+func (s *RoutingService) Route(ctx context.Context, target *model.ProbeTarget, severity model.Severity) []uint64 {
+	rules, err := s.enabled.get(ctx)
+	if err != nil {
+		logger.Error("查询告警路由规则失败", zap.Error(err))
+		return nil
+	}
+
+	seen := make(map[uint64]bool)
+	var ids []uint64
+	for _, rule := range rules {
+		if !rule.Matches(target, severity) {
+			continue
+		}
+		for _, id := range rule.GetChannelIDs() {
+			if !seen[id] {
+				seen[id] = true
+				ids = append(ids, id)
+			}
+		}
+		if !rule.Continue {
+			break
+		}
+	}
+	return ids
+}
+
+// List 获取路由规则列表
+func (s *RoutingService) List(ctx context.Context) ([]*model.RoutingRule, error) {
+	return s.repo.List(ctx)
+}
+
+// Get 获取路由规则详情
+func (s *RoutingService) Get(ctx context.Context, id uint64) (*model.RoutingRule, error) {
+	return s.repo.GetByID(ctx, id)
+}
+
+// Create 创建路由规则
+func (s *RoutingService) Create(ctx context.Context, rule *model.RoutingRule) error {
+	if err := rule.Validate(); err != nil {
+		return err
+	}
+	if err := s.repo.Create(ctx, rule); err != nil {
+		return fmt.Errorf("创建路由规则失败: %w", err)
+	}
+	s.enabled.invalidate()
+	logger.Info("路由规则已创建", zap.Uint64("id", rule.ID), zap.String("name", rule.Name))
+	return nil
+}
+
+// Update 更新路由规则
+func (s *RoutingService) Update(ctx context.Context, rule *model.RoutingRule) error {
+	if err := rule.Validate(); err != nil {
+		return err
+	}
+	if err := s.repo.Update(ctx, rule); err != nil {
+		return fmt.Errorf("更新路由规则失败: %w", err)
+	}
+	s.enabled.invalidate()
+	logger.Info("路由规则已更新", zap.Uint64("id", rule.ID), zap.String("name", rule.Name))
+	return nil
+}
+
+// Delete 删除路由规则
+func (s *RoutingService) Delete(ctx context.Context, id uint64) error {
+	if _, err := s.repo.GetByID(ctx, id); err != nil {
+		return err
+	}
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return fmt.Errorf("删除路由规则失败: %w", err)
+	}
+	s.enabled.invalidate()
+	logger.Info("路由规则已删除", zap.Uint64("id", id))
+	return nil
+}
@@ -0,0 +1,108 @@
+package service
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/thingsboard-rxprobe/internal/model"
+	"github.com/thingsboard-rxprobe/internal/repository"
+	"gorm.io/datatypes"
+)
+
+func TestRouteCachesRules(t *testing.T) {
+	db := newTestDB(t)
+	repo := repository.NewRoutingRepository(db)
+	s := NewRoutingService(repo)
+	ctx := context.Background()
+	target := &model.ProbeTarget{ID: 1, Group: "tb", Type: "http"}
+
+	if ids := s.Route(ctx, target, model.SeverityCritical); len(ids) != 0 {
+		t.Fatalf("没有路由规则时应返回空: %v", ids)
+	}
+
+	rule := &model.RoutingRule{
+		Name:          "tb",
+		TargetMatcher: model.TargetMatcher{Group: "tb"},
+		ChannelIDs:    datatypes.JSON(`[1, 2]`),
+		Enabled:       true,
+	}
+	if err := s.Create(ctx, rule); err != nil {
+		t.Fatal(err)
+	}
+	if ids := s.Route(ctx, target, model.SeverityCritical); len(ids) != 2 {
+		t.Fatalf("创建规则后应立即生效: %v", ids)
+	}
+
+	// 绕过服务直接修改数据库（如其他实例的修改）时在缓存有效期内仍使用缓存的规则
+	rule.ChannelIDs = datatypes.JSON(`[3]`)
+	if err := repo.Update(ctx, rule); err != nil {
+		t.Fatal(err)
+	}
+	if ids := s.Route(ctx, target, model.SeverityCritical); len(ids) != 2 {
+		t.Fatalf("缓存有效期内不应重新查询: %v", ids)
+	}
+
+	rule.ChannelIDs = datatypes.JSON(`[4]`)
+	if err := s.Update(ctx, rule); err != nil {
+		t.Fatal(err)
+	}
+	if ids := s.Route(ctx, target, model.SeverityCritical); len(ids) != 1 || ids[0] != 4 {
+		t.Fatalf("更新规则后应立即生效: %v", ids)
+	}
+
+	if err := s.Delete(ctx, rule.ID); err != nil {
+		t.Fatal(err)
+	}
+	if ids := s.Route(ctx, target, model.SeverityCritical); len(ids) != 0 {
+		t.Fatalf("删除规则后应立即生效: %v", ids)
+	}
+}
+
+func TestRouteOrder(t *testing.T) {
+	db := newTestDB(t)
+	s := NewRoutingService(repository.NewRoutingRepository(db))
+	ctx := context.Background()
+	target := &model.ProbeTarget{ID: 1, Name: "tb-node", Group: "tb", Type: "http"}
+
+	// 按优先级而不是创建顺序匹配
+	rules := []*model.RoutingRule{
+		{Name: "兜底", Priority: 30, TargetMatcher: model.TargetMatcher{Group: "tb"}, ChannelIDs: datatypes.JSON(`[9]`)},
+		{Name: "严重告警", Priority: 10, Severities: datatypes.JSON(`["critical"]`), ChannelIDs: datatypes.JSON(`[1, 2]`), Continue: true},
+		{Name: "HTTP", Priority: 20, TargetMatcher: model.TargetMatcher{TargetType: "http"}, ChannelIDs: datatypes.JSON(`[2, 3]`)},
+		{Name: "其他分组", Priority: 5, TargetMatcher: model.TargetMatcher{Group: "other"}, ChannelIDs: datatypes.JSON(`[8]`)},
+	}
+	for _, rule := range rules {
+		rule.Enabled = true
+		if err := s.Create(ctx, rule); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name     string
+		severity model.Severity
+		want     []uint64
+	}{
+		// 命中设置了 Continue 的规则后继续匹配，合并渠道并去重，命中未设置 Continue 的规则后停止
+		{"继续匹配并合并渠道", model.SeverityCritical, []uint64{1, 2, 3}},
+		// 未命中的规则不影响后续匹配，命中第一条未设置 Continue 的规则后停止
+		{"命中第一条规则后停止", model.SeverityWarning, []uint64{2, 3}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := s.Route(ctx, target, tt.severity); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("Route() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	// 停用规则后不再参与匹配
+	rules[2].Enabled = false
+	if err := s.Update(ctx, rules[2]); err != nil {
+		t.Fatal(err)
+	}
+	if got := s.Route(ctx, target, model.SeverityWarning); !reflect.DeepEqual(got, []uint64{9}) {
+		t.Fatalf("停用规则后 Route() = %v, want [9]", got)
+	}
+}
@@ -83,6 +83,13 @@ func autoMigrate() error {
 		&model.ProbeResult{},
 		&model.AlertRecord{},
 		&model.NotifyChannel{},
+		&model.NotificationDelivery{},
+		&model.NotificationAttempt{},
+		&model.Silence{},
+		&model.MaintenanceWindow{},
+		&model.AlertActivity{},
+		&model.EscalationPolicy{},
+		&model.RoutingRule{},
 	)
 }
 
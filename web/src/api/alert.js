@@ -10,12 +10,56 @@ export function getAlert(id) {
   return request.get(`/alerts/${id}`)
 }
 
-// 静默告警
-export function silenceAlert(targetId, durationMinutes) {
-  return request.post(`/targets/${targetId}/silence`, { duration_minutes: durationMinutes })
+// 确认告警
+export function ackAlert(id, comment = '') {
+  return request.post(`/alerts/${id}/ack`, { comment })
 }
 
-// 取消静默
-export function unsilenceAlert(targetId) {
-  return request.delete(`/targets/${targetId}/silence`)
+// 指派告警处理人
+export function assignAlert(id, assignee) {
+  return request.post(`/alerts/${id}/assign`, { assignee })
+}
+
+// 获取告警活动时间线
+export function getAlertActivities(id) {
+  return request.get(`/alerts/${id}/activities`)
+}
+
+// 添加告警评论
+export function addAlertComment(id, content) {
+  return request.post(`/alerts/${id}/comments`, { content })
+}
+
+// 静默告警目标
+export function silenceAlert(targetId, durationMinutes, comment = '') {
+  return request.post('/alerts/silences', {
+    target_id: targetId,
+    duration_minutes: durationMinutes,
+    comment,
+  })
+}
+
+// 获取静默规则列表
+export function getSilences(params) {
+  return request.get('/alerts/silences', { params })
+}
+
+// 创建静默规则
+export function createSilence(data) {
+  return request.post('/alerts/silences', data)
+}
+
+// 提前结束静默
+export function expireSilence(id) {
+  return request.post(`/alerts/silences/${id}/expire`)
+}
+
+// 获取告警的通知投递记录
+export function getAlertDeliveries(id) {
+  return request.get(`/alerts/${id}/deliveries`)
+}
+
+// 重发失败的通知投递
+export function resendAlertDelivery(id, deliveryId) {
+  return request.post(`/alerts/${id}/deliveries/${deliveryId}/resend`)
 }
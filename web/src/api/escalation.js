@@ -0,0 +1,36 @@
+import request from './request'
+
+/**
+ * 获取升级策略列表
+ */
+export function getEscalationPolicies() {
+  return request.get('/escalation-policies')
+}
+
+/**
+ * 获取升级策略详情
+ */
+export function getEscalationPolicy(id) {
+  return request.get(`/escalation-policies/${id}`)
+}
+
+/**
+ * 创建升级策略
+ */
+export function createEscalationPolicy(data) {
+  return request.post('/escalation-policies', data)
+}
+
+/**
+ * 更新升级策略
+ */
+export function updateEscalationPolicy(id, data) {
+  return request.put(`/escalation-policies/${id}`, data)
+}
+
+/**
+ * 删除升级策略
+ */
+export function deleteEscalationPolicy(id) {
+  return request.delete(`/escalation-policies/${id}`)
+}
@@ -0,0 +1,36 @@
+import request from './request'
+
+/**
+ * 获取维护窗口列表
+ */
+export function getMaintenanceWindows() {
+  return request.get('/maintenance-windows')
+}
+
+/**
+ * 获取维护窗口详情
+ */
+export function getMaintenanceWindow(id) {
+  return request.get(`/maintenance-windows/${id}`)
+}
+
+/**
+ * 创建维护窗口
+ */
+export function createMaintenanceWindow(data) {
+  return request.post('/maintenance-windows', data)
+}
+
+/**
+ * 更新维护窗口
+ */
+export function updateMaintenanceWindow(id, data) {
+  return request.put(`/maintenance-windows/${id}`, data)
+}
+
+/**
+ * 删除维护窗口
+ */
+export function deleteMaintenanceWindow(id) {
+  return request.delete(`/maintenance-windows/${id}`)
+}
@@ -54,3 +54,10 @@ export function testNotifier(data) {
 
 
 
+
+/**
+ * 预览消息模板
+ */
+export function previewNotifierTemplate(data) {
+  return request.post('/notifiers/preview', data)
+}
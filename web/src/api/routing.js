@@ -0,0 +1,36 @@
+import request from './request'
+
+/**
+ * 获取路由规则列表
+ */
+export function getRoutingRules() {
+  return request.get('/routing-rules')
+}
+
+/**
+ * 获取路由规则详情
+ */
+export function getRoutingRule(id) {
+  return request.get(`/routing-rules/${id}`)
+}
+
+/**
+ * 创建路由规则
+ */
+export function createRoutingRule(data) {
+  return request.post('/routing-rules', data)
+}
+
+/**
+ * 更新路由规则
+ */
+export function updateRoutingRule(id, data) {
+  return request.put(`/routing-rules/${id}`, data)
+}
+
+/**
+ * 删除路由规则
+ */
+export function deleteRoutingRule(id) {
+  return request.delete(`/routing-rules/${id}`)
+}
@@ -54,6 +54,10 @@ export const serviceTypeLabels = {
   tcp: 'TCP',
   ping: 'Ping',
   cpu: 'CPU监控',
+  mqtt: 'MQTT',
+  coap: 'CoAP',
+  lwm2m: 'LwM2M',
+  zookeeper: 'ZooKeeper',
   mysql: 'MySQL',
   mongodb: 'MongoDB',
   elasticsearch: 'Elasticsearch'